@@ -1,7 +1,117 @@
 package main
 
-import "github.com/111zxc/pr-review-service/internal/app"
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/repository/postgres"
+	"github.com/111zxc/pr-review-service/internal/repository/sqlite"
+	"github.com/111zxc/pr-review-service/internal/service"
+)
+
+// version is set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+const usage = `Usage: app <command> [flags]
+
+Commands:
+  serve [--auto-migrate]   start the HTTP server (default)
+  migrate up|down|status   apply, roll back one or list migrations
+  config print             print the effective config with secrets masked
+  sync [--dry-run] [--close-open-prs] <roster>
+                           make teams match a YAML or JSON roster file
+  version                  print the build and schema versions
+
+Every command except version accepts --config <file> (default $CONFIG_FILE).
+`
 
 func main() {
-	app.Run()
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		fs := flag.NewFlagSet("serve", flag.ExitOnError)
+		configFile := fs.String("config", "", "config file")
+		autoMigrate := fs.Bool("auto-migrate", false, "apply pending migrations before serving")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		app.Run(app.Options{ConfigFile: *configFile, AutoMigrate: *autoMigrate})
+		return nil
+	case "migrate":
+		fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+		configFile := fs.String("config", "", "config file")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("migrate expects exactly one of up, down or status\n\n%s", usage)
+		}
+		return app.Migrate(context.Background(), *configFile, fs.Arg(0), os.Stdout)
+	case "sync":
+		fs := flag.NewFlagSet("sync", flag.ExitOnError)
+		configFile := fs.String("config", "", "config file")
+		dryRun := fs.Bool("dry-run", false, "print the plan without applying it")
+		closeOpenPRs := fs.Bool("close-open-prs", false, "close open pull requests of teams missing from the roster")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return fmt.Errorf("sync expects a roster file\n\n%s", usage)
+		}
+		opts := service.SyncOptions{DryRun: *dryRun}
+		if *closeOpenPRs {
+			opts.OpenPRs = domain.OpenPRsClose
+		}
+		return app.Sync(context.Background(), *configFile, fs.Arg(0), opts, os.Stdout)
+	case "config":
+		fs := flag.NewFlagSet("config", flag.ExitOnError)
+		configFile := fs.String("config", "", "config file")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 || fs.Arg(0) != "print" {
+			return fmt.Errorf("config expects print\n\n%s", usage)
+		}
+		return printConfig(*configFile)
+	case "version":
+		fmt.Printf("pr-review-service %s (schema version %d, sqlite schema version %d)\n",
+			version, postgres.SchemaVersion, sqlite.SchemaVersion)
+		return nil
+	case "help":
+		fmt.Print(usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+}
+
+func printConfig(configFile string) error {
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	out, err := cfg.Redacted().YAML()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(out)
+	return err
 }
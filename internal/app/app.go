@@ -1,41 +1,86 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
 	"github.com/111zxc/pr-review-service/internal/config"
 	"github.com/111zxc/pr-review-service/internal/handler"
 	"github.com/111zxc/pr-review-service/internal/logger"
-	"github.com/111zxc/pr-review-service/internal/repository/postgres"
+	"github.com/111zxc/pr-review-service/internal/repository/decorator"
 	"github.com/111zxc/pr-review-service/internal/service"
+	"github.com/111zxc/pr-review-service/internal/tracing"
 )
 
-func Run() {
-	cfg := config.Load()
+type Options struct {
+	// ConfigFile is a YAML or JSON config file; empty means $CONFIG_FILE.
+	ConfigFile string
+	// AutoMigrate applies pending migrations before serving traffic.
+	AutoMigrate bool
+}
+
+func Run(opts Options) {
+	cfg, err := config.Load(opts.ConfigFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
 
-	if err := logger.Init(logger.Config{
-		Level:  cfg.Logger.Level,
-		Format: cfg.Logger.Format,
-	}); err != nil {
+	if err := initLogger(cfg); err != nil {
 		panic(err)
 	}
 
-	db, err := postgres.NewDB(cfg)
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		FilePath:    cfg.Tracing.FilePath,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		logger.Error("failed to connect to DB", logger.WithError(err))
+		logger.Error("failed to init tracing", logger.WithError(err))
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("failed to flush traces", logger.WithError(err))
+		}
+	}()
+
+	storage, err := OpenStorage(context.Background(), cfg)
+	if err != nil {
+		logger.Error("failed to open storage", "backend", cfg.Storage.Backend, logger.WithError(err))
+		os.Exit(1)
+	}
+	defer storage.Close()
+
+	switch {
+	case storage.Backend == config.BackendMemory:
+		logger.Warn("using in-memory storage, data will be lost on exit")
+	case opts.AutoMigrate:
+		if err := migrateUp(context.Background(), storage); err != nil {
+			logger.Error("failed to migrate DB", logger.WithError(err))
+			os.Exit(1)
+		}
 	}
 
-	tx := postgres.NewTxManager(db)
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	repos := storage.Repositories.Decorate(decorator.Instrument())
+	if cfg.Cache.Enabled {
+		repos = WithCache(background, repos, cfg.Cache, storage)
+	}
 
-	userRepo := postgres.NewUserRepository(db)
-	teamRepo := postgres.NewTeamRepository(db, tx)
-	prRepo := postgres.NewPullRequestRepository(db, tx)
-	prStatusRepo := postgres.NewPRStatusRepository(db)
-	statsRepo := postgres.NewStatsRepository(db)
-	eventRepo := postgres.NewEventsRepository(db)
+	prService := service.NewPullRequestService(repos.PR, repos.User, repos.Team, repos.PRStatus, repos.Events)
+	userService := service.NewUserService(repos.User, repos.Tx, prService)
+	teamService := service.NewTeamService(repos.Team, repos.User, repos.Tx, prService)
+	prService.SetReviewPolicy(ReviewPolicy(cfg.Review))
+	statsService := service.NewStatsService(repos.Stats)
 
-	teamService := service.NewTeamService(teamRepo, userRepo)
-	userService := service.NewUserService(userRepo)
-	prService := service.NewPullRequestService(prRepo, userRepo, teamRepo, prStatusRepo, eventRepo)
-	statsService := service.NewStatsService(statsRepo)
+	RegisterMetrics(background, storage.Pool(), statsService)
 
 	h := handler.New(
 		teamService,
@@ -43,9 +88,40 @@ func Run() {
 		prService,
 		statsService,
 	)
+	storage.RegisterReadinessChecks(h.Health)
+	h.Admin = handler.NewAdminHandler(cfg.Admin.Token, teamService)
+	h.SCIM = handler.NewSCIMHandler(cfg.SCIM.Token, userService, teamService)
+
+	var authMiddleware func(http.Handler) http.Handler
+	if cfg.Auth.Enabled {
+		authMiddleware, err = NewAuthMiddleware(cfg.Auth, repos.User)
+		if err != nil {
+			logger.Error("failed to configure auth", logger.WithError(err))
+			os.Exit(1)
+		}
+	}
 
-	router := NewRouter(h)
+	router := NewRouter(h, NewMiddlewares(cfg, authMiddleware)...)
 	srv := NewServer(cfg, router)
 
+	if router := storage.Router(); router != nil && router.Replica() != nil {
+		logger.Info("routing read-only queries to the replica")
+		RegisterReplicaMetrics(router)
+		go router.MonitorReplica(background, cfg.DB.ReplicaCheckInterval)
+	}
+	go watchReload(background, opts.ConfigFile, cfg, prService)
+	go watchDebugToggle(background)
+
 	srv.Start()
 }
+
+func initLogger(cfg *config.Config) error {
+	return logger.Init(logger.Config{
+		Level:          cfg.Logger.Level,
+		Format:         cfg.Logger.Format,
+		Components:     cfg.Logger.Components,
+		File:           cfg.Logger.File,
+		FileMaxSizeMB:  cfg.Logger.FileMaxSizeMB,
+		FileMaxBackups: cfg.Logger.FileMaxBackups,
+	})
+}
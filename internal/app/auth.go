@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/111zxc/pr-review-service/internal/auth"
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/repository"
+)
+
+// publicPaths skip bearer auth. The /admin and /scim endpoints check their
+// own tokens, so they stay behind the limits.
+var publicPaths = []string{"/health", "/metrics", "/admin/", "/scim/"}
+
+// unlimitedPaths skip the rate and in-flight limits.
+var unlimitedPaths = []string{"/health", "/metrics"}
+
+func NewAuthMiddleware(cfg config.AuthConfig, userRepo repository.UserRepository) (func(http.Handler) http.Handler, error) {
+	var keys *auth.KeySet
+	switch {
+	case cfg.JWKSURL != "":
+		keys = auth.NewURLKeySet(cfg.JWKSURL, cfg.JWKSRefreshInterval)
+	case cfg.JWKSFile != "":
+		keys = auth.NewFileKeySet(cfg.JWKSFile, cfg.JWKSRefreshInterval)
+	default:
+		return nil, errors.New("auth is enabled but neither AUTH_JWKS_URL nor AUTH_JWKS_FILE is set")
+	}
+
+	if err := keys.Refresh(context.Background()); err != nil {
+		logger.Warn("initial JWKS load failed, will retry on first request", "error", err)
+	}
+
+	verifier := auth.NewVerifier(keys, auth.VerifierConfig{
+		Issuer:     cfg.Issuer,
+		Audience:   cfg.Audience,
+		UserClaim:  cfg.UserClaim,
+		TeamsClaim: cfg.TeamsClaim,
+	})
+
+	return handler.NewAuthMiddleware(auth.NewAuthenticator(verifier, userRepo), publicPaths...), nil
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/repository/cache"
+	"github.com/111zxc/pr-review-service/internal/repository/postgres"
+)
+
+// cacheInvalidationChannel is the LISTEN/NOTIFY channel instances use to
+// drop each other's stale cache entries.
+const cacheInvalidationChannel = "pr_review_cache_invalidation"
+
+// WithCache wraps the user and team repositories in caching decorators. On
+// Postgres, invalidations are exchanged with other instances until ctx is
+// done; the other backends only ever run as a single instance.
+func WithCache(ctx context.Context, repos Repositories, cfg config.CacheConfig, storage *Storage) Repositories {
+	var publisher cache.Publisher
+	var notifier *postgres.Notifier
+	if router := storage.Router(); router != nil {
+		notifier = postgres.NewNotifier(router.Primary(), cacheInvalidationChannel)
+		publisher = notifier
+	}
+
+	c := cache.New(cache.Config{UserTTL: cfg.UserTTL, TeamTTL: cfg.TeamTTL}, publisher, rand.Text())
+	if notifier != nil {
+		go notifier.Listen(ctx, c.HandleRemote, c.Purge)
+	}
+
+	repos.User = cache.NewUserRepository(repos.User, c)
+	repos.Team = cache.NewTeamRepository(repos.Team, c)
+	return repos
+}
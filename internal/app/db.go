@@ -0,0 +1,44 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/repository/postgres"
+)
+
+const (
+	initialConnectBackoff = 500 * time.Millisecond
+	maxConnectBackoff     = 5 * time.Second
+)
+
+// ConnectDB retries postgres.NewDB with exponential backoff until it
+// succeeds or cfg.DB.ConnectTimeout elapses, so the service can start
+// alongside a database that is still booting.
+func ConnectDB(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.DB.ConnectTimeout)
+	defer cancel()
+
+	backoff := initialConnectBackoff
+	for attempt := 1; ; attempt++ {
+		pool, err := postgres.NewDB(ctx, cfg)
+		if err == nil {
+			return pool, nil
+		}
+
+		logger.Warn("database is not reachable yet", "attempt", attempt, "retry_in", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("gave up connecting to database after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/repository/postgres"
+	"github.com/111zxc/pr-review-service/internal/repository/sqlite"
+)
+
+// RegisterReadinessChecks wires the dependencies /health/ready reports on.
+func RegisterReadinessChecks(h *handler.HealthHandler, pool *pgxpool.Pool) {
+	h.AddCheck("database", func(ctx context.Context) (map[string]any, error) {
+		return nil, pool.Ping(ctx)
+	})
+
+	h.AddCheck("migrations", func(ctx context.Context) (map[string]any, error) {
+		applied, err := postgres.AppliedSchemaVersion(ctx, pool)
+		details := map[string]any{"applied": applied, "expected": postgres.SchemaVersion}
+		if err != nil {
+			return details, err
+		}
+		if applied != postgres.SchemaVersion {
+			return details, fmt.Errorf("schema version %d doesn't match expected %d", applied, postgres.SchemaVersion)
+		}
+		return details, nil
+	})
+
+	h.AddCheck("pool", func(context.Context) (map[string]any, error) {
+		stat := pool.Stat()
+		return map[string]any{
+			"acquired":   stat.AcquiredConns(),
+			"idle":       stat.IdleConns(),
+			"total":      stat.TotalConns(),
+			"max":        stat.MaxConns(),
+			"saturation": float64(stat.AcquiredConns()) / float64(stat.MaxConns()),
+		}, nil
+	})
+}
+
+// RegisterReplicaCheck reports the read replica's state. An unhealthy replica
+// doesn't fail readiness: reads fall back to the primary meanwhile.
+func RegisterReplicaCheck(h *handler.HealthHandler, router *postgres.Router) {
+	h.AddCheck("replica", func(context.Context) (map[string]any, error) {
+		stat := router.Replica().Stat()
+		return map[string]any{
+			"healthy":  router.ReplicaHealthy(),
+			"acquired": stat.AcquiredConns(),
+			"total":    stat.TotalConns(),
+		}, nil
+	})
+}
+
+// RegisterSQLiteReadinessChecks is RegisterReadinessChecks for the sqlite
+// backend, which has no pool to report on.
+func RegisterSQLiteReadinessChecks(h *handler.HealthHandler, db *sql.DB) {
+	h.AddCheck("database", func(ctx context.Context) (map[string]any, error) {
+		return nil, db.PingContext(ctx)
+	})
+
+	h.AddCheck("migrations", func(ctx context.Context) (map[string]any, error) {
+		applied, err := sqlite.AppliedSchemaVersion(ctx, db)
+		details := map[string]any{"applied": applied, "expected": sqlite.SchemaVersion}
+		if err != nil {
+			return details, err
+		}
+		if applied != sqlite.SchemaVersion {
+			return details, fmt.Errorf("schema version %d doesn't match expected %d", applied, sqlite.SchemaVersion)
+		}
+		return details, nil
+	})
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler"
+)
+
+// NewMiddlewares chains the limits around auth. The in-flight cap comes first
+// so that authentication, which reads the users table, queues with the rest;
+// the rate limiter comes after it so that buckets follow verified principals.
+// authMiddleware may be nil.
+func NewMiddlewares(cfg *config.Config, authMiddleware func(http.Handler) http.Handler) []func(http.Handler) http.Handler {
+	maxInFlight := cfg.RateLimit.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = cfg.DB.MaxConns
+	}
+
+	middlewares := []func(http.Handler) http.Handler{
+		handler.NewConcurrencyLimitMiddleware(maxInFlight, cfg.RateLimit.QueueTimeout, unlimitedPaths...),
+	}
+	if authMiddleware != nil {
+		middlewares = append(middlewares, authMiddleware)
+	}
+	if cfg.RateLimit.Enabled {
+		middlewares = append(middlewares, handler.NewRateLimitMiddleware(handler.RateLimitOptions{
+			RequestsPerSecond: cfg.RateLimit.RequestsPerSecond,
+			Burst:             cfg.RateLimit.Burst,
+			TrustForwardedFor: cfg.RateLimit.TrustForwardedFor,
+			TrustedProxies:    cfg.RateLimit.TrustedProxies,
+			ExemptPaths:       unlimitedPaths,
+		}))
+	}
+	return middlewares
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/metrics"
+	"github.com/111zxc/pr-review-service/internal/repository/postgres"
+	"github.com/111zxc/pr-review-service/internal/service"
+)
+
+type poolMetric struct {
+	name    string
+	help    string
+	counter bool
+	value   func(*pgxpool.Stat) float64
+}
+
+var poolMetrics = []poolMetric{
+	{"db_pool_acquired_conns", "Connections currently in use.", false,
+		func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }},
+	{"db_pool_idle_conns", "Idle connections in the pool.", false,
+		func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }},
+	{"db_pool_total_conns", "Total connections in the pool.", false,
+		func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }},
+	{"db_pool_constructing_conns", "Connections being established.", false,
+		func(s *pgxpool.Stat) float64 { return float64(s.ConstructingConns()) }},
+	{"db_pool_max_conns", "Maximum size of the pool.", false,
+		func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }},
+	{"db_pool_acquires_total", "Successful connection acquires.", true,
+		func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }},
+	{"db_pool_empty_acquires_total", "Acquires that had to wait for a connection.", true,
+		func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }},
+	{"db_pool_canceled_acquires_total", "Acquires canceled by their context.", true,
+		func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) }},
+	{"db_pool_acquire_duration_seconds_total", "Total time spent acquiring connections.", true,
+		func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }},
+}
+
+const (
+	// openReviewsRefreshInterval is how often the open_reviews gauge is
+	// recomputed; scrapes only read the last result, so they never reach
+	// the database.
+	openReviewsRefreshInterval = 15 * time.Second
+	openReviewsQueryTimeout    = 5 * time.Second
+)
+
+// RegisterMetrics exposes pool and review metrics. Pool metrics are skipped
+// when pool is nil, as with the in-memory backend. The open reviews are
+// refreshed in the background until ctx is done.
+func RegisterMetrics(ctx context.Context, pool *pgxpool.Pool, statsService *service.StatsService) {
+	if pool != nil {
+		registerPoolMetrics(pool)
+	}
+
+	openReviews := &openReviewsGauge{}
+	go openReviews.refreshEvery(ctx, statsService, openReviewsRefreshInterval)
+
+	metrics.RegisterGaugeFunc("open_reviews", "Open review assignments per pull request team.", []string{"team"},
+		openReviews.collect)
+}
+
+// openReviewsGauge holds the last open review counts per team.
+type openReviewsGauge struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (g *openReviewsGauge) refreshEvery(ctx context.Context, statsService *service.StatsService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		g.refresh(ctx, statsService)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *openReviewsGauge) refresh(ctx context.Context, statsService *service.StatsService) {
+	ctx, cancel := context.WithTimeout(ctx, openReviewsQueryTimeout)
+	defer cancel()
+
+	counts, err := statsService.GetOpenReviewsByTeam(ctx)
+	if err != nil {
+		// The gauge keeps the previous counts.
+		logger.Error("failed to collect open reviews metric", "error", err)
+		return
+	}
+
+	g.mu.Lock()
+	g.counts = counts
+	g.mu.Unlock()
+}
+
+func (g *openReviewsGauge) collect(emit func(float64, ...string)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	teams := make([]string, 0, len(g.counts))
+	for team := range g.counts {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+
+	for _, team := range teams {
+		emit(float64(g.counts[team]), team)
+	}
+}
+
+func registerPoolMetrics(pool *pgxpool.Pool) {
+	for _, m := range poolMetrics {
+		collect := func(emit func(float64, ...string)) {
+			emit(m.value(pool.Stat()))
+		}
+		if m.counter {
+			metrics.RegisterCounterFunc(m.name, m.help, nil, collect)
+		} else {
+			metrics.RegisterGaugeFunc(m.name, m.help, nil, collect)
+		}
+	}
+}
+
+// RegisterReplicaMetrics exposes whether reads currently go to the replica.
+func RegisterReplicaMetrics(router *postgres.Router) {
+	metrics.RegisterGaugeFunc("db_replica_healthy", "1 when read-only queries are routed to the replica.", nil,
+		func(emit func(float64, ...string)) {
+			healthy := 0.0
+			if router.ReplicaHealthy() {
+				healthy = 1
+			}
+			emit(healthy)
+		})
+}
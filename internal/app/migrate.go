@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/logger"
+)
+
+// Migrate runs a migrate subcommand (up, down or status) against the
+// configured storage backend, writing the status table to out.
+func Migrate(ctx context.Context, configFile, command string, out io.Writer) error {
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if err := initLogger(cfg); err != nil {
+		return err
+	}
+	storage, err := OpenStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	switch command {
+	case "up":
+		return migrateUp(ctx, storage)
+	case "down":
+		return migrateDown(ctx, storage)
+	case "status":
+		return migrateStatus(ctx, storage, out)
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+}
+
+func migrateUp(ctx context.Context, storage *Storage) error {
+	migrator, latest, err := storage.migrator()
+	if err != nil {
+		return err
+	}
+	defer migrator.Close() //nolint:errcheck
+
+	results, err := migrator.Up(ctx)
+	for _, r := range results {
+		logger.Info("Applied migration", "version", r.Source.Version, "file", r.Source.Path, "duration", r.Duration)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+	if len(results) == 0 {
+		logger.Info("Schema is up to date", "version", latest)
+	}
+	return nil
+}
+
+func migrateDown(ctx context.Context, storage *Storage) error {
+	migrator, _, err := storage.migrator()
+	if err != nil {
+		return err
+	}
+	defer migrator.Close() //nolint:errcheck
+
+	result, err := migrator.Down(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to roll back migration: %w", err)
+	}
+	logger.Info("Rolled back migration", "version", result.Source.Version, "file", result.Source.Path)
+	return nil
+}
+
+func migrateStatus(ctx context.Context, storage *Storage, out io.Writer) error {
+	migrator, _, err := storage.migrator()
+	if err != nil {
+		return err
+	}
+	defer migrator.Close() //nolint:errcheck
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read migration status: %w", err)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+	for _, s := range statuses {
+		appliedAt := "-"
+		if !s.AppliedAt.IsZero() {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, appliedAt, s.Source.Path)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/service"
+)
+
+func ReviewPolicy(cfg config.ReviewConfig) domain.ReviewPolicy {
+	policy := domain.ReviewPolicy{
+		ReviewersPerPR:    cfg.ReviewersPerPR,
+		Fallback:          domain.FallbackPolicy(cfg.Fallback),
+		RequireMaintainer: cfg.RequireMaintainer,
+	}
+	if len(cfg.Teams) > 0 {
+		policy.TeamReviewers = make(map[string]int, len(cfg.Teams))
+		policy.TeamFallback = make(map[string]domain.FallbackPolicy, len(cfg.Teams))
+		policy.TeamRequireMaintainer = make(map[string]bool, len(cfg.Teams))
+		for team, p := range cfg.Teams {
+			if p.ReviewersPerPR > 0 {
+				policy.TeamReviewers[team] = p.ReviewersPerPR
+			}
+			if p.Fallback != "" {
+				policy.TeamFallback[team] = domain.FallbackPolicy(p.Fallback)
+			}
+			if p.RequireMaintainer != nil {
+				policy.TeamRequireMaintainer[team] = *p.RequireMaintainer
+			}
+		}
+	}
+	return policy
+}
+
+// watchReload re-reads the configuration on SIGHUP and applies the settings
+// that are safe to change at runtime. Everything else keeps its startup
+// value until the next restart; an invalid file is rejected as a whole.
+func watchReload(ctx context.Context, configFile string, current *config.Config, prService *service.PullRequestService) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+
+		next, err := config.Load(configFile)
+		if err != nil {
+			logger.Error("Config reload rejected", logger.WithError(err))
+			continue
+		}
+
+		if sections := config.RestartRequired(current, next); len(sections) > 0 {
+			logger.Warn("Config changes require a restart and were not applied", "sections", sections)
+		}
+
+		if err := logger.SetLevel(next.Logger.Level); err != nil {
+			logger.Error("Failed to apply log level", logger.WithError(err))
+		}
+		if err := logger.SetComponentLevels(next.Logger.Components); err != nil {
+			logger.Error("Failed to apply component log levels", logger.WithError(err))
+		}
+		prService.SetReviewPolicy(ReviewPolicy(next.Review))
+
+		current.Logger.Level = next.Logger.Level
+		current.Logger.Components = next.Logger.Components
+		current.Review = next.Review
+		logger.Info("Config reloaded", "log_level", next.Logger.Level,
+			"reviewers_per_pr", next.Review.ReviewersPerPR, "review_fallback", next.Review.Fallback,
+			"require_maintainer", next.Review.RequireMaintainer)
+	}
+}
+
+// watchDebugToggle flips the default log level between debug and its
+// previous value on every SIGUSR1.
+func watchDebugToggle(ctx context.Context) {
+	usr1 := make(chan os.Signal, 1)
+	signal.Notify(usr1, syscall.SIGUSR1)
+	defer signal.Stop(usr1)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-usr1:
+			logger.Warn("Log level toggled", "level", logger.ToggleDebug())
+		}
+	}
+}
@@ -4,16 +4,27 @@ import (
 	"net/http"
 
 	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/metrics"
 )
 
-func NewRouter(h *handler.Handler) http.Handler {
+func NewRouter(h *handler.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	mux := http.NewServeMux()
 
+	mux.HandleFunc("/team", h.Team.DeleteTeam)
 	mux.HandleFunc("/team/add", h.Team.CreateTeam)
 	mux.HandleFunc("/team/get", h.Team.GetTeam)
+	mux.HandleFunc("/team/addMembers", h.Team.AddMembers)
+	mux.HandleFunc("/team/removeMember", h.Team.RemoveMember)
+	mux.HandleFunc("/team/transferMember", h.Team.TransferMember)
+	mux.HandleFunc("/team/restore", h.Team.RestoreTeam)
+	mux.HandleFunc("/team/rename", h.Team.RenameTeam)
+	mux.HandleFunc("/team/setParent", h.Team.SetParent)
+	mux.HandleFunc("/team/setRole", h.Team.SetMemberRole)
 
 	mux.HandleFunc("/users/setIsActive", h.User.SetUserActive)
 	mux.HandleFunc("/users/getReview", h.User.GetUserReviews)
+	mux.HandleFunc("/users/get", h.User.GetUser)
+	mux.HandleFunc("/users/list", h.User.ListUsers)
 
 	mux.HandleFunc("/pullRequest/create", h.PR.CreatePullRequest)
 	mux.HandleFunc("/pullRequest/merge", h.PR.MergePullRequest)
@@ -22,6 +33,34 @@ func NewRouter(h *handler.Handler) http.Handler {
 	mux.HandleFunc("/stats", h.Stats.GetStats)
 
 	mux.HandleFunc("/health", h.Health.Health)
+	mux.HandleFunc("/health/live", h.Health.Live)
+	mux.HandleFunc("/health/ready", h.Health.Ready)
 
-	return mux
+	mux.Handle("/metrics", metrics.Handler())
+
+	mux.HandleFunc("/admin/log-level", h.Admin.LogLevel)
+	mux.HandleFunc("/admin/sync", h.Admin.Sync)
+	mux.HandleFunc("/admin/users/erase", h.Admin.EraseUser)
+	mux.HandleFunc("/admin/team/setRole", h.Admin.SetMemberRole)
+
+	mux.HandleFunc("/scim/v2/ServiceProviderConfig", h.SCIM.ServiceProviderConfig)
+	mux.HandleFunc("/scim/v2/Users", h.SCIM.Users)
+	mux.HandleFunc("/scim/v2/Users/", h.SCIM.User)
+	mux.HandleFunc("/scim/v2/Groups", h.SCIM.Groups)
+	mux.HandleFunc("/scim/v2/Groups/", h.SCIM.Group)
+
+	var router http.Handler = mux
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		router = middlewares[i](router)
+	}
+
+	routeOf := func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		return pattern
+	}
+
+	router = handler.NewMetricsMiddleware(routeOf)(router)
+	router = handler.NewRequestLogMiddleware(routeOf)(router)
+
+	return handler.NewTracingMiddleware(routeOf)(router)
 }
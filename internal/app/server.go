@@ -7,7 +7,6 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/111zxc/pr-review-service/internal/config"
 	"github.com/111zxc/pr-review-service/internal/logger"
@@ -22,9 +21,9 @@ func NewServer(cfg *config.Config, handler http.Handler) *Server {
 	srv := &http.Server{
 		Addr:         ":" + fmt.Sprint(cfg.Server.Port),
 		Handler:      handler,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	return &Server{cfg: cfg, http: srv}
@@ -48,7 +47,7 @@ func (s *Server) Start() {
 func (s *Server) Shutdown() {
 	logger.Info("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := s.http.Shutdown(ctx); err != nil {
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pressly/goose/v3"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/repository"
+	"github.com/111zxc/pr-review-service/internal/repository/decorator"
+	"github.com/111zxc/pr-review-service/internal/repository/memory"
+	"github.com/111zxc/pr-review-service/internal/repository/postgres"
+	"github.com/111zxc/pr-review-service/internal/repository/sqlite"
+)
+
+// Repositories is the set of repositories the services are built on.
+type Repositories struct {
+	User     repository.UserRepository
+	Team     repository.TeamRepository
+	PR       repository.PullRequestRepository
+	PRStatus repository.PRStatusRepository
+	Events   repository.EventsRepository
+	Stats    repository.StatsRepository
+	Tx       repository.Transactor
+}
+
+// Decorate wraps every repository so that mw sees each call.
+func (r Repositories) Decorate(mw decorator.Middleware) Repositories {
+	return Repositories{
+		User:     decorator.NewUserRepository(r.User, mw),
+		Team:     decorator.NewTeamRepository(r.Team, mw),
+		PR:       decorator.NewPullRequestRepository(r.PR, mw),
+		PRStatus: decorator.NewPRStatusRepository(r.PRStatus, mw),
+		Events:   decorator.NewEventsRepository(r.Events, mw),
+		Stats:    decorator.NewStatsRepository(r.Stats, mw),
+		Tx:       r.Tx,
+	}
+}
+
+func NewPostgresRepositories(db *postgres.Router) Repositories {
+	tx := postgres.NewTxManager(db.Primary())
+
+	return Repositories{
+		User:     postgres.NewUserRepository(db),
+		Team:     postgres.NewTeamRepository(db, tx),
+		PR:       postgres.NewPullRequestRepository(db, tx),
+		PRStatus: postgres.NewPRStatusRepository(db),
+		Events:   postgres.NewEventsRepository(db),
+		Stats:    postgres.NewStatsRepository(db),
+		Tx:       tx,
+	}
+}
+
+func NewSQLiteRepositories(db *sql.DB) Repositories {
+	tx := sqlite.NewTxManager(db)
+
+	return Repositories{
+		User:     sqlite.NewUserRepository(db),
+		Team:     sqlite.NewTeamRepository(db, tx),
+		PR:       sqlite.NewPullRequestRepository(db, tx),
+		PRStatus: sqlite.NewPRStatusRepository(db),
+		Events:   sqlite.NewEventsRepository(db),
+		Stats:    sqlite.NewStatsRepository(db),
+		Tx:       tx,
+	}
+}
+
+// NewMemoryRepositories returns repositories backed by a fresh in-memory
+// store. Nothing is persisted across restarts.
+func NewMemoryRepositories() Repositories {
+	store := memory.NewStore()
+
+	return Repositories{
+		User:     memory.NewUserRepository(store),
+		Team:     memory.NewTeamRepository(store),
+		PR:       memory.NewPullRequestRepository(store),
+		PRStatus: memory.NewPRStatusRepository(store),
+		Events:   memory.NewEventsRepository(store),
+		Stats:    memory.NewStatsRepository(store),
+		Tx:       memory.NewTxManager(store),
+	}
+}
+
+// Storage is the opened backend selected by storage.backend.
+type Storage struct {
+	Repositories
+	Backend string
+
+	router *postgres.Router
+	db     *sql.DB
+}
+
+func OpenStorage(ctx context.Context, cfg *config.Config) (*Storage, error) {
+	switch cfg.Storage.Backend {
+	case config.BackendMemory:
+		return &Storage{Repositories: NewMemoryRepositories(), Backend: config.BackendMemory}, nil
+
+	case config.BackendSQLite:
+		db, err := sqlite.Open(ctx, cfg.Storage.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		return &Storage{Repositories: NewSQLiteRepositories(db), Backend: config.BackendSQLite, db: db}, nil
+
+	default:
+		pool, err := ConnectDB(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		var replica *pgxpool.Pool
+		if cfg.DB.ReplicaDSN != "" {
+			if replica, err = postgres.NewReplicaDB(cfg); err != nil {
+				pool.Close()
+				return nil, err
+			}
+		}
+
+		router := postgres.NewRouter(pool, replica)
+		return &Storage{Repositories: NewPostgresRepositories(router), Backend: config.BackendPostgres, router: router}, nil
+	}
+}
+
+// Pool returns the primary Postgres pool, or nil for other backends.
+func (s *Storage) Pool() *pgxpool.Pool {
+	if s.router == nil {
+		return nil
+	}
+	return s.router.Primary()
+}
+
+// Router returns the Postgres query router, or nil for other backends.
+func (s *Storage) Router() *postgres.Router {
+	return s.router
+}
+
+func (s *Storage) Close() {
+	if s.router != nil {
+		s.router.Primary().Close()
+		if replica := s.router.Replica(); replica != nil {
+			replica.Close()
+		}
+	}
+	if s.db != nil {
+		s.db.Close() //nolint:errcheck
+	}
+}
+
+// RegisterReadinessChecks adds the checks for the backend in use; the
+// memory backend has nothing to check.
+func (s *Storage) RegisterReadinessChecks(h *handler.HealthHandler) {
+	switch {
+	case s.router != nil:
+		RegisterReadinessChecks(h, s.router.Primary())
+		if s.router.Replica() != nil {
+			RegisterReplicaCheck(h, s.router)
+		}
+	case s.db != nil:
+		RegisterSQLiteReadinessChecks(h, s.db)
+	}
+}
+
+type schemaMigrator interface {
+	Up(ctx context.Context) ([]*goose.MigrationResult, error)
+	Down(ctx context.Context) (*goose.MigrationResult, error)
+	Status(ctx context.Context) ([]*goose.MigrationStatus, error)
+	Close() error
+}
+
+func (s *Storage) migrator() (schemaMigrator, int64, error) {
+	switch {
+	case s.router != nil:
+		m, err := postgres.NewMigrator(s.router.Primary())
+		return m, postgres.SchemaVersion, err
+	case s.db != nil:
+		m, err := sqlite.NewMigrator(s.db)
+		return m, sqlite.SchemaVersion, err
+	default:
+		return nil, 0, fmt.Errorf("the %s backend has no schema to migrate", s.Backend)
+	}
+}
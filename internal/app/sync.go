@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/roster"
+	"github.com/111zxc/pr-review-service/internal/service"
+)
+
+// Sync applies the roster file to the configured storage, or only plans it
+// with opts.DryRun, and writes the plan to out.
+func Sync(ctx context.Context, configFile, rosterFile string, opts service.SyncOptions, out io.Writer) error {
+	teams, err := roster.Load(rosterFile)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if err := initLogger(cfg); err != nil {
+		return err
+	}
+	storage, err := OpenStorage(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	repos := storage.Repositories
+	prService := service.NewPullRequestService(repos.PR, repos.User, repos.Team, repos.PRStatus, repos.Events)
+	prService.SetReviewPolicy(ReviewPolicy(cfg.Review))
+	teamService := service.NewTeamService(repos.Team, repos.User, repos.Tx, prService)
+
+	plan, err := teamService.SyncRoster(ctx, teams, opts)
+	if err != nil {
+		return err
+	}
+	return writePlan(out, plan, opts.DryRun)
+}
+
+func writePlan(out io.Writer, plan *domain.SyncPlan, dryRun bool) error {
+	if len(plan.Changes) == 0 {
+		_, err := fmt.Fprintln(out, "Nothing to change.")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tTEAM\tUSER\tDETAILS")
+	for _, c := range plan.Changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.Action, dash(c.Team), dash(c.UserID), changeDetails(c))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if dryRun {
+		_, err := fmt.Fprintf(out, "\nDry run: %d changes not applied.\n", len(plan.Changes))
+		return err
+	}
+	if len(plan.ClosedPRs) > 0 {
+		fmt.Fprintf(out, "\nClosed pull requests: %s\n", strings.Join(plan.ClosedPRs, ", "))
+	}
+	for _, r := range plan.Reviews {
+		fmt.Fprintf(out, "Review of %s: %s -> %s\n", r.PRID, r.OldUserID, dash(r.NewUserID))
+	}
+	_, err := fmt.Fprintf(out, "\nApplied %d changes.\n", len(plan.Changes))
+	return err
+}
+
+func changeDetails(c domain.SyncChange) string {
+	var details []string
+	switch c.Action {
+	case domain.SyncCreateTeam, domain.SyncSetParent:
+		details = append(details, "parent="+dash(c.Parent))
+	case domain.SyncCreateUser, domain.SyncUpdateUser, domain.SyncDeactivateUser:
+		details = append(details, "username="+c.Username, fmt.Sprintf("is_active=%t", c.IsActive))
+	case domain.SyncTransferMember:
+		details = append(details, "from="+c.FromTeam)
+	}
+	if c.Role != "" {
+		details = append(details, "role="+string(c.Role))
+	}
+	return dash(strings.Join(details, " "))
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/logger"
+)
+
+var ErrKeyNotFound = errors.New("signing key not found")
+
+const defaultMinRefreshInterval = 10 * time.Second
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// ParseJWKS decodes a JSON Web Key Set into public keys indexed by kid.
+// Keys that are not usable for signature verification are skipped.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jwkSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			logger.Warn("skipping JWKS key", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// KeySet caches keys loaded from a JWKS file or URL. Keys are refreshed
+// once the refresh interval elapses, and on demand when a token references
+// an unknown kid, which is how key rotation at the IdP is picked up.
+type KeySet struct {
+	source             string
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	client             *http.Client
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+}
+
+func NewFileKeySet(path string, refreshInterval time.Duration) *KeySet {
+	return &KeySet{
+		source:             path,
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: defaultMinRefreshInterval,
+	}
+}
+
+func NewURLKeySet(url string, refreshInterval time.Duration) *KeySet {
+	return &KeySet{
+		source:             url,
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: defaultMinRefreshInterval,
+		client:             &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SetMinRefreshInterval limits how often an unknown kid may trigger a reload.
+func (ks *KeySet) SetMinRefreshInterval(d time.Duration) {
+	ks.mu.Lock()
+	ks.minRefreshInterval = d
+	ks.mu.Unlock()
+}
+
+func (ks *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	key, ok := ks.keys[kid]
+	stale := time.Since(ks.lastRefresh) > ks.refreshInterval
+	canRefresh := time.Since(ks.lastRefresh) > ks.minRefreshInterval
+	ks.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if stale || canRefresh {
+		if err := ks.Refresh(ctx); err != nil {
+			logger.Warn("failed to refresh JWKS", "source", ks.source, "error", err)
+		}
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if key, ok := ks.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (ks *KeySet) Refresh(ctx context.Context) error {
+	data, err := ks.load(ctx)
+	if err == nil {
+		var keys map[string]crypto.PublicKey
+		if keys, err = ParseJWKS(data); err == nil {
+			ks.mu.Lock()
+			ks.keys = keys
+			ks.lastRefresh = time.Now()
+			ks.mu.Unlock()
+			return nil
+		}
+	}
+
+	// keep serving the previous keys, but do not hammer a failing source
+	ks.mu.Lock()
+	ks.lastRefresh = time.Now()
+	ks.mu.Unlock()
+
+	return err
+}
+
+func (ks *KeySet) load(ctx context.Context) ([]byte, error) {
+	if ks.client == nil {
+		return os.ReadFile(ks.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected JWKS response status: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// EncodeJWKS is the inverse of ParseJWKS. It is used to publish locally
+// generated keys, e.g. for a development IdP stub.
+func EncodeJWKS(keys map[string]crypto.PublicKey) ([]byte, error) {
+	set := jwkSet{Keys: make([]jwk, 0, len(keys))}
+
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, jwk{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			size := (k.Curve.Params().BitSize + 7) / 8
+			set.Keys = append(set.Keys, jwk{
+				Kty: "EC",
+				Kid: kid,
+				Use: "sig",
+				Alg: "ES256",
+				Crv: k.Curve.Params().Name,
+				X:   base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size))),
+				Y:   base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size))),
+			})
+		default:
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+	}
+
+	return json.Marshal(set)
+}
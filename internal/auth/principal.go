@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/repository"
+)
+
+var ErrInactiveUser = errors.New("user is inactive")
+
+type Principal struct {
+	UserID   string
+	Username string
+	Teams    []string
+	// Roles holds the principal's role in each of Teams.
+	Roles map[string]domain.TeamRole
+}
+
+// HasRole reports whether the principal holds one of roles in team.
+func (p *Principal) HasRole(team string, roles ...domain.TeamRole) bool {
+	role, ok := p.Roles[team]
+	return ok && slices.Contains(roles, role)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// Authenticator verifies bearer tokens and maps their claims onto the users
+// and team memberships stored by the service. Team claims can only narrow
+// the principal's teams, never grant membership the database doesn't have.
+type Authenticator struct {
+	verifier *Verifier
+	userRepo repository.UserRepository
+}
+
+func NewAuthenticator(verifier *Verifier, userRepo repository.UserRepository) *Authenticator {
+	return &Authenticator{verifier: verifier, userRepo: userRepo}
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.userRepo.GetByID(ctx, claims.Subject)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: unknown subject %s", ErrInvalidToken, claims.Subject)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, ErrInactiveUser
+	}
+
+	principal := &Principal{
+		UserID:   user.ID,
+		Username: user.Username,
+	}
+
+	for _, team := range user.Teams {
+		if claims.Teams == nil || slices.Contains(claims.Teams, team) {
+			if principal.Roles == nil {
+				principal.Roles = make(map[string]domain.TeamRole)
+			}
+			principal.Teams = append(principal.Teams, team)
+			principal.Roles[team] = user.RoleFor(team)
+		}
+	}
+
+	return principal, nil
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type VerifierConfig struct {
+	Issuer     string
+	Audience   string
+	UserClaim  string
+	TeamsClaim string
+}
+
+type Claims struct {
+	Subject  string
+	Username string
+	Teams    []string
+}
+
+type Verifier struct {
+	keys   *KeySet
+	cfg    VerifierConfig
+	parser *jwt.Parser
+}
+
+func NewVerifier(keys *KeySet, cfg VerifierConfig) *Verifier {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &Verifier{
+		keys:   keys,
+		cfg:    cfg,
+		parser: jwt.NewParser(opts...),
+	}
+}
+
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Claims, error) {
+	mapClaims := jwt.MapClaims{}
+
+	_, err := v.parser.ParseWithClaims(raw, mapClaims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	subject, _ := mapClaims[v.cfg.UserClaim].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, v.cfg.UserClaim)
+	}
+
+	claims := &Claims{Subject: subject}
+	claims.Username, _ = mapClaims["preferred_username"].(string)
+
+	if v.cfg.TeamsClaim != "" {
+		claims.Teams = stringList(mapClaims[v.cfg.TeamsClaim])
+	}
+
+	return claims, nil
+}
+
+func stringList(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
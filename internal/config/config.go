@@ -1,59 +1,496 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
 	"os"
-	"strconv"
+	"reflect"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
+const redacted = "******"
+
 type Config struct {
-	DB     DBConfig
-	Logger LoggerConfig
-	Server ServerConfig
-	Env    string
+	Storage   StorageConfig   `yaml:"storage"`
+	DB        DBConfig        `yaml:"db"`
+	Logger    LoggerConfig    `yaml:"logger"`
+	Server    ServerConfig    `yaml:"server"`
+	Auth      AuthConfig      `yaml:"auth"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Review    ReviewConfig    `yaml:"review"`
+	Admin     AdminConfig     `yaml:"admin"`
+	SCIM      SCIMConfig      `yaml:"scim"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Env       string          `yaml:"env"`
+}
+
+// EnvMemory is shorthand for the in-memory storage backend when
+// storage.backend is not set explicitly.
+const EnvMemory = "memory"
+
+const (
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+	BackendMemory   = "memory"
+)
+
+// StorageConfig selects where repositories keep their data. The db section
+// only applies to the postgres backend.
+type StorageConfig struct {
+	Backend    string `yaml:"backend"`
+	SQLitePath string `yaml:"sqlite_path"`
 }
 
 type DBConfig struct {
-	Host     string
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
+	Host     string `yaml:"host"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+
+	Port     int `yaml:"port"`
+	MaxConns int `yaml:"max_conns"`
+	MinConns int `yaml:"min_conns"`
 
-	Port int
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+
+	// ReplicaDSN is an optional read replica that serves read-only queries.
+	ReplicaDSN           string        `yaml:"replica_dsn"`
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval"`
 }
 
 type ServerConfig struct {
-	Port int
+	Port int `yaml:"port"`
+
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type AuthConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	JWKSFile   string `yaml:"jwks_file"`
+	JWKSURL    string `yaml:"jwks_url"`
+	Issuer     string `yaml:"issuer"`
+	Audience   string `yaml:"audience"`
+	UserClaim  string `yaml:"user_claim"`
+	TeamsClaim string `yaml:"teams_claim"`
+
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval"`
+}
+
+type RateLimitConfig struct {
+	Enabled           bool    `yaml:"enabled"`
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+	TrustForwardedFor bool    `yaml:"trust_forwarded_for"`
+	// TrustedProxies is how many proxies in front of the service append to
+	// X-Forwarded-For; the client is taken that many entries from the right.
+	TrustedProxies int `yaml:"trusted_proxies"`
+
+	// MaxInFlight caps concurrent requests; 0 derives it from DB.MaxConns.
+	MaxInFlight  int           `yaml:"max_in_flight"`
+	QueueTimeout time.Duration `yaml:"queue_timeout"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	FilePath    string  `yaml:"file"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
 }
 
 type LoggerConfig struct {
-	Level  string
-	Format string
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+	// Components overrides Level for a component and everything under it,
+	// e.g. "repository/postgres": debug.
+	Components map[string]string `yaml:"components"`
+
+	File           string `yaml:"file"`
+	FileMaxSizeMB  int    `yaml:"file_max_size_mb"`
+	FileMaxBackups int    `yaml:"file_max_backups"`
+}
+
+type AdminConfig struct {
+	// Token guards the /admin endpoints; they are disabled while it is empty.
+	Token string `yaml:"token"`
+}
+
+type SCIMConfig struct {
+	// Token is the bearer token identity providers use for /scim/v2; the
+	// endpoints are disabled while it is empty.
+	Token string `yaml:"token"`
 }
 
-func Load() *Config {
-	env := getEnv("ENV", "development")
+// CacheConfig controls the user and team lookup caches.
+type CacheConfig struct {
+	Enabled bool          `yaml:"enabled"`
+	UserTTL time.Duration `yaml:"user_ttl"`
+	TeamTTL time.Duration `yaml:"team_ttl"`
+}
 
+// ReviewConfig controls reviewer assignment. It is safe to change at runtime.
+type ReviewConfig struct {
+	ReviewersPerPR int `yaml:"reviewers_per_pr"`
+	// Fallback is where assignment turns when a team has too few free
+	// reviewers: none, parent or ancestors.
+	Fallback string `yaml:"fallback"`
+	// RequireMaintainer asks for a maintainer or lead on every pull
+	// request.
+	RequireMaintainer bool                  `yaml:"require_maintainer"`
+	Teams             map[string]TeamPolicy `yaml:"teams"`
+}
+
+// TeamPolicy overrides ReviewConfig defaults for a single team. Zero values
+// keep the default.
+type TeamPolicy struct {
+	ReviewersPerPR    int    `yaml:"reviewers_per_pr"`
+	Fallback          string `yaml:"fallback"`
+	RequireMaintainer *bool  `yaml:"require_maintainer,omitempty"`
+}
+
+// Load builds the configuration from defaults, then the YAML or JSON file at
+// path (or $CONFIG_FILE when path is empty), then environment variables,
+// and validates the result. Malformed values are reported, never ignored.
+func Load(path string) (*Config, error) {
+	cfg := defaults()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.Storage.Backend == "" {
+		cfg.Storage.Backend = BackendPostgres
+		if cfg.Env == EnvMemory {
+			cfg.Storage.Backend = BackendMemory
+		}
+	}
+	if cfg.Logger.Level == "" {
+		cfg.Logger.Level = getDefaultLogLevel(cfg.Env)
+	}
+	if cfg.Logger.Format == "" {
+		cfg.Logger.Format = getDefaultLogFormat(cfg.Env)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func defaults() *Config {
 	return &Config{
+		Storage: StorageConfig{
+			SQLitePath: "pr-review-service.db",
+		},
 		DB: DBConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvAsInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "pr_user"),
-			Password: getEnv("DB_PASSWORD", "pr_password"),
-			Name:     getEnv("DB_NAME", "pr_review_service"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:           "localhost",
+			Port:           5432,
+			User:           "pr_user",
+			Password:       "pr_password",
+			Name:           "pr_review_service",
+			SSLMode:        "disable",
+			MaxConns:       25,
+			MinConns:       5,
+			ConnectTimeout: 30 * time.Second,
+
+			ReplicaCheckInterval: 5 * time.Second,
 		},
 		Server: ServerConfig{
-			Port: getEnvAsInt("SERVER_PORT", 8080),
+			Port:            8080,
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Auth: AuthConfig{
+			UserClaim:           "sub",
+			TeamsClaim:          "groups",
+			JWKSRefreshInterval: 10 * time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerSecond: 20,
+			Burst:             40,
+			TrustedProxies:    1,
+			QueueTimeout:      200 * time.Millisecond,
 		},
 		Logger: LoggerConfig{
-			Level:  getEnv("LOG_LEVEL", getDefaultLogLevel(env)),
-			Format: getEnv("LOG_FORMAT", getDefaultLogFormat(env)),
+			FileMaxSizeMB:  100,
+			FileMaxBackups: 5,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			FilePath:    "traces.jsonl",
+			ServiceName: "pr-review-service",
+			SampleRatio: 1,
+		},
+		Review: ReviewConfig{
+			ReviewersPerPR: 2,
+			Fallback:       "none",
+		},
+		Cache: CacheConfig{
+			Enabled: true,
+			UserTTL: 30 * time.Second,
+			TeamTTL: 30 * time.Second,
 		},
-		Env: env,
+		Env: "development",
 	}
 }
 
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// JSON is a subset of YAML, so one decoder handles both formats.
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config) error {
+	var env envReader
+
+	env.string("ENV", &cfg.Env)
+
+	env.string("STORAGE_BACKEND", &cfg.Storage.Backend)
+	env.string("SQLITE_PATH", &cfg.Storage.SQLitePath)
+
+	env.string("DB_HOST", &cfg.DB.Host)
+	env.int("DB_PORT", &cfg.DB.Port)
+	env.string("DB_USER", &cfg.DB.User)
+	env.string("DB_PASSWORD", &cfg.DB.Password)
+	env.string("DB_NAME", &cfg.DB.Name)
+	env.string("DB_SSLMODE", &cfg.DB.SSLMode)
+	env.int("DB_MAX_CONNS", &cfg.DB.MaxConns)
+	env.int("DB_MIN_CONNS", &cfg.DB.MinConns)
+	env.duration("DB_CONNECT_TIMEOUT", &cfg.DB.ConnectTimeout)
+	env.string("DB_REPLICA_DSN", &cfg.DB.ReplicaDSN)
+	env.duration("DB_REPLICA_CHECK_INTERVAL", &cfg.DB.ReplicaCheckInterval)
+
+	env.int("SERVER_PORT", &cfg.Server.Port)
+	env.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	env.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	env.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	env.duration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+
+	env.string("LOG_LEVEL", &cfg.Logger.Level)
+	env.string("LOG_FORMAT", &cfg.Logger.Format)
+	env.keyValues("LOG_COMPONENT_LEVELS", &cfg.Logger.Components)
+	env.string("LOG_FILE", &cfg.Logger.File)
+	env.int("LOG_FILE_MAX_SIZE_MB", &cfg.Logger.FileMaxSizeMB)
+	env.int("LOG_FILE_MAX_BACKUPS", &cfg.Logger.FileMaxBackups)
+
+	env.string("ADMIN_TOKEN", &cfg.Admin.Token)
+	env.string("SCIM_TOKEN", &cfg.SCIM.Token)
+
+	env.bool("AUTH_ENABLED", &cfg.Auth.Enabled)
+	env.string("AUTH_JWKS_FILE", &cfg.Auth.JWKSFile)
+	env.string("AUTH_JWKS_URL", &cfg.Auth.JWKSURL)
+	env.string("AUTH_ISSUER", &cfg.Auth.Issuer)
+	env.string("AUTH_AUDIENCE", &cfg.Auth.Audience)
+	env.string("AUTH_USER_CLAIM", &cfg.Auth.UserClaim)
+	env.string("AUTH_TEAMS_CLAIM", &cfg.Auth.TeamsClaim)
+	env.duration("AUTH_JWKS_REFRESH_INTERVAL", &cfg.Auth.JWKSRefreshInterval)
+
+	env.bool("RATE_LIMIT_ENABLED", &cfg.RateLimit.Enabled)
+	env.float("RATE_LIMIT_RPS", &cfg.RateLimit.RequestsPerSecond)
+	env.int("RATE_LIMIT_BURST", &cfg.RateLimit.Burst)
+	env.bool("RATE_LIMIT_TRUST_FORWARDED_FOR", &cfg.RateLimit.TrustForwardedFor)
+	env.int("RATE_LIMIT_TRUSTED_PROXIES", &cfg.RateLimit.TrustedProxies)
+	env.int("MAX_IN_FLIGHT_REQUESTS", &cfg.RateLimit.MaxInFlight)
+	env.duration("IN_FLIGHT_QUEUE_TIMEOUT", &cfg.RateLimit.QueueTimeout)
+
+	env.string("TRACING_EXPORTER", &cfg.Tracing.Exporter)
+	env.string("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", &cfg.Tracing.Endpoint)
+	env.string("TRACING_FILE", &cfg.Tracing.FilePath)
+	env.string("OTEL_SERVICE_NAME", &cfg.Tracing.ServiceName)
+	env.float("TRACING_SAMPLE_RATIO", &cfg.Tracing.SampleRatio)
+
+	env.int("REVIEWERS_PER_PR", &cfg.Review.ReviewersPerPR)
+	env.string("REVIEW_FALLBACK", &cfg.Review.Fallback)
+	env.bool("REVIEW_REQUIRE_MAINTAINER", &cfg.Review.RequireMaintainer)
+
+	env.bool("CACHE_ENABLED", &cfg.Cache.Enabled)
+	env.duration("CACHE_USER_TTL", &cfg.Cache.UserTTL)
+	env.duration("CACHE_TEAM_TTL", &cfg.Cache.TeamTTL)
+
+	return errors.Join(env.errs...)
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(oneOf(c.Storage.Backend, BackendPostgres, BackendSQLite, BackendMemory),
+		"storage.backend must be one of postgres, sqlite, memory, got %q", c.Storage.Backend)
+	if c.Storage.Backend == BackendSQLite {
+		check(c.Storage.SQLitePath != "", "storage.sqlite_path is required for the sqlite backend")
+	}
+
+	check(c.DB.Host != "", "db.host is required")
+	check(c.DB.Name != "", "db.name is required")
+	check(validPort(c.DB.Port), "db.port must be between 1 and 65535, got %d", c.DB.Port)
+	check(c.DB.MaxConns >= 1, "db.max_conns must be at least 1, got %d", c.DB.MaxConns)
+	check(c.DB.MinConns >= 0 && c.DB.MinConns <= c.DB.MaxConns,
+		"db.min_conns must be between 0 and db.max_conns (%d), got %d", c.DB.MaxConns, c.DB.MinConns)
+	check(c.DB.ConnectTimeout > 0, "db.connect_timeout must be positive")
+	if c.DB.ReplicaDSN != "" {
+		check(c.DB.ReplicaCheckInterval > 0, "db.replica_check_interval must be positive")
+	}
+
+	check(validPort(c.Server.Port), "server.port must be between 1 and 65535, got %d", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+
+	check(oneOf(c.Logger.Level, "debug", "info", "warn", "error"),
+		"logger.level must be one of debug, info, warn, error, got %q", c.Logger.Level)
+	check(oneOf(c.Logger.Format, "text", "json"),
+		"logger.format must be text or json, got %q", c.Logger.Format)
+	for component, level := range c.Logger.Components {
+		check(oneOf(level, "debug", "info", "warn", "error"),
+			"logger.components.%s must be one of debug, info, warn, error, got %q", component, level)
+	}
+	if c.Logger.File != "" {
+		check(c.Logger.FileMaxSizeMB >= 1, "logger.file_max_size_mb must be at least 1, got %d", c.Logger.FileMaxSizeMB)
+		check(c.Logger.FileMaxBackups >= 0, "logger.file_max_backups must not be negative")
+	}
+
+	if c.Auth.Enabled {
+		check(c.Auth.JWKSURL != "" || c.Auth.JWKSFile != "",
+			"auth.jwks_url or auth.jwks_file is required when auth is enabled")
+		check(c.Auth.UserClaim != "", "auth.user_claim is required when auth is enabled")
+		check(c.Auth.JWKSRefreshInterval > 0, "auth.jwks_refresh_interval must be positive")
+	}
+
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.RequestsPerSecond > 0, "rate_limit.requests_per_second must be positive")
+		check(c.RateLimit.Burst >= 1, "rate_limit.burst must be at least 1, got %d", c.RateLimit.Burst)
+		check(!c.RateLimit.TrustForwardedFor || c.RateLimit.TrustedProxies >= 1,
+			"rate_limit.trusted_proxies must be at least 1, got %d", c.RateLimit.TrustedProxies)
+	}
+	check(c.RateLimit.MaxInFlight >= 0, "rate_limit.max_in_flight must not be negative")
+	check(c.RateLimit.QueueTimeout >= 0, "rate_limit.queue_timeout must not be negative")
+
+	check(oneOf(c.Tracing.Exporter, "none", "otlp", "stdout", "file"),
+		"tracing.exporter must be one of none, otlp, stdout, file, got %q", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing.sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	if c.Tracing.Exporter == "file" {
+		check(c.Tracing.FilePath != "", "tracing.file is required for the file exporter")
+	}
+
+	check(c.Review.ReviewersPerPR >= 1, "review.reviewers_per_pr must be at least 1, got %d", c.Review.ReviewersPerPR)
+	check(oneOf(c.Review.Fallback, "none", "parent", "ancestors"),
+		"review.fallback must be one of none, parent, ancestors, got %q", c.Review.Fallback)
+	for team, policy := range c.Review.Teams {
+		check(policy.ReviewersPerPR >= 0,
+			"review.teams.%s.reviewers_per_pr must not be negative, got %d", team, policy.ReviewersPerPR)
+		check(oneOf(policy.Fallback, "", "none", "parent", "ancestors"),
+			"review.teams.%s.fallback must be one of none, parent, ancestors, got %q", team, policy.Fallback)
+	}
+
+	if c.Cache.Enabled {
+		check(c.Cache.UserTTL > 0, "cache.user_ttl must be positive")
+		check(c.Cache.TeamTTL > 0, "cache.team_ttl must be positive")
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy of the config that is safe to print.
+func (c *Config) Redacted() *Config {
+	out := *c
+	if out.DB.Password != "" {
+		out.DB.Password = redacted
+	}
+	out.DB.ReplicaDSN = redactDSN(out.DB.ReplicaDSN)
+	if out.Admin.Token != "" {
+		out.Admin.Token = redacted
+	}
+	if out.SCIM.Token != "" {
+		out.SCIM.Token = redacted
+	}
+	return &out
+}
+
+// YAML renders the config in the same format Load reads.
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// RestartRequired lists the sections that differ between two configs other
+// than the ones that can be applied at runtime: log levels and the review
+// policies.
+func RestartRequired(current, next *Config) []string {
+	a, b := *current, *next
+	b.Logger.Level = a.Logger.Level
+	b.Logger.Components = a.Logger.Components
+	b.Review = a.Review
+
+	var sections []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := range va.NumField() {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			sections = append(sections, va.Type().Field(i).Tag.Get("yaml"))
+		}
+	}
+	return sections
+}
+
+var dsnPassword = regexp.MustCompile(`password=\S+`)
+
+// redactDSN masks the password in both URL and key=value connection strings.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "password="+redacted)
+}
+
+func validPort(port int) bool {
+	return port >= 1 && port <= 65535
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
 func getDefaultLogLevel(env string) string {
 	switch env {
 	case "production":
@@ -73,19 +510,3 @@ func getDefaultLogFormat(env string) string {
 		return "text"
 	}
 }
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-	}
-	return defaultValue
-}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envReader overrides config fields from environment variables, collecting
+// parse errors instead of falling back to defaults.
+type envReader struct {
+	errs []error
+}
+
+func (r *envReader) lookup(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	return value, ok && value != ""
+}
+
+func (r *envReader) fail(key, value, kind string) {
+	r.errs = append(r.errs, fmt.Errorf("%s=%q is not a valid %s", key, value, kind))
+}
+
+func (r *envReader) string(key string, dst *string) {
+	if value, ok := r.lookup(key); ok {
+		*dst = value
+	}
+}
+
+func (r *envReader) int(key string, dst *int) {
+	if value, ok := r.lookup(key); ok {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			r.fail(key, value, "integer")
+			return
+		}
+		*dst = v
+	}
+}
+
+func (r *envReader) float(key string, dst *float64) {
+	if value, ok := r.lookup(key); ok {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			r.fail(key, value, "number")
+			return
+		}
+		*dst = v
+	}
+}
+
+func (r *envReader) bool(key string, dst *bool) {
+	if value, ok := r.lookup(key); ok {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			r.fail(key, value, "boolean")
+			return
+		}
+		*dst = v
+	}
+}
+
+func (r *envReader) duration(key string, dst *time.Duration) {
+	if value, ok := r.lookup(key); ok {
+		v, err := time.ParseDuration(value)
+		if err != nil {
+			r.fail(key, value, "duration")
+			return
+		}
+		*dst = v
+	}
+}
+
+// keyValues parses "a=1,b=2".
+func (r *envReader) keyValues(key string, dst *map[string]string) {
+	if value, ok := r.lookup(key); ok {
+		m := make(map[string]string)
+		for _, pair := range strings.Split(value, ",") {
+			k, v, found := strings.Cut(strings.TrimSpace(pair), "=")
+			if !found || k == "" {
+				r.fail(key, value, "list of key=value pairs")
+				return
+			}
+			m[k] = v
+		}
+		*dst = m
+	}
+}
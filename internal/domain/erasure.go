@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// PseudonymPrefix starts the ID and username an erased user is left with.
+const PseudonymPrefix = "erased-"
+
+// NewPseudonym returns a random pseudonym for a user being erased.
+func NewPseudonym() string {
+	return PseudonymPrefix + rand.Text()
+}
+
+// ErasedRecords counts the records an erasure moved from the user's ID to
+// the pseudonym.
+type ErasedRecords struct {
+	// PullRequests counts the pull requests the user authored.
+	PullRequests int
+	// PastReviews counts the reviews the user kept on pull requests that
+	// were no longer open.
+	PastReviews int
+	// Events counts the events that named the user, as the event's user or
+	// in its additional data.
+	Events int
+}
+
+// ErasureReceipt records what erasing a user changed. It carries the erased
+// ID so the requester can match it to their request; the service keeps no
+// copy.
+type ErasureReceipt struct {
+	UserID    string
+	Pseudonym string
+	ErasedAt  time.Time
+	// Teams lists the teams the user was taken out of.
+	Teams []string
+	// Reviews lists the open reviews handed to someone else or unassigned.
+	Reviews []ReviewerChange
+	ErasedRecords
+}
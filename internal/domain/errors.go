@@ -2,18 +2,47 @@ package domain
 
 import "errors"
 
+// ErrDomain matches, through errors.Is, every error of this package: the
+// outcomes the service reports on purpose, as opposed to failures of the
+// storage or the network.
+var ErrDomain = errors.New("domain error")
+
 var (
-	ErrTeamExists          = errors.New("team already exists")
-	ErrTeamNotFound        = errors.New("team not found")
-	ErrUserNotFound        = errors.New("user not found")
-	ErrPullRequestNotFound = errors.New("pull request not found")
-	ErrPullRequestExists   = errors.New("pull request already exists")
-	ErrPullRequestMerged   = errors.New("pull request is merged")
-	ErrReviewerNotAssigned = errors.New("reviewer not assigned")
-	ErrNoCandidate         = errors.New("no active replacement candidate")
-	ErrInvalidInput        = errors.New("invalid input")
+	ErrTeamExists          = newError("team already exists")
+	ErrTeamNotFound        = newError("team not found")
+	ErrUserNotFound        = newError("user not found")
+	ErrUserExists          = newError("user already exists")
+	ErrPullRequestNotFound = newError("pull request not found")
+	ErrPullRequestExists   = newError("pull request already exists")
+	ErrPullRequestMerged   = newError("pull request is merged")
+	ErrPullRequestClosed   = newError("pull request is closed")
+	ErrReviewerNotAssigned = newError("reviewer not assigned")
+	ErrNoCandidate         = newError("no active replacement candidate")
+	ErrInvalidInput        = newError("invalid input")
+	ErrTeamAmbiguous       = newError("author belongs to several teams")
+	ErrNotTeamMember       = newError("user is not a member of the team")
+	ErrTeamHasOpenPRs      = newError("team has open pull requests")
+	ErrTeamCycle           = newError("team cannot be nested under itself")
+	ErrNotTeamLead         = newError("only the team lead can change roles")
 )
 
+// domainError is a sentinel that also matches ErrDomain.
+type domainError struct {
+	msg string
+}
+
+func newError(msg string) error {
+	return &domainError{msg: msg}
+}
+
+func (e *domainError) Error() string {
+	return e.msg
+}
+
+func (e *domainError) Is(target error) bool {
+	return target == ErrDomain
+}
+
 type ErrorResponse struct {
 	Error struct {
 		Code    string `json:"code"`
@@ -10,6 +10,7 @@ type EventType string
 const (
 	EventTypePRCreated          EventType = "pr_created"
 	EventTypePRMerged           EventType = "pr_merged"
+	EventTypePRClosed           EventType = "pr_closed"
 	EventTypeReviewerAssigned   EventType = "reviewer_assigned"
 	EventTypeReviewerReassigned EventType = "reviewer_reassigned"
 	EventTypeReviewerUnassigned EventType = "reviewer_unassigned"
@@ -50,3 +51,13 @@ type PRCreatedData struct {
 type PRMergedData struct {
 	MergedAt time.Time `json:"merged_at"`
 }
+
+type PRClosedData struct {
+	Reason   string    `json:"reason"`
+	ClosedAt time.Time `json:"closed_at"`
+}
+
+type ReviewerUnassignedData struct {
+	OldUserID    string    `json:"old_user_id"`
+	UnassignedAt time.Time `json:"unassigned_at"`
+}
@@ -13,4 +13,5 @@ type PRStatus struct {
 const (
 	PRStatusOpen   = "OPEN"
 	PRStatusMerged = "MERGED"
+	PRStatusClosed = "CLOSED"
 )
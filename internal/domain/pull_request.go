@@ -6,7 +6,8 @@ type PullRequest struct {
 	ID                string     `json:"pull_request_id"`
 	Name              string     `json:"pull_request_name"`
 	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"` // "OPEN", "MERGED"
+	TeamName          string     `json:"team_name,omitempty"` // team reviewers are drawn from
+	Status            string     `json:"status"`              // "OPEN", "MERGED", "CLOSED"
 	AssignedReviewers []string   `json:"assigned_reviewers"`
 	CreatedAt         *time.Time `json:"createdAt,omitempty"`
 	MergedAt          *time.Time `json:"mergedAt,omitempty"`
@@ -27,6 +28,18 @@ func (pr *PullRequest) IsMerged() bool {
 	return pr.Status == PRStatusMerged
 }
 
+func (pr *PullRequest) IsClosed() bool {
+	return pr.Status == PRStatusClosed
+}
+
 func (pr *PullRequest) CanModifyReviewers() bool {
 	return pr.IsOpen()
 }
+
+// ReviewerChange records a review handed over when its reviewer left a team.
+// NewUserID is empty when nobody could take the review over.
+type ReviewerChange struct {
+	PRID      string
+	OldUserID string
+	NewUserID string
+}
@@ -0,0 +1,59 @@
+package domain
+
+const DefaultReviewersPerPR = 2
+
+// FallbackPolicy decides where reviewers come from once a team has run out
+// of free members.
+type FallbackPolicy string
+
+const (
+	// FallbackNone keeps assignment within the team.
+	FallbackNone FallbackPolicy = "none"
+	// FallbackParent also draws from the parent team and all its sub-teams.
+	FallbackParent FallbackPolicy = "parent"
+	// FallbackAncestors keeps widening to each ancestor's sub-tree up to the
+	// top of the hierarchy.
+	FallbackAncestors FallbackPolicy = "ancestors"
+)
+
+// ReviewPolicy decides how many reviewers a pull request gets and where they
+// may come from. Teams without an override use ReviewersPerPR, Fallback and
+// RequireMaintainer.
+type ReviewPolicy struct {
+	ReviewersPerPR int
+	TeamReviewers  map[string]int
+	Fallback       FallbackPolicy
+	TeamFallback   map[string]FallbackPolicy
+	// RequireMaintainer asks for at least one maintainer or lead among the
+	// reviewers of every pull request, as far as the team can provide one.
+	RequireMaintainer     bool
+	TeamRequireMaintainer map[string]bool
+}
+
+func DefaultReviewPolicy() ReviewPolicy {
+	return ReviewPolicy{ReviewersPerPR: DefaultReviewersPerPR, Fallback: FallbackNone}
+}
+
+func (p ReviewPolicy) ReviewersFor(teamName string) int {
+	if n, ok := p.TeamReviewers[teamName]; ok {
+		return n
+	}
+	return p.ReviewersPerPR
+}
+
+func (p ReviewPolicy) FallbackFor(teamName string) FallbackPolicy {
+	if f, ok := p.TeamFallback[teamName]; ok {
+		return f
+	}
+	if p.Fallback == "" {
+		return FallbackNone
+	}
+	return p.Fallback
+}
+
+func (p ReviewPolicy) MaintainerRequired(teamName string) bool {
+	if required, ok := p.TeamRequireMaintainer[teamName]; ok {
+		return required
+	}
+	return p.RequireMaintainer
+}
@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A roster is the whole organisation as it should be: every live team, its
+// parent and its members with their roles. Syncing a roster deletes the
+// teams and memberships it does not list.
+
+// RosterError lists everything wrong with a roster.
+type RosterError struct {
+	Problems []string
+}
+
+func (e *RosterError) Error() string {
+	return "invalid roster: " + strings.Join(e.Problems, "; ")
+}
+
+func (e *RosterError) Is(target error) bool {
+	return target == ErrDomain
+}
+
+// ValidateRoster checks that the roster lists at least one team, that team
+// names are set and unique, that parents are teams of the roster and form
+// no cycle, and that a user listed in several teams has the same username
+// and active flag in each. An empty roster is rejected since syncing it
+// would delete every team; it usually is an empty or cut-off file.
+func ValidateRoster(teams []*Team) error {
+	if len(teams) == 0 {
+		return &RosterError{Problems: []string{"the roster lists no teams"}}
+	}
+
+	var problems []string
+	byName := make(map[string]*Team, len(teams))
+	for _, team := range teams {
+		switch {
+		case team.Name == "":
+			problems = append(problems, "team_name is required")
+		case byName[team.Name] != nil:
+			problems = append(problems, fmt.Sprintf("team %q is listed twice", team.Name))
+		default:
+			byName[team.Name] = team
+		}
+	}
+
+	users := make(map[string]TeamMember)
+	for _, team := range teams {
+		if team.ParentName != "" && byName[team.ParentName] == nil {
+			problems = append(problems, fmt.Sprintf("team %q: parent_team %q is not in the roster", team.Name, team.ParentName))
+		}
+
+		seen := make(map[string]bool, len(team.Members))
+		for _, member := range team.Members {
+			switch {
+			case member.UserID == "":
+				problems = append(problems, fmt.Sprintf("team %q: user_id is required", team.Name))
+				continue
+			case seen[member.UserID]:
+				problems = append(problems, fmt.Sprintf("team %q: user %q is listed twice", team.Name, member.UserID))
+				continue
+			}
+			seen[member.UserID] = true
+
+			first, ok := users[member.UserID]
+			if !ok {
+				users[member.UserID] = member
+				continue
+			}
+			if first.Username != member.Username || first.IsActive != member.IsActive {
+				problems = append(problems, fmt.Sprintf("user %q has different username or is_active in different teams", member.UserID))
+			}
+		}
+	}
+
+	for _, team := range teams {
+		parent, steps := team.ParentName, 0
+		for parent != "" && byName[parent] != nil && steps <= len(teams) {
+			if parent == team.Name {
+				problems = append(problems, fmt.Sprintf("team %q is nested under itself", team.Name))
+				break
+			}
+			parent, steps = byName[parent].ParentName, steps+1
+		}
+	}
+
+	if len(problems) > 0 {
+		return &RosterError{Problems: problems}
+	}
+	return nil
+}
+
+type SyncAction string
+
+const (
+	SyncDeleteTeam SyncAction = "delete_team"
+	SyncCreateTeam SyncAction = "create_team"
+	SyncSetParent  SyncAction = "set_parent"
+	// SyncCreateUser and SyncUpdateUser also cover reactivation; a user
+	// turned inactive is a SyncDeactivateUser.
+	SyncCreateUser     SyncAction = "create_user"
+	SyncUpdateUser     SyncAction = "update_user"
+	SyncDeactivateUser SyncAction = "deactivate_user"
+	SyncAddMember      SyncAction = "add_member"
+	SyncTransferMember SyncAction = "transfer_member"
+	SyncSetRole        SyncAction = "set_role"
+	SyncRemoveMember   SyncAction = "remove_member"
+)
+
+// SyncChange is one step of a roster sync. Only the fields its Action
+// needs are set.
+type SyncChange struct {
+	Action SyncAction
+	// Team is the team changed; for transfers it is the one joined.
+	Team     string
+	FromTeam string
+	Parent   string
+	UserID   string
+	Username string
+	IsActive bool
+	Role     TeamRole
+}
+
+// SyncPlan is what a roster sync does, in the order it is done. Applying
+// it fills in the closed pull requests and the released reviews.
+type SyncPlan struct {
+	Changes   []SyncChange
+	ClosedPRs []string
+	Reviews   []ReviewerChange
+}
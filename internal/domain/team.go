@@ -1,6 +1,145 @@
 package domain
 
+import (
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"strings"
+)
+
 type Team struct {
+	// ID is generated when the team is created and never changes, unlike
+	// Name.
+	ID      string       `json:"team_id"`
 	Name    string       `json:"team_name"`
 	Members []TeamMember `json:"members"`
+	// ParentName is empty for top-level teams and for teams whose parent
+	// is deleted.
+	ParentName string `json:"parent_team,omitempty"`
+
+	// Ancestors (nearest first) and SubTeams are filled in by
+	// TeamService.GetTeam only.
+	Ancestors []string   `json:"ancestors,omitempty"`
+	SubTeams  []TeamNode `json:"sub_teams,omitempty"`
+}
+
+func (t *Team) HasMember(userID string) bool {
+	return slices.ContainsFunc(t.Members, func(m TeamMember) bool { return m.UserID == userID })
+}
+
+// TeamNode is a team in the sub-tree shown under another team.
+type TeamNode struct {
+	Name     string     `json:"team_name"`
+	SubTeams []TeamNode `json:"sub_teams,omitempty"`
+}
+
+func NewTeamID() string {
+	return rand.Text()
+}
+
+// TeamRef points at a team by ID or, when ID is empty, by name.
+type TeamRef struct {
+	ID   string
+	Name string
+}
+
+// ConflictPolicy decides what creating a team or adding members does with
+// members that already exist.
+type ConflictPolicy string
+
+const (
+	// ConflictReject refuses members that are in another team or whose
+	// username or active flag differ from the stored user.
+	ConflictReject ConflictPolicy = "reject"
+	// ConflictUpsert overwrites existing users and adds them to the team
+	// regardless of their other teams.
+	ConflictUpsert ConflictPolicy = "upsert"
+)
+
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case "":
+		return ConflictReject, nil
+	case ConflictReject, ConflictUpsert:
+		return p, nil
+	default:
+		return "", ErrInvalidInput
+	}
+}
+
+type MemberAction string
+
+const (
+	MemberCreate    MemberAction = "create"
+	MemberUpdate    MemberAction = "update"
+	MemberUnchanged MemberAction = "unchanged"
+)
+
+// MemberDiff describes what creating a team does to one member's user.
+// The Old fields are only set for existing users.
+type MemberDiff struct {
+	UserID      string
+	Action      MemberAction
+	Username    string
+	OldUsername string
+	IsActive    bool
+	OldIsActive bool
+	// OtherTeams are the teams the user already belongs to.
+	OtherTeams []string
+}
+
+// Conflicts reports whether the change would alter a user some other team
+// already relies on.
+func (d MemberDiff) Conflicts() bool {
+	return d.Action == MemberUpdate || len(d.OtherTeams) > 0
+}
+
+// MemberConflictError is returned when a team is created, or members are
+// added to one, with ConflictReject and some members conflict.
+type MemberConflictError struct {
+	Conflicts []MemberDiff
+}
+
+func (e *MemberConflictError) Error() string {
+	ids := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		ids = append(ids, c.UserID)
+	}
+	return fmt.Sprintf("conflicting team members: %s", strings.Join(ids, ", "))
+}
+
+func (e *MemberConflictError) Is(target error) bool {
+	return target == ErrDomain
+}
+
+// OpenPRPolicy decides what deleting a team does with its open pull
+// requests.
+type OpenPRPolicy string
+
+const (
+	// OpenPRsReject refuses to delete a team that has open pull requests.
+	OpenPRsReject OpenPRPolicy = ""
+	// OpenPRsClose closes them without merging.
+	OpenPRsClose OpenPRPolicy = "close"
+	// OpenPRsReassign moves them to another team, replacing reviewers who
+	// are not members of it.
+	OpenPRsReassign OpenPRPolicy = "reassign"
+)
+
+func ParseOpenPRPolicy(s string) (OpenPRPolicy, error) {
+	switch p := OpenPRPolicy(s); p {
+	case OpenPRsReject, OpenPRsClose, OpenPRsReassign:
+		return p, nil
+	default:
+		return "", ErrInvalidInput
+	}
+}
+
+// TeamDeletion reports what happened to a deleted team's open pull
+// requests.
+type TeamDeletion struct {
+	ClosedPRs []string
+	MovedPRs  []string
+	// Reviews lists reviewers replaced or unassigned on moved pull requests.
+	Reviews []ReviewerChange
 }
@@ -1,14 +1,109 @@
 package domain
 
+import "slices"
+
 type User struct {
 	ID       string `json:"user_id"`
 	Username string `json:"username"`
 	IsActive bool   `json:"is_active"`
-	TeamName string `json:"team_name,omitempty"`
+	// Teams lists the teams the user belongs to, sorted by name.
+	Teams []string `json:"teams,omitempty"`
+	// Roles maps each of Teams to the user's role there.
+	Roles map[string]TeamRole `json:"roles,omitempty"`
+}
+
+func (u *User) InTeam(name string) bool {
+	return slices.Contains(u.Teams, name)
+}
+
+// RoleFor is the role that counts when the user reviews for a team: their
+// role in it or, when they are borrowed from other teams, the most senior
+// role they hold. Unknown roles count as RoleMember.
+func (u *User) RoleFor(team string) TeamRole {
+	if role, ok := u.Roles[team]; ok {
+		return role
+	}
+	if len(u.Roles) == 0 {
+		return RoleMember
+	}
+	best := RoleTrainee
+	for _, role := range u.Roles {
+		if role.rank() > best.rank() {
+			best = role
+		}
+	}
+	return best
+}
+
+// UserFilter selects users for listing; zero fields match every user.
+type UserFilter struct {
+	Username string
+	// UsernamePrefix matches usernames starting with it, case-sensitively.
+	UsernamePrefix string
+	// Team matches members of the live team with that name.
+	Team     string
+	IsActive *bool
+	// Offset skips that many matching users in ID order; a zero Limit
+	// returns all the rest.
+	Offset int
+	Limit  int
+}
+
+// UserDetails is a user as the user directory shows it.
+type UserDetails struct {
+	*User
+	// OpenReviews counts the open pull requests the user reviews.
+	OpenReviews int
 }
 
 type TeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	IsActive bool     `json:"is_active"`
+	Role     TeamRole `json:"role"`
+}
+
+// TeamRole is what a member may do in a team. Roles are per team, so the
+// same user can lead one team and be a trainee in another.
+type TeamRole string
+
+const (
+	// RoleLead is not picked as a regular reviewer; review is escalated to
+	// the lead when nobody else is available.
+	RoleLead TeamRole = "lead"
+	// RoleMaintainer counts towards a team's maintainer requirement.
+	RoleMaintainer TeamRole = "maintainer"
+	RoleMember     TeamRole = "member"
+	// RoleTrainee is never assigned as the only reviewer of a pull request.
+	RoleTrainee TeamRole = "trainee"
+)
+
+// ParseTeamRole accepts the role names; an empty one means RoleMember.
+func ParseTeamRole(s string) (TeamRole, error) {
+	switch r := TeamRole(s); r {
+	case "":
+		return RoleMember, nil
+	case RoleLead, RoleMaintainer, RoleMember, RoleTrainee:
+		return r, nil
+	default:
+		return "", ErrInvalidInput
+	}
+}
+
+// Maintains reports whether the role satisfies a maintainer requirement.
+func (r TeamRole) Maintains() bool {
+	return r == RoleLead || r == RoleMaintainer
+}
+
+func (r TeamRole) rank() int {
+	switch r {
+	case RoleLead:
+		return 3
+	case RoleMaintainer:
+		return 2
+	case RoleMember:
+		return 1
+	default:
+		return 0
+	}
 }
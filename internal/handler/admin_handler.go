@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/handler/dto"
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/roster"
+	"github.com/111zxc/pr-review-service/internal/service"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+type AdminHandler struct {
+	token       string
+	teamService *service.TeamService
+}
+
+// NewAdminHandler serves operational endpoints guarded by a static token.
+// With an empty token they are disabled.
+func NewAdminHandler(token string, teamService *service.TeamService) *AdminHandler {
+	return &AdminHandler{token: token, teamService: teamService}
+}
+
+type LogLevelRequest struct {
+	// Component selects a per-component override; empty means the default
+	// level. An empty Level with a Component removes the override.
+	Component string `json:"component"`
+	Level     string `json:"level"`
+}
+
+type LogLevelResponse struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components"`
+}
+
+func (h *AdminHandler) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if h.token == "" {
+		writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		return false
+	}
+
+	got := r.Header.Get(AdminTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+		writeError(w, domain.NewErrorResponse("UNAUTHORIZED", "admin token is required"))
+		return false
+	}
+	return true
+}
+
+// LogLevel reports the current levels on GET and changes one on PUT.
+func (h *AdminHandler) LogLevel(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req LogLevelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+			return
+		}
+
+		var err error
+		if req.Component == "" {
+			err = logger.SetLevel(req.Level)
+		} else {
+			err = logger.SetComponentLevel(req.Component, req.Level)
+		}
+		if err != nil {
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", err.Error()))
+			return
+		}
+
+		logger.For(ctx, "handler").Warn("Log level changed", "target_component", req.Component, "level", req.Level)
+	default:
+		writeError(w, domain.NewErrorResponse("METHOD_NOT_ALLOWED", "Only GET and PUT methods are allowed"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(LogLevelResponse{
+		Level:      logger.Level(),
+		Components: logger.ComponentLevels(),
+	})
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+	}
+}
+
+// Sync makes the teams match the YAML or JSON roster in the body. With
+// dry_run=true it only reports the plan; open_prs=close closes the open
+// pull requests of teams the roster drops.
+func (h *AdminHandler) Sync(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		writeError(w, domain.NewErrorResponse("METHOD_NOT_ALLOWED", "Only POST method is allowed"))
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun := false
+	if v := query.Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", "dry_run must be true or false"))
+			return
+		}
+	}
+	openPRs, err := domain.ParseOpenPRPolicy(query.Get("open_prs"))
+	if err != nil || openPRs == domain.OpenPRsReassign {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "open_prs must be close"))
+		return
+	}
+
+	teams, err := roster.Decode(r.Body)
+	if err != nil {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", err.Error()))
+		return
+	}
+
+	plan, err := h.teamService.SyncRoster(ctx, teams, service.SyncOptions{DryRun: dryRun, OpenPRs: openPRs})
+	if err != nil {
+		var invalid *domain.RosterError
+		switch {
+		case errors.As(err, &invalid):
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", invalid.Error()))
+		case errors.Is(err, domain.ErrTeamHasOpenPRs):
+			writeError(w, domain.NewErrorResponse("TEAM_HAS_OPEN_PRS",
+				"a team missing from the roster has open pull requests; pass open_prs=close"))
+		case errors.Is(err, domain.ErrTeamExists):
+			writeError(w, domain.NewErrorResponse("TEAM_EXISTS", "a new team has the name of a deleted team; restore it first"))
+		default:
+			logger.For(ctx, "handler").Error("Failed to sync roster", "error", err)
+			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		}
+		return
+	}
+
+	if !dryRun {
+		logger.For(ctx, "handler").Warn("Roster synced", "changes", len(plan.Changes))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(dto.SyncPlanResponse{
+		DryRun:             dryRun,
+		Changes:            syncChangeResponses(plan.Changes),
+		ClosedPullRequests: nonNil(plan.ClosedPRs),
+		Reviews:            reviewerChangeResponses(plan.Reviews),
+	})
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+	}
+}
+
+// EraseUser erases a user for a data-protection request and returns the
+// receipt. The user ID is deliberately kept out of the logs.
+func (h *AdminHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	ctx := r.Context()
+
+	if r.Method != http.MethodPost {
+		writeError(w, domain.NewErrorResponse("METHOD_NOT_ALLOWED", "Only POST method is allowed"))
+		return
+	}
+
+	var req dto.EraseUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+	if req.UserID == "" {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "user_id is required"))
+		return
+	}
+
+	receipt, err := h.teamService.EraseUser(ctx, req.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
+			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		default:
+			logger.For(ctx, "handler").Error("Failed to erase user", "error", err)
+			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		}
+		return
+	}
+
+	logger.For(ctx, "handler").Warn("User erased", "pseudonym", receipt.Pseudonym)
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(dto.ErasureReceiptResponse{
+		UserID:           receipt.UserID,
+		Pseudonym:        receipt.Pseudonym,
+		ErasedAt:         receipt.ErasedAt,
+		Teams:            nonNil(receipt.Teams),
+		Reviews:          reviewerChangeResponses(receipt.Reviews),
+		PullRequestsKept: receipt.PullRequests,
+		ReviewsKept:      receipt.PastReviews,
+		EventsScrubbed:   receipt.Events,
+	})
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+	}
+}
+
+// SetMemberRole sets a member's role without the lead check, for teams
+// that have no lead yet and no lead above them.
+func (h *AdminHandler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, domain.NewErrorResponse("METHOD_NOT_ALLOWED", "Only POST method is allowed"))
+		return
+	}
+	setMemberRole(w, r, h.teamService, service.SetMemberRoleOptions{Force: true})
+}
+
+func syncChangeResponses(changes []domain.SyncChange) []dto.SyncChangeResponse {
+	resp := make([]dto.SyncChangeResponse, 0, len(changes))
+	for _, c := range changes {
+		r := dto.SyncChangeResponse{
+			Action:     string(c.Action),
+			TeamName:   c.Team,
+			FromTeam:   c.FromTeam,
+			ParentName: c.Parent,
+			UserID:     c.UserID,
+			Username:   c.Username,
+			Role:       string(c.Role),
+		}
+		switch c.Action {
+		case domain.SyncCreateUser, domain.SyncUpdateUser, domain.SyncDeactivateUser:
+			r.IsActive = &c.IsActive
+		}
+		resp = append(resp, r)
+	}
+	return resp
+}
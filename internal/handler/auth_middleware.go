@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/111zxc/pr-review-service/internal/auth"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/logger"
+)
+
+func NewAuthMiddleware(authenticator *auth.Authenticator, publicPaths ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isExempt(r.URL.Path, publicPaths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := bearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				writeError(w, domain.NewErrorResponse("UNAUTHORIZED", "bearer token is required"))
+				return
+			}
+
+			principal, err := authenticator.Authenticate(r.Context(), token)
+			if err != nil {
+				switch {
+				case errors.Is(err, auth.ErrInvalidToken):
+					logger.For(r.Context(), "auth").Debug("Rejected bearer token", "error", err)
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+					writeError(w, domain.NewErrorResponse("UNAUTHORIZED", "invalid bearer token"))
+				case errors.Is(err, auth.ErrInactiveUser):
+					writeError(w, domain.NewErrorResponse("FORBIDDEN", "user is inactive"))
+				default:
+					logger.For(r.Context(), "auth").Error("Failed to authenticate request", "error", err)
+					writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+				}
+				return
+			}
+
+			setRequestPrincipal(r.Context(), principal.UserID)
+			ctx := logger.With(auth.WithPrincipal(r.Context(), principal), "principal", principal.UserID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -1,14 +1,21 @@
 package dto
 
 type CreateTeamRequest struct {
-	Name    string          `json:"team_name"`
-	Members []TeamMemberDTO `json:"members"`
+	Name       string          `json:"team_name"`
+	ParentName string          `json:"parent_team,omitempty"`
+	Members    []TeamMemberDTO `json:"members"`
+	DryRun     bool            `json:"dry_run,omitempty"`
+	// OnConflict is "reject" (default) or "upsert".
+	OnConflict string `json:"on_conflict,omitempty"`
 }
 
 type TeamMemberDTO struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	IsActive bool   `json:"is_active"`
+	// Role is lead, maintainer, member or trainee. It defaults to member
+	// for new members.
+	Role string `json:"role,omitempty"`
 }
 
 type SetUserActiveRequest struct {
@@ -16,10 +23,16 @@ type SetUserActiveRequest struct {
 	IsActive bool   `json:"is_active"`
 }
 
+type EraseUserRequest struct {
+	UserID string `json:"user_id"`
+}
+
 type CreatePullRequestRequest struct {
 	ID       string `json:"pull_request_id"`
 	Name     string `json:"pull_request_name"`
 	AuthorID string `json:"author_id"`
+	// TeamName is required when the author is in more than one team.
+	TeamName string `json:"team_name,omitempty"`
 }
 
 type MergePullRequestRequest struct {
@@ -30,3 +43,48 @@ type ReassignReviewerRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_reviewer_id"`
 }
+
+type AddTeamMembersRequest struct {
+	TeamName string          `json:"team_name"`
+	Members  []TeamMemberDTO `json:"members"`
+	// OnConflict is "reject" (default) or "upsert".
+	OnConflict string `json:"on_conflict,omitempty"`
+}
+
+type RemoveTeamMemberRequest struct {
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+}
+
+type TransferTeamMemberRequest struct {
+	UserID   string `json:"user_id"`
+	FromTeam string `json:"from_team"`
+	ToTeam   string `json:"to_team"`
+}
+
+type RestoreTeamRequest struct {
+	TeamName string `json:"team_name"`
+}
+
+// RenameTeamRequest names the team by TeamID or, failing that, TeamName.
+type RenameTeamRequest struct {
+	TeamID   string `json:"team_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+	NewName  string `json:"new_name"`
+}
+
+// SetTeamParentRequest names the team like RenameTeamRequest. An empty
+// ParentName makes the team top-level.
+type SetTeamParentRequest struct {
+	TeamID     string `json:"team_id,omitempty"`
+	TeamName   string `json:"team_name,omitempty"`
+	ParentName string `json:"parent_team"`
+}
+
+// SetMemberRoleRequest names the team like RenameTeamRequest.
+type SetMemberRoleRequest struct {
+	TeamID   string `json:"team_id,omitempty"`
+	TeamName string `json:"team_name,omitempty"`
+	UserID   string `json:"user_id"`
+	Role     string `json:"role"`
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
 
 type TeamResponse struct {
 	Name    string          `json:"team_name"`
@@ -10,14 +14,39 @@ type TeamResponse struct {
 type UserResponse struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
+	// TeamName is kept for older clients and is only set for users in
+	// exactly one team; Teams is authoritative.
+	TeamName string   `json:"team_name"`
+	Teams    []string `json:"teams"`
+	IsActive bool     `json:"is_active"`
+}
+
+type UserMembershipResponse struct {
 	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	Role     string `json:"role"`
+}
+
+// UserDetailsResponse is a user directory entry.
+type UserDetailsResponse struct {
+	UserResponse
+	Memberships []UserMembershipResponse `json:"memberships"`
+	// OpenReviews counts the open pull requests the user reviews.
+	OpenReviews int `json:"open_reviews"`
+}
+
+type UserListResponse struct {
+	Users []UserDetailsResponse `json:"users"`
+	// Total counts every user matching the filters, not only this page.
+	Total  int `json:"total"`
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
 }
 
 type PullRequestResponse struct {
 	ID                string     `json:"pull_request_id"`
 	Name              string     `json:"pull_request_name"`
 	AuthorID          string     `json:"author_id"`
+	TeamName          string     `json:"team_name,omitempty"`
 	Status            string     `json:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers"`
 	CreatedAt         *time.Time `json:"createdAt,omitempty"`
@@ -40,3 +69,79 @@ type ReassignResponse struct {
 	PR         PullRequestResponse `json:"pr"`
 	ReplacedBy string              `json:"replaced_by"`
 }
+
+type ReviewerChangeResponse struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id,omitempty"` // empty if unassigned
+}
+
+type MembershipChangeResponse struct {
+	UserID   string                   `json:"user_id"`
+	FromTeam string                   `json:"from_team"`
+	ToTeam   string                   `json:"to_team,omitempty"`
+	Reviews  []ReviewerChangeResponse `json:"reviews"`
+}
+
+type MemberDiffResponse struct {
+	UserID      string   `json:"user_id"`
+	Action      string   `json:"action"`
+	Username    string   `json:"username"`
+	OldUsername string   `json:"old_username,omitempty"`
+	IsActive    bool     `json:"is_active"`
+	OldIsActive *bool    `json:"old_is_active,omitempty"`
+	OtherTeams  []string `json:"other_teams,omitempty"`
+	Conflict    bool     `json:"conflict"`
+}
+
+type TeamPlanResponse struct {
+	TeamName string               `json:"team_name"`
+	DryRun   bool                 `json:"dry_run"`
+	Members  []MemberDiffResponse `json:"members"`
+}
+
+type MemberConflictResponse struct {
+	domain.ErrorResponse
+	Conflicts []MemberDiffResponse `json:"conflicts"`
+}
+
+type TeamDeletionResponse struct {
+	TeamName           string                   `json:"team_name"`
+	ClosedPullRequests []string                 `json:"closed_pull_requests"`
+	MovedPullRequests  []string                 `json:"moved_pull_requests"`
+	ReassignedTo       string                   `json:"reassigned_to,omitempty"`
+	Reviews            []ReviewerChangeResponse `json:"reviews"`
+}
+
+// SyncChangeResponse is one step of a roster sync; only the fields its
+// action uses are set. IsActive is set for user changes.
+type SyncChangeResponse struct {
+	Action     string `json:"action"`
+	TeamName   string `json:"team_name,omitempty"`
+	FromTeam   string `json:"from_team,omitempty"`
+	ParentName string `json:"parent_team,omitempty"`
+	UserID     string `json:"user_id,omitempty"`
+	Username   string `json:"username,omitempty"`
+	IsActive   *bool  `json:"is_active,omitempty"`
+	Role       string `json:"role,omitempty"`
+}
+
+type SyncPlanResponse struct {
+	DryRun             bool                     `json:"dry_run"`
+	Changes            []SyncChangeResponse     `json:"changes"`
+	ClosedPullRequests []string                 `json:"closed_pull_requests"`
+	Reviews            []ReviewerChangeResponse `json:"reviews"`
+}
+
+// ErasureReceiptResponse reports a user erasure. The *_kept counts are the
+// records now under the pseudonym.
+type ErasureReceiptResponse struct {
+	UserID           string                   `json:"user_id"`
+	Pseudonym        string                   `json:"pseudonym"`
+	ErasedAt         time.Time                `json:"erased_at"`
+	Teams            []string                 `json:"teams"`
+	Reviews          []ReviewerChangeResponse `json:"reviews"`
+	PullRequestsKept int                      `json:"pull_requests_kept"`
+	ReviewsKept      int                      `json:"reviews_kept"`
+	EventsScrubbed   int                      `json:"events_scrubbed"`
+}
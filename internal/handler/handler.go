@@ -10,6 +10,8 @@ type Handler struct {
 	PR     *PullRequestHandler
 	Stats  *StatsHandler
 	Health *HealthHandler
+	Admin  *AdminHandler
+	SCIM   *SCIMHandler
 }
 
 func New(team *service.TeamService, user *service.UserService, pr *service.PullRequestService, stats *service.StatsService) *Handler {
@@ -19,5 +21,7 @@ func New(team *service.TeamService, user *service.UserService, pr *service.PullR
 		PR:     NewPullRequestHandler(pr),
 		Stats:  NewStatsHandler(stats),
 		Health: NewHealthHandler(),
+		Admin:  NewAdminHandler("", team),
+		SCIM:   NewSCIMHandler("", user, team),
 	}
 }
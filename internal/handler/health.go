@@ -1,25 +1,138 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/111zxc/pr-review-service/internal/logger"
 )
 
-type HealthHandler struct{}
+const (
+	statusOK   = "ok"
+	statusFail = "fail"
+
+	readinessTimeout = 2 * time.Second
+)
+
+// CheckFunc is a readiness check. Details are reported alongside the check
+// result whether or not it failed.
+type CheckFunc func(ctx context.Context) (details map[string]any, err error)
+
+type CheckResult struct {
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	DurationMS float64        `json:"duration_ms"`
+	Details    map[string]any `json:"details,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status    string                 `json:"status"`
+	Timestamp string                 `json:"timestamp"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+type HealthHandler struct {
+	mu     sync.RWMutex
+	checks []namedCheck
+}
 
 func NewHealthHandler() *HealthHandler {
 	return &HealthHandler{}
 }
 
+// AddCheck registers a dependency that must be healthy for /health/ready to
+// report the instance as ready.
+func (h *HealthHandler) AddCheck(name string, check CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// Health is kept for probes configured before /health/live existed.
 func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
-	logger.Debug("Health check called", "method", r.Method, "path", r.URL.Path)
+	h.Live(w, r)
+}
+
+// Live reports that the process is up and serving HTTP. It never touches
+// dependencies, so a database outage doesn't get the instance restarted.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger.For(ctx, "handler").Debug("Health check called")
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_, err := w.Write([]byte(`{"status":"ok","timestamp":"` + time.Now().Format(time.RFC3339) + `"}`))
 	if err != nil {
-		logger.Error("couldn't write health response", "error", err)
+		logger.For(ctx, "handler").Error("couldn't write health response", "error", err)
+	}
+}
+
+// Ready runs every registered check concurrently and returns 503 when any
+// of them fails.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	h.mu.RLock()
+	checks := h.checks
+	h.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runCheck(ctx, c.check)
+		}()
+	}
+	wg.Wait()
+
+	resp := ReadinessResponse{
+		Status:    statusOK,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Checks:    make(map[string]CheckResult, len(checks)),
+	}
+	for i, c := range checks {
+		resp.Checks[c.name] = results[i]
+		if results[i].Status != statusOK {
+			resp.Status = statusFail
+			logger.For(ctx, "handler").Warn("Readiness check failed", "check", c.name, "error", results[i].Error)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status == statusOK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.For(ctx, "handler").Error("couldn't write readiness response", "error", err)
+	}
+}
+
+func runCheck(ctx context.Context, check CheckFunc) CheckResult {
+	start := time.Now()
+	details, err := check(ctx)
+
+	result := CheckResult{
+		Status:     statusOK,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:    details,
+	}
+	if err != nil {
+		result.Status = statusFail
+		result.Error = err.Error()
 	}
+	return result
 }
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
@@ -19,29 +20,38 @@ func NewPullRequestHandler(prService *service.PullRequestService) *PullRequestHa
 }
 
 func (h *PullRequestHandler) CreatePullRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	var req dto.CreatePullRequestRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Error("Failed to decode request", "error", err)
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
 		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
 		return
 	}
 
+	ctx = logger.With(ctx, "pr_id", req.ID, "author_id", req.AuthorID)
+
 	pr := &domain.PullRequest{
 		ID:       req.ID,
 		Name:     req.Name,
 		AuthorID: req.AuthorID,
+		TeamName: req.TeamName,
 	}
 
-	if err := h.prService.CreatePullRequest(pr); err != nil {
-		switch err {
-		case domain.ErrPullRequestExists:
+	if err := h.prService.CreatePullRequest(ctx, pr); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrPullRequestExists):
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusConflict)
 			writeError(w, domain.NewErrorResponse("PR_EXISTS", "PR id already exists"))
-		case domain.ErrUserNotFound, domain.ErrTeamNotFound:
+		case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrTeamNotFound):
 			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		case errors.Is(err, domain.ErrTeamAmbiguous):
+			writeError(w, domain.NewErrorResponse("TEAM_AMBIGUOUS", "author is in several teams, team_name is required"))
+		case errors.Is(err, domain.ErrNotTeamMember):
+			writeError(w, domain.NewErrorResponse("NOT_TEAM_MEMBER", "author is not a member of team_name"))
 		default:
-			logger.Error("Failed to create PR", "error", err)
+			logger.For(ctx, "handler").Error("Failed to create PR", "error", err)
 			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
 		}
 		return
@@ -54,32 +64,39 @@ func (h *PullRequestHandler) CreatePullRequest(w http.ResponseWriter, r *http.Re
 			ID:                pr.ID,
 			Name:              pr.Name,
 			AuthorID:          pr.AuthorID,
+			TeamName:          pr.TeamName,
 			Status:            pr.Status,
 			AssignedReviewers: pr.AssignedReviewers,
 			CreatedAt:         pr.CreatedAt,
 		},
 	})
 	if err != nil {
-		logger.Error("failed to write JSON response", "error", err)
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
 		return
 	}
 }
 
 func (h *PullRequestHandler) MergePullRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	var req dto.MergePullRequestRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Error("Failed to decode request", "error", err)
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
 		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
 		return
 	}
 
-	pr, err := h.prService.MergePullRequest(req.ID)
+	ctx = logger.With(ctx, "pr_id", req.ID)
+
+	pr, err := h.prService.MergePullRequest(ctx, req.ID)
 	if err != nil {
-		switch err {
-		case domain.ErrPullRequestNotFound:
+		switch {
+		case errors.Is(err, domain.ErrPullRequestNotFound):
 			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		case errors.Is(err, domain.ErrPullRequestClosed):
+			writeError(w, domain.NewErrorResponse("PR_CLOSED", "cannot merge a closed PR"))
 		default:
-			logger.Error("Failed to merge PR", "error", err)
+			logger.For(ctx, "handler").Error("Failed to merge PR", "error", err)
 			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
 		}
 		return
@@ -91,6 +108,7 @@ func (h *PullRequestHandler) MergePullRequest(w http.ResponseWriter, r *http.Req
 			ID:                pr.ID,
 			Name:              pr.Name,
 			AuthorID:          pr.AuthorID,
+			TeamName:          pr.TeamName,
 			Status:            pr.Status,
 			AssignedReviewers: pr.AssignedReviewers,
 			CreatedAt:         pr.CreatedAt,
@@ -98,34 +116,38 @@ func (h *PullRequestHandler) MergePullRequest(w http.ResponseWriter, r *http.Req
 		},
 	})
 	if err != nil {
-		logger.Error("failed to write JSON response", "error", err)
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
 		return
 	}
 }
 
 func (h *PullRequestHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	var req dto.ReassignReviewerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Error("Failed to decode request", "error", err)
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
 		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
 		return
 	}
 
-	pr, replacedBy, err := h.prService.ReassignReviewer(req.PullRequestID, req.OldUserID)
+	ctx = logger.With(ctx, "pr_id", req.PullRequestID, "old_user_id", req.OldUserID)
+
+	pr, replacedBy, err := h.prService.ReassignReviewer(ctx, req.PullRequestID, req.OldUserID)
 	if err != nil {
-		switch err {
-		case domain.ErrPullRequestNotFound:
+		switch {
+		case errors.Is(err, domain.ErrPullRequestNotFound):
 			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
-		case domain.ErrPullRequestMerged:
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
+		case errors.Is(err, domain.ErrPullRequestMerged):
 			writeError(w, domain.NewErrorResponse("PR_MERGED", "cannot reassign on merged PR"))
-		case domain.ErrReviewerNotAssigned:
+		case errors.Is(err, domain.ErrPullRequestClosed):
+			writeError(w, domain.NewErrorResponse("PR_CLOSED", "cannot reassign on closed PR"))
+		case errors.Is(err, domain.ErrReviewerNotAssigned):
 			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
-		case domain.ErrNoCandidate:
+		case errors.Is(err, domain.ErrNoCandidate):
 			writeError(w, domain.NewErrorResponse("NO_CANDIDATE", "no active replacement candidate in team"))
 		default:
-			logger.Error("Failed to reassign reviewer", "error", err)
+			logger.For(ctx, "handler").Error("Failed to reassign reviewer", "error", err)
 			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
 		}
 		return
@@ -137,6 +159,7 @@ func (h *PullRequestHandler) ReassignReviewer(w http.ResponseWriter, r *http.Req
 			ID:                pr.ID,
 			Name:              pr.Name,
 			AuthorID:          pr.AuthorID,
+			TeamName:          pr.TeamName,
 			Status:            pr.Status,
 			AssignedReviewers: pr.AssignedReviewers,
 			CreatedAt:         pr.CreatedAt,
@@ -145,7 +168,7 @@ func (h *PullRequestHandler) ReassignReviewer(w http.ResponseWriter, r *http.Req
 		ReplacedBy: replacedBy,
 	})
 	if err != nil {
-		logger.Error("failed to write json response", "error", err)
+		logger.For(ctx, "handler").Error("failed to write json response", "error", err)
 		return
 	}
 }
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/111zxc/pr-review-service/internal/auth"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/metrics"
+)
+
+const (
+	limiterIdleTTL = 10 * time.Minute
+	// maxLimiterClients caps the buckets kept in memory. When it is reached
+	// the least recently seen client loses its bucket.
+	maxLimiterClients = 10000
+)
+
+type RateLimitOptions struct {
+	RequestsPerSecond float64
+	Burst             int
+	TrustForwardedFor bool
+	// TrustedProxies is the number of proxies in front of the service that
+	// append to X-Forwarded-For. The client is the entry that many places
+	// from the right; anything left of it may be forged. 0 means 1.
+	TrustedProxies int
+	ExemptPaths    []string
+}
+
+type clientLimiter struct {
+	key      string
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type rateLimiter struct {
+	opts RateLimitOptions
+
+	mu      sync.Mutex
+	clients map[string]*list.Element
+	// recent orders the clients from most to least recently seen.
+	recent *list.List
+}
+
+// NewRateLimitMiddleware applies a token bucket per client. It runs after the
+// auth middleware: clients are identified by the verified principal, and by
+// IP when there is none.
+func NewRateLimitMiddleware(opts RateLimitOptions) func(http.Handler) http.Handler {
+	rl := &rateLimiter{
+		opts:    opts,
+		clients: make(map[string]*list.Element),
+		recent:  list.New(),
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isExempt(r.URL.Path, opts.ExemptPaths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			reservation := rl.limiterFor(rl.clientKey(r)).Reserve()
+			if delay := reservation.Delay(); delay > 0 {
+				reservation.Cancel()
+				metrics.ThrottledRequests.Inc("rate_limit")
+				writeTooManyRequests(w, delay, "rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (rl *rateLimiter) limiterFor(key string) *rate.Limiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	for e := rl.recent.Back(); e != nil && now.Sub(e.Value.(*clientLimiter).lastSeen) > limiterIdleTTL; e = rl.recent.Back() {
+		rl.evict(e)
+	}
+
+	if e, ok := rl.clients[key]; ok {
+		c := e.Value.(*clientLimiter)
+		c.lastSeen = now
+		rl.recent.MoveToFront(e)
+		return c.limiter
+	}
+
+	if len(rl.clients) >= maxLimiterClients {
+		rl.evict(rl.recent.Back())
+	}
+	c := &clientLimiter{
+		key:      key,
+		limiter:  rate.NewLimiter(rate.Limit(rl.opts.RequestsPerSecond), rl.opts.Burst),
+		lastSeen: now,
+	}
+	rl.clients[key] = rl.recent.PushFront(c)
+	return c.limiter
+}
+
+func (rl *rateLimiter) evict(e *list.Element) {
+	rl.recent.Remove(e)
+	delete(rl.clients, e.Value.(*clientLimiter).key)
+}
+
+func (rl *rateLimiter) clientKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		return "user:" + principal.UserID
+	}
+
+	if rl.opts.TrustForwardedFor {
+		if client := forwardedClient(r.Header.Values("X-Forwarded-For"), rl.opts.TrustedProxies); client != "" {
+			return "ip:" + client
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// forwardedClient returns the X-Forwarded-For entry added by the outermost
+// of trustedProxies proxies, counting from the right. When the header is
+// shorter, every entry came from a trusted proxy and the left-most is used.
+func forwardedClient(headers []string, trustedProxies int) string {
+	var hops []string
+	for _, h := range headers {
+		for _, hop := range strings.Split(h, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	if len(hops) == 0 {
+		return ""
+	}
+	return hops[max(len(hops)-max(trustedProxies, 1), 0)]
+}
+
+// NewConcurrencyLimitMiddleware caps the number of requests served at once so
+// that bursts queue briefly instead of exhausting the DB pool.
+func NewConcurrencyLimitMiddleware(maxInFlight int, queueTimeout time.Duration, exemptPaths ...string) func(http.Handler) http.Handler {
+	slots := make(chan struct{}, maxInFlight)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isExempt(r.URL.Path, exemptPaths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), queueTimeout)
+			defer cancel()
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				metrics.ThrottledRequests.Inc("concurrency")
+				writeTooManyRequests(w, time.Second, "too many concurrent requests")
+				return
+			}
+			defer func() { <-slots }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func isExempt(path string, exemptPaths []string) bool {
+	for _, prefix := range exemptPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTooManyRequests(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	writeError(w, domain.NewErrorResponse("RATE_LIMITED", message))
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/111zxc/pr-review-service/internal/logger"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+// requestInfo collects fields for the access log that are only known to
+// inner middlewares, such as the authenticated principal.
+type requestInfo struct {
+	principal string
+}
+
+type requestInfoKey struct{}
+
+func setRequestPrincipal(ctx context.Context, userID string) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.principal = userID
+	}
+}
+
+// NewRequestLogMiddleware assigns every request an ID, taken from
+// X-Request-ID when the client sent a sane one, stores a logger carrying it
+// in the request context and writes one access-log line per request.
+func NewRequestLogMiddleware(routeOf func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = rand.Text()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			route := routeOf(r)
+			if route == "" {
+				route = "unmatched"
+			}
+
+			attrs := []any{"request_id", requestID, "route", route}
+			span := trace.SpanFromContext(r.Context())
+			if sc := span.SpanContext(); sc.IsValid() {
+				attrs = append(attrs, "trace_id", sc.TraceID().String())
+			}
+			span.SetAttributes(attribute.String("http.request_id", requestID))
+
+			log := logger.FromContext(r.Context()).With(attrs...)
+			info := &requestInfo{}
+			ctx := context.WithValue(logger.WithContext(r.Context(), log), requestInfoKey{}, info)
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			fields := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", loggedPath(r, route)),
+				slog.Int("status", rec.Status()),
+				slog.Int("bytes", rec.bytes),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote_addr", r.RemoteAddr),
+			}
+			if info.principal != "" {
+				fields = append(fields, slog.String("principal", info.principal))
+			}
+			log.LogAttrs(ctx, slog.LevelInfo, "http request", fields...)
+		})
+	}
+}
+
+// loggedPath is the path written to logs and spans. Routes registered with
+// a trailing slash take an ID in the rest of the path, such as a SCIM user
+// ID; it is replaced by a placeholder so those IDs stay out of logs.
+func loggedPath(r *http.Request, route string) string {
+	if strings.HasSuffix(route, "/") && len(r.URL.Path) > len(route) {
+		return route + "{id}"
+	}
+	return r.URL.Path
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,515 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/scim"
+	"github.com/111zxc/pr-review-service/internal/service"
+)
+
+const scimPrefix = "/scim/v2"
+
+// SCIMHandler serves the /scim/v2 Users and Groups resources that identity
+// providers provision through. Users map onto users, groups onto teams.
+type SCIMHandler struct {
+	token       string
+	userService *service.UserService
+	teamService *service.TeamService
+}
+
+// NewSCIMHandler serves SCIM to clients holding the bearer token. With an
+// empty token the endpoints are disabled.
+func NewSCIMHandler(token string, userService *service.UserService, teamService *service.TeamService) *SCIMHandler {
+	return &SCIMHandler{token: token, userService: userService, teamService: teamService}
+}
+
+func (h *SCIMHandler) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if h.token == "" {
+		writeSCIM(w, http.StatusNotFound, scim.NewError(http.StatusNotFound, "", "resource not found"))
+		return false
+	}
+
+	got, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+		writeSCIM(w, http.StatusUnauthorized, scim.NewError(http.StatusUnauthorized, "", "a valid bearer token is required"))
+		return false
+	}
+	return true
+}
+
+func (h *SCIMHandler) ServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	writeSCIM(w, http.StatusOK, scim.ServiceProviderConfig())
+}
+
+// Users lists users on GET and creates one on POST.
+func (h *SCIMHandler) Users(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.listUsers(w, r)
+	case http.MethodPost:
+		h.createUser(w, r)
+	default:
+		writeSCIM(w, http.StatusMethodNotAllowed, scim.NewError(http.StatusMethodNotAllowed, "", "only GET and POST are allowed"))
+	}
+}
+
+// User reads, patches or deletes the user at /scim/v2/Users/{id}.
+func (h *SCIMHandler) User(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, scimPrefix+"/Users/")
+	ctx := logger.With(r.Context(), "user_id", id)
+
+	switch r.Method {
+	case http.MethodGet:
+		user, err := h.userService.GetUser(ctx, id)
+		if err != nil {
+			h.writeError(ctx, w, err)
+			return
+		}
+		h.writeUser(ctx, w, r, http.StatusOK, user)
+	case http.MethodPatch:
+		h.patchUser(ctx, w, r, id)
+	case http.MethodDelete:
+		// Identity providers delete users routinely, so this is a soft
+		// delete that a later POST undoes; erasure stays with
+		// /admin/users/erase.
+		released, err := h.teamService.DeleteUser(ctx, id)
+		if err != nil {
+			h.writeError(ctx, w, err)
+			return
+		}
+		logger.For(ctx, "handler").Info("SCIM user deleted", "reviews_released", len(released))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeSCIM(w, http.StatusMethodNotAllowed, scim.NewError(http.StatusMethodNotAllowed, "", "only GET, PATCH and DELETE are allowed"))
+	}
+}
+
+func (h *SCIMHandler) listUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	page, err := scim.ParsePage(query.Get("startIndex"), query.Get("count"))
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	filter, err := scim.ParseFilter(query.Get("filter"))
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	userFilter := domain.UserFilter{Offset: page.Offset(), Limit: max(page.Count, 1)}
+	var users []*domain.User
+	var total int
+	switch attr := filterAttribute(filter); attr {
+	case "", "username", "active":
+		if attr == "username" {
+			userFilter.Username = filter.Value
+		}
+		if attr == "active" {
+			active, err := filter.Bool()
+			if err != nil {
+				h.writeError(ctx, w, err)
+				return
+			}
+			userFilter.IsActive = &active
+		}
+		if users, total, err = h.userService.ListUsers(ctx, userFilter); err != nil {
+			h.writeError(ctx, w, err)
+			return
+		}
+	case "id", "externalid":
+		user, err := h.userService.GetUser(ctx, filter.Value)
+		switch {
+		case err == nil:
+			total = 1
+			if page.Offset() == 0 {
+				users = []*domain.User{user}
+			}
+		case !errors.Is(err, domain.ErrUserNotFound):
+			h.writeError(ctx, w, err)
+			return
+		}
+	default:
+		h.writeError(ctx, w, scim.NewError(http.StatusBadRequest, scim.ErrInvalidFilter, "users can be filtered by userName, externalId, id or active"))
+		return
+	}
+	users = users[:min(len(users), page.Count)]
+
+	teamIDs, err := h.teamIDs(ctx)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	resources := make([]scim.User, 0, len(users))
+	for _, user := range users {
+		resources = append(resources, userResource(r, user, teamIDs))
+	}
+	writeSCIM(w, http.StatusOK, scim.NewListResponse(resources, total, page.StartIndex))
+}
+
+func (h *SCIMHandler) createUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// RFC 7643 gives active no default; a user sent without it is active.
+	req := scim.User{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(ctx, w, scim.NewError(http.StatusBadRequest, scim.ErrInvalidSyntax, "invalid request body"))
+		return
+	}
+	if req.UserName == "" {
+		h.writeError(ctx, w, scim.NewError(http.StatusBadRequest, scim.ErrInvalidValue, "userName is required"))
+		return
+	}
+
+	user := &domain.User{ID: req.ExternalID, Username: req.UserName, IsActive: req.Active}
+	if user.ID == "" {
+		user.ID = req.UserName
+	}
+	ctx = logger.With(ctx, "user_id", user.ID)
+
+	if err := h.userService.CreateUser(ctx, user); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	h.writeUser(ctx, w, r, http.StatusCreated, user)
+}
+
+func (h *SCIMHandler) patchUser(ctx context.Context, w http.ResponseWriter, r *http.Request, id string) {
+	var req scim.PatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(ctx, w, scim.NewError(http.StatusBadRequest, scim.ErrInvalidSyntax, "invalid request body"))
+		return
+	}
+
+	user, err := h.userService.GetUser(ctx, id)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	patched := scim.User{UserName: user.Username, Active: user.IsActive}
+	if err := patched.Apply(req.Operations); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	if patched.UserName != user.Username {
+		if user, err = h.userService.RenameUser(ctx, id, patched.UserName); err != nil {
+			h.writeError(ctx, w, err)
+			return
+		}
+	}
+	// Deactivation goes through SetUserActive like /users/setIsActive, so
+	// the user's open reviews are released.
+	if patched.Active != user.IsActive {
+		if user, err = h.userService.SetUserActive(ctx, id, patched.Active); err != nil {
+			h.writeError(ctx, w, err)
+			return
+		}
+	}
+	h.writeUser(ctx, w, r, http.StatusOK, user)
+}
+
+// Groups lists teams on GET and creates one on POST.
+func (h *SCIMHandler) Groups(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.listGroups(w, r)
+	case http.MethodPost:
+		h.createGroup(w, r)
+	default:
+		writeSCIM(w, http.StatusMethodNotAllowed, scim.NewError(http.StatusMethodNotAllowed, "", "only GET and POST are allowed"))
+	}
+}
+
+// Group reads, patches or deletes the team at /scim/v2/Groups/{team_id}.
+func (h *SCIMHandler) Group(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(w, r) {
+		return
+	}
+	ref := domain.TeamRef{ID: strings.TrimPrefix(r.URL.Path, scimPrefix+"/Groups/")}
+	ctx := logger.With(r.Context(), "team_id", ref.ID)
+
+	team, err := h.teamService.GetTeam(ctx, ref)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeSCIM(w, http.StatusOK, groupResource(r, team))
+	case http.MethodPatch:
+		h.patchGroup(ctx, w, r, team)
+	case http.MethodDelete:
+		// A team with open pull requests is kept (409) as with DELETE
+		// /team; closing them is left to an explicit open_prs=close.
+		if _, err := h.teamService.DeleteTeam(ctx, team.Name, service.DeleteTeamOptions{}); err != nil {
+			h.writeError(ctx, w, err)
+			return
+		}
+		logger.For(ctx, "handler").Info("SCIM group deleted")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeSCIM(w, http.StatusMethodNotAllowed, scim.NewError(http.StatusMethodNotAllowed, "", "only GET, PATCH and DELETE are allowed"))
+	}
+}
+
+func (h *SCIMHandler) listGroups(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	page, err := scim.ParsePage(query.Get("startIndex"), query.Get("count"))
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	filter, err := scim.ParseFilter(query.Get("filter"))
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	var match func(team *domain.Team) bool
+	switch filterAttribute(filter) {
+	case "":
+		match = func(*domain.Team) bool { return true }
+	case "displayname":
+		match = func(team *domain.Team) bool { return team.Name == filter.Value }
+	case "id":
+		match = func(team *domain.Team) bool { return team.ID == filter.Value }
+	default:
+		h.writeError(ctx, w, scim.NewError(http.StatusBadRequest, scim.ErrInvalidFilter, "groups can be filtered by displayName or id"))
+		return
+	}
+
+	teams, err := h.teamService.ListTeams(ctx)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	teams = slices.DeleteFunc(teams, func(team *domain.Team) bool { return !match(team) })
+
+	total := len(teams)
+	teams = teams[min(page.Offset(), total):]
+	teams = teams[:min(len(teams), page.Count)]
+
+	resources := make([]scim.Group, 0, len(teams))
+	for _, team := range teams {
+		resources = append(resources, groupResource(r, team))
+	}
+	writeSCIM(w, http.StatusOK, scim.NewListResponse(resources, total, page.StartIndex))
+}
+
+func (h *SCIMHandler) createGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req scim.Group
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(ctx, w, scim.NewError(http.StatusBadRequest, scim.ErrInvalidSyntax, "invalid request body"))
+		return
+	}
+	if req.DisplayName == "" {
+		h.writeError(ctx, w, scim.NewError(http.StatusBadRequest, scim.ErrInvalidValue, "displayName is required"))
+		return
+	}
+	ctx = logger.With(ctx, "team_name", req.DisplayName)
+
+	// Members are existing users; they join with the data they already
+	// have, so upserting them changes nothing.
+	team := &domain.Team{Name: req.DisplayName}
+	for _, ref := range req.Members {
+		user, err := h.userService.GetUser(ctx, ref.Value)
+		if err != nil {
+			h.writeError(ctx, w, memberError(err, ref.Value))
+			return
+		}
+		team.Members = append(team.Members, domain.TeamMember{
+			UserID:   user.ID,
+			Username: user.Username,
+			IsActive: user.IsActive,
+			Role:     domain.RoleMember,
+		})
+	}
+
+	_, err := h.teamService.CreateTeam(ctx, team, service.CreateTeamOptions{OnConflict: domain.ConflictUpsert})
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	writeSCIM(w, http.StatusCreated, groupResource(r, team))
+}
+
+func (h *SCIMHandler) patchGroup(ctx context.Context, w http.ResponseWriter, r *http.Request, team *domain.Team) {
+	var req scim.PatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(ctx, w, scim.NewError(http.StatusBadRequest, scim.ErrInvalidSyntax, "invalid request body"))
+		return
+	}
+
+	current := groupResource(r, team)
+	patched := current
+	patched.Members = slices.Clone(current.Members)
+	if err := patched.Apply(req.Operations); err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+
+	ref := domain.TeamRef{ID: team.ID}
+	var err error
+	if patched.DisplayName != current.DisplayName {
+		if team, err = h.teamService.RenameTeam(ctx, ref, patched.DisplayName); err != nil {
+			h.writeError(ctx, w, err)
+			return
+		}
+	}
+
+	sameMembers := len(patched.Members) == len(current.Members) &&
+		!slices.ContainsFunc(patched.Members, func(m scim.Ref) bool { return !current.HasMember(m.Value) })
+	if !sameMembers {
+		userIDs := make([]string, 0, len(patched.Members))
+		for _, m := range patched.Members {
+			userIDs = append(userIDs, m.Value)
+		}
+		var changes []domain.ReviewerChange
+		if team, changes, err = h.teamService.SetMembers(ctx, ref, userIDs); err != nil {
+			h.writeError(ctx, w, memberError(err, ""))
+			return
+		}
+		logger.For(ctx, "handler").Info("SCIM group members set", "reviews_released", len(changes))
+	}
+
+	writeSCIM(w, http.StatusOK, groupResource(r, team))
+}
+
+// teamIDs maps team names to IDs for the groups of user resources.
+func (h *SCIMHandler) teamIDs(ctx context.Context) (map[string]string, error) {
+	teams, err := h.teamService.ListTeams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]string, len(teams))
+	for _, team := range teams {
+		ids[team.Name] = team.ID
+	}
+	return ids, nil
+}
+
+func (h *SCIMHandler) writeUser(ctx context.Context, w http.ResponseWriter, r *http.Request, status int, user *domain.User) {
+	teamIDs, err := h.teamIDs(ctx)
+	if err != nil {
+		h.writeError(ctx, w, err)
+		return
+	}
+	if status == http.StatusCreated {
+		w.Header().Set("Location", resourceURL(r, "Users", user.ID))
+	}
+	writeSCIM(w, status, userResource(r, user, teamIDs))
+}
+
+func (h *SCIMHandler) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	var scimErr *scim.Error
+	switch {
+	case errors.As(err, &scimErr):
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrTeamNotFound):
+		scimErr = scim.NewError(http.StatusNotFound, "", err.Error())
+	case errors.Is(err, domain.ErrUserExists), errors.Is(err, domain.ErrTeamExists):
+		scimErr = scim.NewError(http.StatusConflict, scim.ErrUniqueness, err.Error())
+	case errors.Is(err, domain.ErrTeamHasOpenPRs):
+		scimErr = scim.NewError(http.StatusConflict, scim.ErrMutability, "team has open pull requests")
+	case errors.Is(err, domain.ErrInvalidInput):
+		scimErr = scim.NewError(http.StatusBadRequest, scim.ErrInvalidValue, err.Error())
+	default:
+		logger.For(ctx, "handler").Error("SCIM request failed", "error", err)
+		scimErr = scim.NewError(http.StatusInternalServerError, "", "Internal server error")
+	}
+	writeSCIM(w, scimErr.HTTPStatus(), scimErr)
+}
+
+// memberError reports an unknown member as a bad value rather than the
+// group itself not being found.
+func memberError(err error, userID string) error {
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	detail := "members must be existing users"
+	if userID != "" {
+		detail = "unknown member " + userID
+	}
+	return scim.NewError(http.StatusBadRequest, scim.ErrInvalidValue, detail)
+}
+
+func filterAttribute(filter *scim.Filter) string {
+	if filter == nil {
+		return ""
+	}
+	return filter.Attribute
+}
+
+func userResource(r *http.Request, user *domain.User, teamIDs map[string]string) scim.User {
+	res := scim.User{
+		Schemas:    []string{scim.UserSchema},
+		ID:         user.ID,
+		ExternalID: user.ID,
+		UserName:   user.Username,
+		Active:     user.IsActive,
+		Meta:       &scim.Meta{ResourceType: "User", Location: resourceURL(r, "Users", user.ID)},
+	}
+	for _, team := range user.Teams {
+		res.Groups = append(res.Groups, scim.Ref{Value: teamIDs[team], Display: team})
+	}
+	return res
+}
+
+func groupResource(r *http.Request, team *domain.Team) scim.Group {
+	res := scim.Group{
+		Schemas:     []string{scim.GroupSchema},
+		ID:          team.ID,
+		DisplayName: team.Name,
+		Members:     make([]scim.Ref, 0, len(team.Members)),
+		Meta:        &scim.Meta{ResourceType: "Group", Location: resourceURL(r, "Groups", team.ID)},
+	}
+	for _, m := range team.Members {
+		res.Members = append(res.Members, scim.Ref{Value: m.UserID, Display: m.Username})
+	}
+	return res
+}
+
+func resourceURL(r *http.Request, resource, id string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + scimPrefix + "/" + resource + "/" + id
+}
+
+func writeSCIM(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", scim.ContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Error("failed to write JSON response", "error", err)
+	}
+}
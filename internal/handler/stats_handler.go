@@ -18,6 +18,8 @@ func NewStatsHandler(statsService *service.StatsService) *StatsHandler {
 }
 
 func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	if r.Method != http.MethodGet {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -31,15 +33,15 @@ func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 			},
 		})
 		if err != nil {
-			logger.Error("failed to write JSON response", "error", err)
+			logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
 			return
 		}
 		return
 	}
 
-	stats, err := h.statsService.GetStats()
+	stats, err := h.statsService.GetStats(ctx)
 	if err != nil {
-		logger.Error("Failed to get stats", "error", err)
+		logger.For(ctx, "handler").Error("Failed to get stats", "error", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		err = json.NewEncoder(w).Encode(domain.ErrorResponse{
@@ -52,7 +54,7 @@ func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 			},
 		})
 		if err != nil {
-			logger.Error("failed to write JSON response", "error", err)
+			logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
 			return
 		}
 		return
@@ -61,7 +63,7 @@ func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err = json.NewEncoder(w).Encode(stats); err != nil {
-		logger.Error("failed to write JSON response", "error", err)
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
 		return
 	}
 }
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
@@ -19,83 +21,578 @@ func NewTeamHandler(teamService *service.TeamService) *TeamHandler {
 }
 
 func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	var req dto.CreateTeamRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Error("Failed to decode request", "error", err)
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
 		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
 		return
 	}
 
+	ctx = logger.With(ctx, "team_name", req.Name)
+
+	onConflict, err := domain.ParseConflictPolicy(req.OnConflict)
+	if err != nil {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "on_conflict must be reject or upsert"))
+		return
+	}
+
 	team := &domain.Team{
-		Name: req.Name,
+		Name:       req.Name,
+		ParentName: req.ParentName,
 	}
 
 	for _, member := range req.Members {
+		role, err := domain.ParseTeamRole(member.Role)
+		if err != nil {
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", invalidRoleMessage))
+			return
+		}
 		team.Members = append(team.Members, domain.TeamMember{
 			UserID:   member.UserID,
 			Username: member.Username,
 			IsActive: member.IsActive,
+			Role:     role,
 		})
 	}
 
-	if err := h.teamService.CreateTeam(team); err != nil {
-		switch err {
-		case domain.ErrTeamExists:
+	diffs, err := h.teamService.CreateTeam(ctx, team, service.CreateTeamOptions{
+		DryRun:     req.DryRun,
+		OnConflict: onConflict,
+	})
+	if err != nil {
+		var conflict *domain.MemberConflictError
+		switch {
+		case errors.Is(err, domain.ErrTeamExists):
 			writeError(w, domain.NewErrorResponse("TEAM_EXISTS", "team_name already exists"))
+		case errors.Is(err, domain.ErrTeamNotFound):
+			writeError(w, domain.NewErrorResponse("NOT_FOUND", "parent_team not found"))
+		case errors.As(err, &conflict):
+			writeMemberConflict(ctx, w, conflict)
 		default:
-			logger.Error("Failed to create team", "error", err)
+			logger.For(ctx, "handler").Error("Failed to create team", "error", err)
 			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
 		}
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	err := json.NewEncoder(w).Encode(map[string]interface{}{
+	if req.DryRun {
+		err = json.NewEncoder(w).Encode(dto.TeamPlanResponse{
+			TeamName: team.Name,
+			DryRun:   true,
+			Members:  memberDiffResponses(diffs),
+		})
+	} else {
+		w.WriteHeader(http.StatusCreated)
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"team":    team,
+			"changes": memberDiffResponses(diffs),
+		})
+	}
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+		return
+	}
+}
+
+func writeMemberConflict(ctx context.Context, w http.ResponseWriter, conflict *domain.MemberConflictError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	err := json.NewEncoder(w).Encode(dto.MemberConflictResponse{
+		ErrorResponse: domain.NewErrorResponse("MEMBER_CONFLICT",
+			"members already exist with other data or teams; use on_conflict=upsert to overwrite"),
+		Conflicts: memberDiffResponses(conflict.Conflicts),
+	})
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+	}
+}
+
+func memberDiffResponses(diffs []domain.MemberDiff) []dto.MemberDiffResponse {
+	resp := make([]dto.MemberDiffResponse, 0, len(diffs))
+	for _, d := range diffs {
+		r := dto.MemberDiffResponse{
+			UserID:     d.UserID,
+			Action:     string(d.Action),
+			Username:   d.Username,
+			IsActive:   d.IsActive,
+			OtherTeams: d.OtherTeams,
+			Conflict:   d.Conflicts(),
+		}
+		if d.Action == domain.MemberUpdate {
+			if d.OldUsername != d.Username {
+				r.OldUsername = d.OldUsername
+			}
+			if d.OldIsActive != d.IsActive {
+				r.OldIsActive = &d.OldIsActive
+			}
+		}
+		resp = append(resp, r)
+	}
+	return resp
+}
+
+func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	ref := domain.TeamRef{ID: r.URL.Query().Get("team_id"), Name: r.URL.Query().Get("team_name")}
+	if ref.ID == "" && ref.Name == "" {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "team_id or team_name is required"))
+		return
+	}
+
+	ctx := withTeamRef(r.Context(), ref)
+
+	team, err := h.teamService.GetTeam(ctx, ref)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTeamNotFound):
+			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		default:
+			logger.For(ctx, "handler").Error("Failed to get team", "error", err)
+			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(team); err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+		return
+	}
+}
+
+func (h *TeamHandler) AddMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.AddTeamMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+	if req.TeamName == "" || len(req.Members) == 0 {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "team_name and members are required"))
+		return
+	}
+
+	ctx = logger.With(ctx, "team_name", req.TeamName)
+
+	onConflict, err := domain.ParseConflictPolicy(req.OnConflict)
+	if err != nil {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "on_conflict must be reject or upsert"))
+		return
+	}
+
+	members := make([]domain.TeamMember, 0, len(req.Members))
+	for _, member := range req.Members {
+		// Without a role current members keep theirs and new ones join as
+		// members.
+		var role domain.TeamRole
+		if member.Role != "" {
+			var err error
+			if role, err = domain.ParseTeamRole(member.Role); err != nil {
+				writeError(w, domain.NewErrorResponse("INVALID_INPUT", invalidRoleMessage))
+				return
+			}
+		}
+		members = append(members, domain.TeamMember{
+			UserID:   member.UserID,
+			Username: member.Username,
+			IsActive: member.IsActive,
+			Role:     role,
+		})
+	}
+
+	team, err := h.teamService.AddMembers(ctx, req.TeamName, members, service.AddMembersOptions{OnConflict: onConflict})
+	if err != nil {
+		var conflict *domain.MemberConflictError
+		switch {
+		case errors.Is(err, domain.ErrTeamNotFound):
+			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		case errors.As(err, &conflict):
+			writeMemberConflict(ctx, w, conflict)
+		default:
+			logger.For(ctx, "handler").Error("Failed to add team members", "error", err)
+			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
 		"team": team,
 	})
 	if err != nil {
-		logger.Error("failed to write JSON response", "error", err)
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
 		return
 	}
 }
 
-func (h *TeamHandler) GetTeam(w http.ResponseWriter, r *http.Request) {
-	teamName := r.URL.Query().Get("team_name")
+func (h *TeamHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.RemoveTeamMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+	if req.TeamName == "" || req.UserID == "" {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "team_name and user_id are required"))
+		return
+	}
+
+	ctx = logger.With(ctx, "team_name", req.TeamName, "user_id", req.UserID)
+
+	changes, err := h.teamService.RemoveMember(ctx, req.TeamName, req.UserID)
+	if err != nil {
+		writeMembershipError(ctx, w, err)
+		return
+	}
+
+	writeMembershipChange(ctx, w, dto.MembershipChangeResponse{
+		UserID:   req.UserID,
+		FromTeam: req.TeamName,
+		Reviews:  reviewerChangeResponses(changes),
+	})
+}
+
+func (h *TeamHandler) TransferMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.TransferTeamMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+	if req.UserID == "" || req.FromTeam == "" || req.ToTeam == "" {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "user_id, from_team and to_team are required"))
+		return
+	}
+
+	ctx = logger.With(ctx, "user_id", req.UserID, "from_team", req.FromTeam, "to_team", req.ToTeam)
+
+	changes, err := h.teamService.TransferMember(ctx, req.UserID, req.FromTeam, req.ToTeam)
+	if err != nil {
+		writeMembershipError(ctx, w, err)
+		return
+	}
+
+	writeMembershipChange(ctx, w, dto.MembershipChangeResponse{
+		UserID:   req.UserID,
+		FromTeam: req.FromTeam,
+		ToTeam:   req.ToTeam,
+		Reviews:  reviewerChangeResponses(changes),
+	})
+}
+
+// DeleteTeam handles DELETE /team?team_name=...&open_prs=close|reassign
+// &reassign_to=....
+func (h *TeamHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, domain.NewErrorResponse("METHOD_NOT_ALLOWED", "Only DELETE method is allowed"))
+		return
+	}
+
+	query := r.URL.Query()
+	teamName := query.Get("team_name")
 	if teamName == "" {
 		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "team_name is required"))
 		return
 	}
 
-	team, err := h.teamService.GetTeam(teamName)
+	ctx := logger.With(r.Context(), "team_name", teamName)
+
+	openPRs, err := domain.ParseOpenPRPolicy(query.Get("open_prs"))
 	if err != nil {
-		switch err {
-		case domain.ErrTeamNotFound:
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "open_prs must be close or reassign"))
+		return
+	}
+
+	deletion, err := h.teamService.DeleteTeam(ctx, teamName, service.DeleteTeamOptions{
+		OpenPRs:    openPRs,
+		ReassignTo: query.Get("reassign_to"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTeamNotFound):
 			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		case errors.Is(err, domain.ErrInvalidInput):
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", "reassign_to must name another team"))
+		case errors.Is(err, domain.ErrTeamHasOpenPRs):
+			writeError(w, domain.NewErrorResponse("TEAM_HAS_OPEN_PRS",
+				"team has open pull requests; pass open_prs=close or open_prs=reassign"))
 		default:
-			logger.Error("Failed to get team", "error", err)
+			logger.For(ctx, "handler").Error("Failed to delete team", "error", err)
 			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
 		}
 		return
 	}
 
+	resp := dto.TeamDeletionResponse{
+		TeamName:           teamName,
+		ClosedPullRequests: nonNil(deletion.ClosedPRs),
+		MovedPullRequests:  nonNil(deletion.MovedPRs),
+		Reviews:            reviewerChangeResponses(deletion.Reviews),
+	}
+	if openPRs == domain.OpenPRsReassign {
+		resp.ReassignedTo = query.Get("reassign_to")
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err = json.NewEncoder(w).Encode(team); err != nil {
-		logger.Error("failed to write JSON response", "error", err)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
 		return
 	}
 }
 
+func (h *TeamHandler) RestoreTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.RestoreTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+	if req.TeamName == "" {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "team_name is required"))
+		return
+	}
+
+	ctx = logger.With(ctx, "team_name", req.TeamName)
+
+	team, err := h.teamService.RestoreTeam(ctx, req.TeamName)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTeamNotFound):
+			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		default:
+			logger.For(ctx, "handler").Error("Failed to restore team", "error", err)
+			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"team": team,
+	})
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+		return
+	}
+}
+
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
+
+func writeMembershipError(ctx context.Context, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrTeamNotFound):
+		writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+	case errors.Is(err, domain.ErrNotTeamMember):
+		writeError(w, domain.NewErrorResponse("NOT_TEAM_MEMBER", "user is not a member of the team"))
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "from_team and to_team must differ"))
+	default:
+		logger.For(ctx, "handler").Error("Failed to change team membership", "error", err)
+		writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+	}
+}
+
+func writeMembershipChange(ctx context.Context, w http.ResponseWriter, resp dto.MembershipChangeResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+	}
+}
+
+func reviewerChangeResponses(changes []domain.ReviewerChange) []dto.ReviewerChangeResponse {
+	resp := make([]dto.ReviewerChangeResponse, 0, len(changes))
+	for _, c := range changes {
+		resp = append(resp, dto.ReviewerChangeResponse{
+			PullRequestID: c.PRID,
+			OldReviewerID: c.OldUserID,
+			NewReviewerID: c.NewUserID,
+		})
+	}
+	return resp
+}
+
+func (h *TeamHandler) RenameTeam(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.RenameTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+	ref := domain.TeamRef{ID: req.TeamID, Name: req.TeamName}
+	if (ref.ID == "" && ref.Name == "") || req.NewName == "" {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "team_id or team_name, and new_name are required"))
+		return
+	}
+
+	ctx = withTeamRef(ctx, ref)
+
+	team, err := h.teamService.RenameTeam(ctx, ref, req.NewName)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTeamNotFound):
+			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		case errors.Is(err, domain.ErrTeamExists):
+			writeError(w, domain.NewErrorResponse("TEAM_EXISTS", "new_name already exists"))
+		default:
+			logger.For(ctx, "handler").Error("Failed to rename team", "error", err)
+			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"team": team,
+	})
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+		return
+	}
+}
+
+func (h *TeamHandler) SetParent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.SetTeamParentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+	ref := domain.TeamRef{ID: req.TeamID, Name: req.TeamName}
+	if ref.ID == "" && ref.Name == "" {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "team_id or team_name is required"))
+		return
+	}
+
+	ctx = withTeamRef(ctx, ref)
+
+	team, err := h.teamService.SetParent(ctx, ref, req.ParentName)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTeamNotFound):
+			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		case errors.Is(err, domain.ErrTeamCycle):
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", "parent_team is the team itself or one of its sub-teams"))
+		default:
+			logger.For(ctx, "handler").Error("Failed to set parent team", "error", err)
+			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"team": team,
+	})
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+		return
+	}
+}
+
+const invalidRoleMessage = "role must be one of lead, maintainer, member, trainee"
+
+func (h *TeamHandler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+	setMemberRole(w, r, h.teamService, service.SetMemberRoleOptions{})
+}
+
+// setMemberRole serves both /team/setRole and /admin/team/setRole; only the
+// admin endpoint passes opts.Force.
+func setMemberRole(w http.ResponseWriter, r *http.Request, teamService *service.TeamService, opts service.SetMemberRoleOptions) {
+	ctx := r.Context()
+
+	var req dto.SetMemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
+		return
+	}
+	ref := domain.TeamRef{ID: req.TeamID, Name: req.TeamName}
+	if (ref.ID == "" && ref.Name == "") || req.UserID == "" || req.Role == "" {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "team_id or team_name, user_id and role are required"))
+		return
+	}
+	role, err := domain.ParseTeamRole(req.Role)
+	if err != nil {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", invalidRoleMessage))
+		return
+	}
+
+	ctx = logger.With(withTeamRef(ctx, ref), "user_id", req.UserID)
+
+	team, err := teamService.SetMemberRole(ctx, ref, req.UserID, role, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrTeamNotFound):
+			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		case errors.Is(err, domain.ErrNotTeamMember):
+			writeError(w, domain.NewErrorResponse("NOT_TEAM_MEMBER", "user is not a member of the team"))
+		case errors.Is(err, domain.ErrNotTeamLead):
+			writeError(w, domain.NewErrorResponse("FORBIDDEN", "only the team lead can change roles"))
+		default:
+			logger.For(ctx, "handler").Error("Failed to set member role", "error", err)
+			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"team": team,
+	})
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+		return
+	}
+}
+
+// withTeamRef adds whichever of the team's ID and name the client sent to
+// the request logs.
+func withTeamRef(ctx context.Context, ref domain.TeamRef) context.Context {
+	if ref.ID != "" {
+		return logger.With(ctx, "team_id", ref.ID)
+	}
+	return logger.With(ctx, "team_name", ref.Name)
+}
+
 func writeError(w http.ResponseWriter, errResp domain.ErrorResponse) {
 	w.Header().Set("Content-Type", "application/json")
 
 	switch errResp.Error.Code {
 	case "TEAM_EXISTS":
 		w.WriteHeader(http.StatusBadRequest)
+	case "PR_MERGED", "PR_CLOSED", "TEAM_HAS_OPEN_PRS":
+		w.WriteHeader(http.StatusConflict)
 	case "NOT_FOUND":
 		w.WriteHeader(http.StatusNotFound)
-	case "INVALID_INPUT":
+	case "INVALID_INPUT", "TEAM_AMBIGUOUS", "NOT_TEAM_MEMBER":
 		w.WriteHeader(http.StatusBadRequest)
+	case "UNAUTHORIZED":
+		w.WriteHeader(http.StatusUnauthorized)
+	case "FORBIDDEN":
+		w.WriteHeader(http.StatusForbidden)
+	case "RATE_LIMITED":
+		w.WriteHeader(http.StatusTooManyRequests)
+	case "METHOD_NOT_ALLOWED":
+		w.WriteHeader(http.StatusMethodNotAllowed)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
 	"github.com/111zxc/pr-review-service/internal/handler/dto"
@@ -23,20 +25,24 @@ func NewUserHandler(userService *service.UserService, prService *service.PullReq
 }
 
 func (h *UserHandler) SetUserActive(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
 	var req dto.SetUserActiveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Error("Failed to decode request", "error", err)
+		logger.For(ctx, "handler").Error("Failed to decode request", "error", err)
 		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "Invalid request body"))
 		return
 	}
 
-	user, err := h.userService.SetUserActive(req.UserID, req.IsActive)
+	ctx = logger.With(ctx, "user_id", req.UserID)
+
+	user, err := h.userService.SetUserActive(ctx, req.UserID, req.IsActive)
 	if err != nil {
-		switch err {
-		case domain.ErrUserNotFound:
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
 			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
 		default:
-			logger.Error("Failed to set user active", "error", err)
+			logger.For(ctx, "handler").Error("Failed to set user active", "error", err)
 			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
 		}
 		return
@@ -44,15 +50,10 @@ func (h *UserHandler) SetUserActive(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(map[string]interface{}{
-		"user": dto.UserResponse{
-			UserID:   user.ID,
-			Username: user.Username,
-			TeamName: user.TeamName,
-			IsActive: user.IsActive,
-		},
+		"user": newUserResponse(user),
 	})
 	if err != nil {
-		logger.Error("failed to write JSON response", "error", err)
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
 		return
 	}
 }
@@ -64,9 +65,11 @@ func (h *UserHandler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prs, err := h.prService.GetUserReviews(userID)
+	ctx := logger.With(r.Context(), "user_id", userID)
+
+	prs, err := h.prService.GetUserReviews(ctx, userID)
 	if err != nil {
-		logger.Error("Failed to get user reviews", "error", err)
+		logger.For(ctx, "handler").Error("Failed to get user reviews", "error", err)
 		writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
 		return
 	}
@@ -87,7 +90,134 @@ func (h *UserHandler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
 		PullRequests: shortPRs,
 	})
 	if err != nil {
-		logger.Error("failed to write JSON response", "error", err)
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+		return
+	}
+}
+
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 100
+)
+
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		writeError(w, domain.NewErrorResponse("INVALID_INPUT", "user_id is required"))
+		return
+	}
+
+	ctx := logger.With(r.Context(), "user_id", userID)
+
+	user, err := h.userService.GetUserDetails(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
+			writeError(w, domain.NewErrorResponse("NOT_FOUND", "resource not found"))
+		default:
+			logger.For(ctx, "handler").Error("Failed to get user", "error", err)
+			writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"user": newUserDetailsResponse(*user),
+	})
+	if err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
+		return
+	}
+}
+
+// ListUsers lists users sorted by ID, optionally filtered by team_name,
+// is_active and username_prefix, a page of limit users from offset.
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	filter := domain.UserFilter{
+		Team:           query.Get("team_name"),
+		UsernamePrefix: query.Get("username_prefix"),
+		Limit:          defaultUserPageSize,
+	}
+	if v := query.Get("is_active"); v != "" {
+		isActive, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", "is_active must be true or false"))
+			return
+		}
+		filter.IsActive = &isActive
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxUserPageSize {
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", "limit must be between 1 and "+strconv.Itoa(maxUserPageSize)))
+			return
+		}
+		filter.Limit = limit
+	}
+	if v := query.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			writeError(w, domain.NewErrorResponse("INVALID_INPUT", "offset must be a non-negative number"))
+			return
+		}
+		filter.Offset = offset
+	}
+
+	users, total, err := h.userService.ListUserDetails(ctx, filter)
+	if err != nil {
+		logger.For(ctx, "handler").Error("Failed to list users", "error", err)
+		writeError(w, domain.NewErrorResponse("INTERNAL_ERROR", "Internal server error"))
+		return
+	}
+
+	resp := dto.UserListResponse{
+		Users:  make([]dto.UserDetailsResponse, 0, len(users)),
+		Total:  total,
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, newUserDetailsResponse(user))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.For(ctx, "handler").Error("failed to write JSON response", "error", err)
 		return
 	}
 }
+
+func newUserResponse(user *domain.User) dto.UserResponse {
+	resp := dto.UserResponse{
+		UserID:   user.ID,
+		Username: user.Username,
+		Teams:    user.Teams,
+		IsActive: user.IsActive,
+	}
+	if len(user.Teams) == 1 {
+		resp.TeamName = user.Teams[0]
+	}
+	if resp.Teams == nil {
+		resp.Teams = []string{}
+	}
+	return resp
+}
+
+func newUserDetailsResponse(user domain.UserDetails) dto.UserDetailsResponse {
+	resp := dto.UserDetailsResponse{
+		UserResponse: newUserResponse(user.User),
+		Memberships:  make([]dto.UserMembershipResponse, 0, len(user.Teams)),
+		OpenReviews:  user.OpenReviews,
+	}
+	for _, team := range user.Teams {
+		resp.Memberships = append(resp.Memberships, dto.UserMembershipResponse{
+			TeamName: team,
+			Role:     string(user.RoleFor(team)),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ComponentKey is the attribute that selects per-component levels.
+const ComponentKey = "component"
+
+// levels holds the default level and per-component overrides. Overrides
+// apply to the component and everything nested under it, so "repository"
+// also covers "repository/postgres"; the longest match wins.
+type levels struct {
+	defaultLevel slog.LevelVar
+	overrides    atomic.Pointer[map[string]slog.Level]
+
+	mu          sync.Mutex
+	beforeDebug *slog.Level
+}
+
+var currentLevels = newLevels()
+
+func newLevels() *levels {
+	l := &levels{}
+	l.overrides.Store(&map[string]slog.Level{})
+	return l
+}
+
+func (l *levels) forComponent(component string) slog.Level {
+	overrides := *l.overrides.Load()
+	for c := component; c != ""; {
+		if lvl, ok := overrides[c]; ok {
+			return lvl
+		}
+		i := strings.LastIndexByte(c, '/')
+		if i < 0 {
+			break
+		}
+		c = c[:i]
+	}
+	return l.defaultLevel.Level()
+}
+
+// ParseLevel accepts debug, info, warn and error.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// SetLevel changes the default level of the global logger and every logger
+// derived from it, including request-scoped ones.
+func SetLevel(name string) error {
+	lvl, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+
+	currentLevels.mu.Lock()
+	defer currentLevels.mu.Unlock()
+
+	currentLevels.defaultLevel.Set(lvl)
+	currentLevels.beforeDebug = nil
+	return nil
+}
+
+// Level returns the default level.
+func Level() string {
+	return levelName(currentLevels.defaultLevel.Level())
+}
+
+// SetComponentLevels replaces all per-component overrides.
+func SetComponentLevels(components map[string]string) error {
+	overrides := make(map[string]slog.Level, len(components))
+	for component, name := range components {
+		lvl, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("component %s: %w", component, err)
+		}
+		overrides[component] = lvl
+	}
+
+	currentLevels.overrides.Store(&overrides)
+	return nil
+}
+
+// SetComponentLevel overrides the level of one component; an empty level
+// removes the override.
+func SetComponentLevel(component, name string) error {
+	currentLevels.mu.Lock()
+	defer currentLevels.mu.Unlock()
+
+	overrides := maps.Clone(*currentLevels.overrides.Load())
+	if name == "" {
+		delete(overrides, component)
+	} else {
+		lvl, err := ParseLevel(name)
+		if err != nil {
+			return err
+		}
+		overrides[component] = lvl
+	}
+
+	currentLevels.overrides.Store(&overrides)
+	return nil
+}
+
+// ComponentLevels returns the per-component overrides.
+func ComponentLevels() map[string]string {
+	overrides := *currentLevels.overrides.Load()
+	out := make(map[string]string, len(overrides))
+	for component, lvl := range overrides {
+		out[component] = levelName(lvl)
+	}
+	return out
+}
+
+// ToggleDebug switches the default level to debug, or back to the level it
+// had before the previous toggle. It returns the new level.
+func ToggleDebug() string {
+	currentLevels.mu.Lock()
+	defer currentLevels.mu.Unlock()
+
+	if prev := currentLevels.beforeDebug; prev != nil {
+		currentLevels.defaultLevel.Set(*prev)
+		currentLevels.beforeDebug = nil
+	} else {
+		prev := currentLevels.defaultLevel.Level()
+		currentLevels.beforeDebug = &prev
+		currentLevels.defaultLevel.Set(slog.LevelDebug)
+	}
+	return levelName(currentLevels.defaultLevel.Level())
+}
+
+// For returns the context logger tagged with a component, so that
+// per-component levels apply to it.
+func For(ctx context.Context, component string) *slog.Logger {
+	return FromContext(ctx).With(ComponentKey, component)
+}
+
+func levelName(lvl slog.Level) string {
+	return strings.ToLower(lvl.String())
+}
+
+// componentHandler filters records by the level of the component the logger
+// was tagged with. The wrapped handler must accept every level.
+type componentHandler struct {
+	next      slog.Handler
+	levels    *levels
+	component string
+}
+
+func (h *componentHandler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= h.levels.forComponent(h.component)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	component := h.component
+	for _, a := range attrs {
+		if a.Key == ComponentKey {
+			component = a.Value.String()
+		}
+	}
+	return &componentHandler{next: h.next.WithAttrs(attrs), levels: h.levels, component: component}
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return &componentHandler{next: h.next.WithGroup(name), levels: h.levels, component: h.component}
+}
@@ -1,43 +1,68 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"os"
 )
 
-var globalLogger *slog.Logger
+var (
+	globalLogger *slog.Logger
+	logFile      *rotatingFile
+)
 
 type Config struct {
 	Level  string
 	Format string
+	// Components overrides the level per component, e.g.
+	// {"repository/postgres": "debug"}.
+	Components map[string]string
+
+	// File, when set, receives a copy of the output and is rotated once it
+	// reaches FileMaxSizeMB, keeping FileMaxBackups old files.
+	File           string
+	FileMaxSizeMB  int
+	FileMaxBackups int
 }
 
 func Init(cfg Config) error {
-	var level slog.Level
-	switch cfg.Level {
-	case "debug":
-		level = slog.LevelDebug
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
+	if err := SetLevel(cfg.Level); err != nil {
+		return err
+	}
+	if err := SetComponentLevels(cfg.Components); err != nil {
+		return err
 	}
 
-	var handler slog.Handler
+	var out io.Writer = os.Stdout
+	if cfg.File != "" {
+		f, err := openRotatingFile(cfg.File, int64(cfg.FileMaxSizeMB)<<20, cfg.FileMaxBackups)
+		if err != nil {
+			return err
+		}
+		if logFile != nil {
+			logFile.Close() //nolint:errcheck
+		}
+		logFile = f
+		out = io.MultiWriter(os.Stdout, f)
+	}
+
+	// Filtering happens in componentHandler, so the output handler accepts
+	// every level.
 	opts := &slog.HandlerOptions{
-		Level: level,
+		Level: slog.Level(math.MinInt),
 	}
 
+	var handler slog.Handler
 	if cfg.Format == "json" {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(out, opts)
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		handler = slog.NewTextHandler(out, opts)
 	}
 
-	globalLogger = slog.New(handler)
+	globalLogger = slog.New(&componentHandler{next: handler, levels: currentLevels})
 	return nil
 }
 
@@ -67,6 +92,27 @@ func Get() *slog.Logger {
 	return globalLogger
 }
 
+type ctxKey struct{}
+
+// WithContext stores a request-scoped logger in ctx.
+func WithContext(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger stored in ctx, falling back to the global one.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return Get()
+}
+
+// With returns a context whose logger carries args on every record, e.g.
+// the IDs of the entities a request operates on.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithContext(ctx, FromContext(ctx).With(args...))
+}
+
 func Info(msg string, args ...interface{}) {
 	Get().Info(msg, args...)
 }
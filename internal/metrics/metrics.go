@@ -0,0 +1,61 @@
+package metrics
+
+var (
+	ThrottledRequests = NewCounterVec(
+		"http_requests_throttled_total",
+		"Requests rejected with 429 by the rate or concurrency limiter.",
+		"reason",
+	)
+
+	HTTPRequestDuration = NewHistogramVec(
+		"http_request_duration_seconds",
+		"HTTP request latency by route, method and status.",
+		DefaultBuckets,
+		"route", "method", "status",
+	)
+
+	PullRequestsCreated = NewCounterVec(
+		"pull_requests_created_total",
+		"Pull requests created.",
+	)
+
+	PullRequestsMerged = NewCounterVec(
+		"pull_requests_merged_total",
+		"Pull requests merged.",
+	)
+
+	ReviewersReassigned = NewCounterVec(
+		"reviewers_reassigned_total",
+		"Reviewers replaced on open pull requests.",
+	)
+
+	NoCandidateFailures = NewCounterVec(
+		"reviewer_no_candidate_total",
+		"Reassignments that failed with NO_CANDIDATE.",
+	)
+
+	CacheLookups = NewCounterVec(
+		"repository_cache_lookups_total",
+		"Repository cache lookups by cache and result (hit or miss).",
+		"cache", "result",
+	)
+
+	CacheInvalidations = NewCounterVec(
+		"repository_cache_invalidations_total",
+		"Repository cache invalidations by origin (local or remote).",
+		"origin",
+	)
+
+	RepositoryOperationDuration = NewHistogramVec(
+		"repository_operation_duration_seconds",
+		"Repository call latency by operation.",
+		DefaultBuckets,
+		"operation",
+	)
+
+	RepositoryOperationErrors = NewCounterVec(
+		"repository_operation_errors_total",
+		"Repository calls that returned an error, by operation and kind (domain or internal).",
+		"operation", "kind",
+	)
+)
@@ -0,0 +1,381 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/111zxc/pr-review-service/internal/logger"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type collector interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+var defaultRegistry = &Registry{}
+
+func (r *Registry) register(c collector, replace bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.collectors {
+		if existing.name() == c.name() {
+			if !replace {
+				panic("metrics: duplicate metric " + c.name())
+			}
+			r.collectors[i] = c
+			return
+		}
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	collectors := make([]collector, len(r.collectors))
+	copy(collectors, r.collectors)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		if err := defaultRegistry.Write(w); err != nil {
+			logger.Error("failed to write metrics", "error", err)
+		}
+	})
+}
+
+type CounterVec struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		values:     make(map[string]float64),
+	}
+	if len(labels) == 0 {
+		c.values[""] = 0
+	}
+	defaultRegistry.register(c, false)
+	return c
+}
+
+func (c *CounterVec) name() string {
+	return c.metricName
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	checkLabels(c.metricName, c.labels, labelValues)
+	key := labelKey(labelValues)
+
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.values[labelKey(labelValues)]
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, c.metricName, c.help, "counter")
+	for _, key := range sortedKeys(c.values) {
+		writeSample(w, c.metricName, c.labels, splitKey(key), "", "", c.values[key])
+	}
+}
+
+type GaugeVec struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		values:     make(map[string]float64),
+	}
+	defaultRegistry.register(g, false)
+	return g
+}
+
+func (g *GaugeVec) name() string {
+	return g.metricName
+}
+
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	checkLabels(g.metricName, g.labels, labelValues)
+
+	g.mu.Lock()
+	g.values[labelKey(labelValues)] = v
+	g.mu.Unlock()
+}
+
+func (g *GaugeVec) Add(v float64, labelValues ...string) {
+	checkLabels(g.metricName, g.labels, labelValues)
+
+	g.mu.Lock()
+	g.values[labelKey(labelValues)] += v
+	g.mu.Unlock()
+}
+
+func (g *GaugeVec) Value(labelValues ...string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.values[labelKey(labelValues)]
+}
+
+func (g *GaugeVec) write(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	writeHeader(w, g.metricName, g.help, "gauge")
+	for _, key := range sortedKeys(g.values) {
+		writeSample(w, g.metricName, g.labels, splitKey(key), "", "", g.values[key])
+	}
+}
+
+type histogramSeries struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type HistogramVec struct {
+	metricName string
+	help       string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		metricName: name,
+		help:       help,
+		labels:     labels,
+		buckets:    buckets,
+		series:     make(map[string]*histogramSeries),
+	}
+	defaultRegistry.register(h, false)
+	return h
+}
+
+func (h *HistogramVec) name() string {
+	return h.metricName
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	checkLabels(h.metricName, h.labels, labelValues)
+	key := labelKey(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+
+	for i, upper := range h.buckets {
+		if v <= upper {
+			s.counts[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if s, ok := h.series[labelKey(labelValues)]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.metricName, h.help, "histogram")
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		values := splitKey(key)
+		for i, upper := range h.buckets {
+			writeSample(w, h.metricName+"_bucket", h.labels, values, "le", formatFloat(upper), float64(s.counts[i]))
+		}
+		writeSample(w, h.metricName+"_bucket", h.labels, values, "le", "+Inf", float64(s.count))
+		writeSample(w, h.metricName+"_sum", h.labels, values, "", "", s.sum)
+		writeSample(w, h.metricName+"_count", h.labels, values, "", "", float64(s.count))
+	}
+}
+
+// FuncCollector produces its samples at scrape time, for values that are
+// owned by something else, such as the DB pool or the database itself.
+type FuncCollector struct {
+	metricName string
+	help       string
+	metricType string
+	labels     []string
+	collect    func(emit func(v float64, labelValues ...string))
+}
+
+// RegisterGaugeFunc registers a scrape-time gauge. Registering the same name
+// again replaces the previous collector.
+func RegisterGaugeFunc(name, help string, labels []string, collect func(emit func(v float64, labelValues ...string))) {
+	defaultRegistry.register(&FuncCollector{
+		metricName: name, help: help, metricType: "gauge", labels: labels, collect: collect,
+	}, true)
+}
+
+// RegisterCounterFunc registers a scrape-time counter. Registering the same
+// name again replaces the previous collector.
+func RegisterCounterFunc(name, help string, labels []string, collect func(emit func(v float64, labelValues ...string))) {
+	defaultRegistry.register(&FuncCollector{
+		metricName: name, help: help, metricType: "counter", labels: labels, collect: collect,
+	}, true)
+}
+
+func (f *FuncCollector) name() string {
+	return f.metricName
+}
+
+func (f *FuncCollector) write(w *bufio.Writer) {
+	writeHeader(w, f.metricName, f.help, f.metricType)
+	f.collect(func(v float64, labelValues ...string) {
+		if len(labelValues) != len(f.labels) {
+			logger.Error("metrics: wrong number of label values", "metric", f.metricName)
+			return
+		}
+		writeSample(w, f.metricName, f.labels, labelValues, "", "", v)
+	})
+}
+
+func writeHeader(w *bufio.Writer, name, help, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, metricType)
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraLabel, extraValue string, v float64) {
+	w.WriteString(name) //nolint:errcheck
+
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{') //nolint:errcheck
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',') //nolint:errcheck
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", label, escapeLabelValue(values[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',') //nolint:errcheck
+			}
+			fmt.Fprintf(w, "%s=\"%s\"", extraLabel, extraValue)
+		}
+		w.WriteByte('}') //nolint:errcheck
+	}
+
+	w.WriteByte(' ')              //nolint:errcheck
+	w.WriteString(formatFloat(v)) //nolint:errcheck
+	w.WriteByte('\n')             //nolint:errcheck
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func checkLabels(name string, labels, values []string) {
+	if len(values) != len(labels) {
+		panic("metrics: wrong number of label values for " + name)
+	}
+}
+
+func labelKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func splitKey(key string) []string {
+	return strings.Split(key, "\xff")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
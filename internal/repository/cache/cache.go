@@ -0,0 +1,187 @@
+// Package cache provides read-through caching decorators for the user and
+// team repositories. Writes through a decorator invalidate the affected
+// entries locally and, through a Publisher, on every other instance.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/metrics"
+	"github.com/111zxc/pr-review-service/internal/repository"
+)
+
+// Publisher broadcasts invalidations to other instances.
+type Publisher interface {
+	Publish(ctx context.Context, payload string) error
+}
+
+type Config struct {
+	UserTTL time.Duration
+	TeamTTL time.Duration
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache is the state shared by the user and team decorators; a change to a
+// user also invalidates the cached teams listing that user and vice versa.
+type Cache struct {
+	cfg       Config
+	publisher Publisher
+	origin    string
+	now       func() time.Time
+
+	mu         sync.Mutex
+	generation uint64
+	users      map[string]entry[domain.User]
+	teamUsers  map[string]entry[[]domain.User]
+	teams      map[string]entry[domain.Team]
+}
+
+// New returns an empty cache. publisher may be nil on single-instance
+// backends. origin identifies this instance so it can skip its own
+// broadcasts.
+func New(cfg Config, publisher Publisher, origin string) *Cache {
+	return &Cache{
+		cfg:       cfg,
+		publisher: publisher,
+		origin:    origin,
+		now:       time.Now,
+		users:     make(map[string]entry[domain.User]),
+		teamUsers: make(map[string]entry[[]domain.User]),
+		teams:     make(map[string]entry[domain.Team]),
+	}
+}
+
+type invalidation struct {
+	Origin string `json:"origin"`
+	User   string `json:"user,omitempty"`
+	Team   string `json:"team,omitempty"`
+}
+
+// HandleRemote applies an invalidation received from another instance.
+// Unreadable payloads purge everything rather than risk stale entries.
+func (c *Cache) HandleRemote(payload string) {
+	var inv invalidation
+	if err := json.Unmarshal([]byte(payload), &inv); err != nil {
+		logger.For(context.Background(), "repository/cache").Warn("Malformed cache invalidation, purging", "error", err)
+		c.Purge()
+		return
+	}
+	if inv.Origin == c.origin {
+		return
+	}
+
+	metrics.CacheInvalidations.Inc("remote")
+	c.apply(inv)
+}
+
+// Purge drops every entry, e.g. after notifications may have been missed.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	clear(c.users)
+	clear(c.teamUsers)
+	clear(c.teams)
+}
+
+// invalidate drops the entries affected by a write and tells the other
+// instances. A write inside a transaction is only acted on once it commits.
+func (c *Cache) invalidate(ctx context.Context, inv invalidation) {
+	repository.AfterCommit(ctx, func() { c.invalidateNow(ctx, inv) })
+}
+
+func (c *Cache) invalidateNow(ctx context.Context, inv invalidation) {
+	metrics.CacheInvalidations.Inc("local")
+	c.apply(inv)
+
+	if c.publisher == nil {
+		return
+	}
+	inv.Origin = c.origin
+	payload, _ := json.Marshal(inv)
+	if err := c.publisher.Publish(ctx, string(payload)); err != nil {
+		// Other instances fall back to the TTL for this change.
+		logger.For(ctx, "repository/cache").Error("Failed to publish cache invalidation", "error", err)
+	}
+}
+
+func (c *Cache) apply(inv invalidation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	if inv.User != "" {
+		delete(c.users, inv.User)
+		for name, e := range c.teams {
+			for _, m := range e.value.Members {
+				if m.UserID == inv.User {
+					delete(c.teams, name)
+					break
+				}
+			}
+		}
+		// A user may just have become active, so listings that don't
+		// contain them can be stale too.
+		clear(c.teamUsers)
+	}
+
+	if inv.Team != "" {
+		delete(c.teams, inv.Team)
+		delete(c.teamUsers, inv.Team)
+		// Sub-teams carry the team's name as their parent.
+		for name, e := range c.teams {
+			if e.value.ParentName == inv.Team {
+				delete(c.teams, name)
+			}
+		}
+		// Members' Teams may have changed.
+		clear(c.users)
+	}
+}
+
+func (c *Cache) snapshot() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// lookup returns a live entry and records the hit or miss.
+func lookup[V any](c *Cache, name string, m map[string]entry[V], key string) (V, bool) {
+	c.mu.Lock()
+	e, ok := m[key]
+	if ok && !c.now().Before(e.expiresAt) {
+		delete(m, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if ok {
+		metrics.CacheLookups.Inc(name, "hit")
+	} else {
+		metrics.CacheLookups.Inc(name, "miss")
+	}
+	return e.value, ok
+}
+
+// store saves a loaded value unless an invalidation happened since the load
+// started at generation gen, in which case the value may already be stale.
+func store[V any](c *Cache, m map[string]entry[V], key string, value V, ttl time.Duration, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != gen {
+		return
+	}
+	m[key] = entry[V]{value: value, expiresAt: c.now().Add(ttl)}
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"slices"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/repository"
+)
+
+// TeamRepository caches GetByName; Exists is answered from the same entry.
+// GetByID, ListSubTeams and List are not cached since entries are keyed and
+// invalidated by name.
+type TeamRepository struct {
+	next  repository.TeamRepository
+	cache *Cache
+}
+
+func NewTeamRepository(next repository.TeamRepository, cache *Cache) *TeamRepository {
+	return &TeamRepository{next: next, cache: cache}
+}
+
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	if err := r.next.Create(ctx, team); err != nil {
+		return err
+	}
+	r.cache.invalidate(ctx, invalidation{Team: team.Name})
+	return nil
+}
+
+func (r *TeamRepository) AddMembers(ctx context.Context, teamName string, userIDs []string) error {
+	if err := r.next.AddMembers(ctx, teamName, userIDs); err != nil {
+		return err
+	}
+	r.cache.invalidate(ctx, invalidation{Team: teamName})
+	return nil
+}
+
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamName, userID string) error {
+	if err := r.next.RemoveMember(ctx, teamName, userID); err != nil {
+		return err
+	}
+	r.cache.invalidate(ctx, invalidation{Team: teamName})
+	return nil
+}
+
+func (r *TeamRepository) SetRole(ctx context.Context, teamName, userID string, role domain.TeamRole) error {
+	if err := r.next.SetRole(ctx, teamName, userID, role); err != nil {
+		return err
+	}
+	// The user's roles are also listed under their other teams.
+	r.cache.invalidate(ctx, invalidation{Team: teamName, User: userID})
+	return nil
+}
+
+func (r *TeamRepository) Delete(ctx context.Context, name string) error {
+	if err := r.next.Delete(ctx, name); err != nil {
+		return err
+	}
+	r.cache.invalidate(ctx, invalidation{Team: name})
+	return nil
+}
+
+func (r *TeamRepository) Restore(ctx context.Context, name string) error {
+	if err := r.next.Restore(ctx, name); err != nil {
+		return err
+	}
+	r.cache.invalidate(ctx, invalidation{Team: name})
+	// Sub-teams were cached without a parent while the team was deleted.
+	children, err := r.next.ListSubTeams(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		r.cache.invalidate(ctx, invalidation{Team: child})
+	}
+	return nil
+}
+
+func (r *TeamRepository) Rename(ctx context.Context, name, newName string) error {
+	if err := r.next.Rename(ctx, name, newName); err != nil {
+		return err
+	}
+	// Nothing is cached under newName yet: misses are never stored.
+	r.cache.invalidate(ctx, invalidation{Team: name})
+	return nil
+}
+
+func (r *TeamRepository) SetParent(ctx context.Context, name, parentName string) error {
+	if err := r.next.SetParent(ctx, name, parentName); err != nil {
+		return err
+	}
+	r.cache.invalidate(ctx, invalidation{Team: name})
+	return nil
+}
+
+func (r *TeamRepository) ListSubTeams(ctx context.Context, name string) ([]string, error) {
+	return r.next.ListSubTeams(ctx, name)
+}
+
+func (r *TeamRepository) List(ctx context.Context) ([]*domain.Team, error) {
+	return r.next.List(ctx)
+}
+
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	return r.next.GetByID(ctx, id)
+}
+
+func (r *TeamRepository) GetByName(ctx context.Context, name string) (*domain.Team, error) {
+	if repository.InTx(ctx) {
+		return r.next.GetByName(ctx, name)
+	}
+	if team, ok := lookup(r.cache, "team", r.cache.teams, name); ok {
+		team.Members = slices.Clone(team.Members)
+		return &team, nil
+	}
+
+	gen := r.cache.snapshot()
+	team, err := r.next.GetByName(repository.WithPrimary(ctx), name)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := *team
+	cached.Members = slices.Clone(team.Members)
+	store(r.cache, r.cache.teams, name, cached, r.cache.cfg.TeamTTL, gen)
+	return team, nil
+}
+
+// Exists only uses positive cache entries; a missing team is always checked
+// against the database so a freshly created team is seen at once.
+func (r *TeamRepository) Exists(ctx context.Context, name string) (bool, error) {
+	if repository.InTx(ctx) {
+		return r.next.Exists(ctx, name)
+	}
+	if _, ok := lookup(r.cache, "team", r.cache.teams, name); ok {
+		return true, nil
+	}
+	return r.next.Exists(ctx, name)
+}
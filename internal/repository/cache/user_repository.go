@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/repository"
+)
+
+// UserRepository caches GetByID and GetByTeam; List is not cached.
+type UserRepository struct {
+	next  repository.UserRepository
+	cache *Cache
+}
+
+func NewUserRepository(next repository.UserRepository, cache *Cache) *UserRepository {
+	return &UserRepository{next: next, cache: cache}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	if err := r.next.Create(ctx, user); err != nil {
+		return err
+	}
+	r.cache.invalidate(ctx, invalidation{User: user.ID})
+	return nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	if err := r.next.Update(ctx, user); err != nil {
+		return err
+	}
+	r.cache.invalidate(ctx, invalidation{User: user.ID})
+	return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	// Reads in a transaction may see its uncommitted writes; keep them out.
+	if repository.InTx(ctx) {
+		return r.next.GetByID(ctx, id)
+	}
+	if user, ok := lookup(r.cache, "user", r.cache.users, id); ok {
+		return &user, nil
+	}
+
+	// Fill from the primary: a lagging replica could put a value back that
+	// a write has just invalidated.
+	gen := r.cache.snapshot()
+	user, err := r.next.GetByID(repository.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	store(r.cache, r.cache.users, id, *user, r.cache.cfg.UserTTL, gen)
+	return user, nil
+}
+
+func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]*domain.User, error) {
+	if repository.InTx(ctx) {
+		return r.next.GetByTeam(ctx, teamName)
+	}
+	if users, ok := lookup(r.cache, "team_users", r.cache.teamUsers, teamName); ok {
+		return userPointers(users), nil
+	}
+
+	gen := r.cache.snapshot()
+	users, err := r.next.GetByTeam(repository.WithPrimary(ctx), teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := make([]domain.User, len(users))
+	for i, u := range users {
+		cached[i] = *u
+	}
+	store(r.cache, r.cache.teamUsers, teamName, cached, r.cache.cfg.TeamTTL, gen)
+	return users, nil
+}
+
+// userPointers hands out copies so callers can't modify cached entries.
+func userPointers(users []domain.User) []*domain.User {
+	if users == nil {
+		return nil
+	}
+	out := make([]*domain.User, len(users))
+	for i := range users {
+		u := users[i]
+		out[i] = &u
+	}
+	return out
+}
+
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int, error) {
+	return r.next.List(ctx, filter)
+}
+
+// CountOpenReviews is not cached; review assignments change with every
+// pull request.
+func (r *UserRepository) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	return r.next.CountOpenReviews(ctx, userIDs)
+}
+
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	if err := r.next.Delete(ctx, userID); err != nil {
+		return err
+	}
+	r.cache.invalidate(ctx, invalidation{User: userID})
+	return nil
+}
+
+func (r *UserRepository) Erase(ctx context.Context, userID, pseudonym string) (domain.ErasedRecords, error) {
+	erased, err := r.next.Erase(ctx, userID, pseudonym)
+	if err != nil {
+		return erased, err
+	}
+	r.cache.invalidate(ctx, invalidation{User: userID})
+	return erased, nil
+}
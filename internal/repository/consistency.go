@@ -0,0 +1,18 @@
+package repository
+
+import "context"
+
+type primaryKey struct{}
+
+// WithPrimary marks ctx as needing read-your-writes consistency. Backends
+// with read replicas serve every query made with such a context from the
+// primary; others ignore the mark.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryRequired reports whether ctx was marked with WithPrimary.
+func PrimaryRequired(ctx context.Context) bool {
+	required, _ := ctx.Value(primaryKey{}).(bool)
+	return required
+}
@@ -0,0 +1,41 @@
+// Package decorator wraps every repository interface so that a single
+// Middleware sees each call by operation name, e.g. "Events.CreateEvent".
+// Instrumentation and fault injection are both middlewares.
+package decorator
+
+import "context"
+
+// Middleware runs around one repository call. op is "<Repository>.<Method>"
+// using the field names of app.Repositories. It must call next at most once
+// and return its error, or return an error of its own instead of calling it.
+type Middleware func(ctx context.Context, op string, next func(context.Context) error) error
+
+// Chain returns a middleware that runs mws in order, the first outermost.
+func Chain(mws ...Middleware) Middleware {
+	return func(ctx context.Context, op string, next func(context.Context) error) error {
+		call := next
+		for i := len(mws) - 1; i >= 0; i-- {
+			mw, inner := mws[i], call
+			call = func(ctx context.Context) error { return mw(ctx, op, inner) }
+		}
+		return call(ctx)
+	}
+}
+
+func exec(ctx context.Context, mw Middleware, op string, fn func(context.Context) error) error {
+	return mw(ctx, op, fn)
+}
+
+func query[T any](ctx context.Context, mw Middleware, op string, fn func(context.Context) (T, error)) (T, error) {
+	var result T
+	err := mw(ctx, op, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
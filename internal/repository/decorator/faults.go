@@ -0,0 +1,88 @@
+package decorator
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"path"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is returned by calls failed by Faults.
+var ErrInjectedFault = errors.New("injected repository fault")
+
+// FaultRule delays and/or fails the calls whose operation matches.
+type FaultRule struct {
+	// Operation is matched with path.Match, so "Events.*" covers every
+	// method of the events repository and "*" every call.
+	Operation string
+	// ErrorRate is the probability, in [0, 1], that a call fails.
+	ErrorRate float64
+	// Err is returned for failed calls; ErrInjectedFault when nil.
+	Err error
+	// Delay is added before every matching call, failed or not.
+	Delay time.Duration
+}
+
+// Faults injects failures and delays for resilience tests. Rules can be
+// replaced while calls are in flight.
+type Faults struct {
+	mu    sync.Mutex
+	rules []FaultRule
+	rng   *rand.Rand
+}
+
+// NewFaults returns an injector without rules. A fixed seed makes the
+// sequence of failures reproducible.
+func NewFaults(seed int64) *Faults {
+	return &Faults{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Set replaces the active rules; call it without arguments to stop
+// injecting.
+func (f *Faults) Set(rules ...FaultRule) {
+	f.mu.Lock()
+	f.rules = append([]FaultRule(nil), rules...)
+	f.mu.Unlock()
+}
+
+// Middleware applies the first rule matching each call.
+func (f *Faults) Middleware() Middleware {
+	return func(ctx context.Context, op string, next func(context.Context) error) error {
+		delay, err := f.decide(op)
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return next(ctx)
+	}
+}
+
+func (f *Faults) decide(op string) (time.Duration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, rule := range f.rules {
+		if ok, _ := path.Match(rule.Operation, op); !ok {
+			continue
+		}
+		if rule.ErrorRate > 0 && f.rng.Float64() < rule.ErrorRate {
+			if rule.Err != nil {
+				return rule.Delay, rule.Err
+			}
+			return rule.Delay, ErrInjectedFault
+		}
+		return rule.Delay, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,38 @@
+package decorator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/metrics"
+	"github.com/111zxc/pr-review-service/internal/tracing"
+)
+
+// Instrument records the latency of every call in
+// repository_operation_duration_seconds and its errors in
+// repository_operation_errors_total, and wraps the call in a span.
+func Instrument() Middleware {
+	return func(ctx context.Context, op string, next func(context.Context) error) (err error) {
+		ctx, span := tracing.Tracer().Start(ctx, "Repository."+op)
+		defer func() { tracing.End(span, err) }()
+
+		start := time.Now()
+		err = next(ctx)
+		metrics.RepositoryOperationDuration.Observe(time.Since(start).Seconds(), op)
+		if err != nil {
+			metrics.RepositoryOperationErrors.Inc(op, errorKind(err))
+		}
+		return err
+	}
+}
+
+// errorKind tells outcomes the repositories report on purpose apart from
+// failures of the backend itself.
+func errorKind(err error) string {
+	if errors.Is(err, domain.ErrDomain) {
+		return "domain"
+	}
+	return "internal"
+}
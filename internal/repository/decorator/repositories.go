@@ -0,0 +1,278 @@
+package decorator
+
+import (
+	"context"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/repository"
+)
+
+type UserRepository struct {
+	next repository.UserRepository
+	mw   Middleware
+}
+
+func NewUserRepository(next repository.UserRepository, mw Middleware) *UserRepository {
+	return &UserRepository{next: next, mw: mw}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	return exec(ctx, r.mw, "User.Create", func(ctx context.Context) error {
+		return r.next.Create(ctx, user)
+	})
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	return query(ctx, r.mw, "User.GetByID", func(ctx context.Context) (*domain.User, error) {
+		return r.next.GetByID(ctx, id)
+	})
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	return exec(ctx, r.mw, "User.Update", func(ctx context.Context) error {
+		return r.next.Update(ctx, user)
+	})
+}
+
+func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]*domain.User, error) {
+	return query(ctx, r.mw, "User.GetByTeam", func(ctx context.Context) ([]*domain.User, error) {
+		return r.next.GetByTeam(ctx, teamName)
+	})
+}
+
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) (users []*domain.User, total int, err error) {
+	err = r.mw(ctx, "User.List", func(ctx context.Context) error {
+		var err error
+		users, total, err = r.next.List(ctx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *UserRepository) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	return query(ctx, r.mw, "User.CountOpenReviews", func(ctx context.Context) (map[string]int, error) {
+		return r.next.CountOpenReviews(ctx, userIDs)
+	})
+}
+
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	return exec(ctx, r.mw, "User.Delete", func(ctx context.Context) error {
+		return r.next.Delete(ctx, userID)
+	})
+}
+
+func (r *UserRepository) Erase(ctx context.Context, userID, pseudonym string) (domain.ErasedRecords, error) {
+	return query(ctx, r.mw, "User.Erase", func(ctx context.Context) (domain.ErasedRecords, error) {
+		return r.next.Erase(ctx, userID, pseudonym)
+	})
+}
+
+type TeamRepository struct {
+	next repository.TeamRepository
+	mw   Middleware
+}
+
+func NewTeamRepository(next repository.TeamRepository, mw Middleware) *TeamRepository {
+	return &TeamRepository{next: next, mw: mw}
+}
+
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	return exec(ctx, r.mw, "Team.Create", func(ctx context.Context) error {
+		return r.next.Create(ctx, team)
+	})
+}
+
+func (r *TeamRepository) AddMembers(ctx context.Context, teamName string, userIDs []string) error {
+	return exec(ctx, r.mw, "Team.AddMembers", func(ctx context.Context) error {
+		return r.next.AddMembers(ctx, teamName, userIDs)
+	})
+}
+
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamName, userID string) error {
+	return exec(ctx, r.mw, "Team.RemoveMember", func(ctx context.Context) error {
+		return r.next.RemoveMember(ctx, teamName, userID)
+	})
+}
+
+func (r *TeamRepository) SetRole(ctx context.Context, teamName, userID string, role domain.TeamRole) error {
+	return exec(ctx, r.mw, "Team.SetRole", func(ctx context.Context) error {
+		return r.next.SetRole(ctx, teamName, userID, role)
+	})
+}
+
+func (r *TeamRepository) Delete(ctx context.Context, name string) error {
+	return exec(ctx, r.mw, "Team.Delete", func(ctx context.Context) error {
+		return r.next.Delete(ctx, name)
+	})
+}
+
+func (r *TeamRepository) Restore(ctx context.Context, name string) error {
+	return exec(ctx, r.mw, "Team.Restore", func(ctx context.Context) error {
+		return r.next.Restore(ctx, name)
+	})
+}
+
+func (r *TeamRepository) Rename(ctx context.Context, name, newName string) error {
+	return exec(ctx, r.mw, "Team.Rename", func(ctx context.Context) error {
+		return r.next.Rename(ctx, name, newName)
+	})
+}
+
+func (r *TeamRepository) SetParent(ctx context.Context, name, parentName string) error {
+	return exec(ctx, r.mw, "Team.SetParent", func(ctx context.Context) error {
+		return r.next.SetParent(ctx, name, parentName)
+	})
+}
+
+func (r *TeamRepository) ListSubTeams(ctx context.Context, name string) ([]string, error) {
+	return query(ctx, r.mw, "Team.ListSubTeams", func(ctx context.Context) ([]string, error) {
+		return r.next.ListSubTeams(ctx, name)
+	})
+}
+
+func (r *TeamRepository) List(ctx context.Context) ([]*domain.Team, error) {
+	return query(ctx, r.mw, "Team.List", func(ctx context.Context) ([]*domain.Team, error) {
+		return r.next.List(ctx)
+	})
+}
+
+func (r *TeamRepository) GetByName(ctx context.Context, name string) (*domain.Team, error) {
+	return query(ctx, r.mw, "Team.GetByName", func(ctx context.Context) (*domain.Team, error) {
+		return r.next.GetByName(ctx, name)
+	})
+}
+
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	return query(ctx, r.mw, "Team.GetByID", func(ctx context.Context) (*domain.Team, error) {
+		return r.next.GetByID(ctx, id)
+	})
+}
+
+func (r *TeamRepository) Exists(ctx context.Context, name string) (bool, error) {
+	return query(ctx, r.mw, "Team.Exists", func(ctx context.Context) (bool, error) {
+		return r.next.Exists(ctx, name)
+	})
+}
+
+type PullRequestRepository struct {
+	next repository.PullRequestRepository
+	mw   Middleware
+}
+
+func NewPullRequestRepository(next repository.PullRequestRepository, mw Middleware) *PullRequestRepository {
+	return &PullRequestRepository{next: next, mw: mw}
+}
+
+func (r *PullRequestRepository) Create(ctx context.Context, pr *domain.PullRequest) error {
+	return exec(ctx, r.mw, "PR.Create", func(ctx context.Context) error {
+		return r.next.Create(ctx, pr)
+	})
+}
+
+func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*domain.PullRequest, error) {
+	return query(ctx, r.mw, "PR.GetByID", func(ctx context.Context) (*domain.PullRequest, error) {
+		return r.next.GetByID(ctx, id)
+	})
+}
+
+func (r *PullRequestRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
+	return exec(ctx, r.mw, "PR.Update", func(ctx context.Context) error {
+		return r.next.Update(ctx, pr)
+	})
+}
+
+func (r *PullRequestRepository) ListByReviewer(ctx context.Context, userID string) ([]*domain.PullRequest, error) {
+	return query(ctx, r.mw, "PR.ListByReviewer", func(ctx context.Context) ([]*domain.PullRequest, error) {
+		return r.next.ListByReviewer(ctx, userID)
+	})
+}
+
+func (r *PullRequestRepository) ListOpenByTeam(ctx context.Context, teamName string) ([]*domain.PullRequest, error) {
+	return query(ctx, r.mw, "PR.ListOpenByTeam", func(ctx context.Context) ([]*domain.PullRequest, error) {
+		return r.next.ListOpenByTeam(ctx, teamName)
+	})
+}
+
+func (r *PullRequestRepository) Exists(ctx context.Context, id string) (bool, error) {
+	return query(ctx, r.mw, "PR.Exists", func(ctx context.Context) (bool, error) {
+		return r.next.Exists(ctx, id)
+	})
+}
+
+type PRStatusRepository struct {
+	next repository.PRStatusRepository
+	mw   Middleware
+}
+
+func NewPRStatusRepository(next repository.PRStatusRepository, mw Middleware) *PRStatusRepository {
+	return &PRStatusRepository{next: next, mw: mw}
+}
+
+func (r *PRStatusRepository) GetByCode(ctx context.Context, code string) (*domain.PRStatus, error) {
+	return query(ctx, r.mw, "PRStatus.GetByCode", func(ctx context.Context) (*domain.PRStatus, error) {
+		return r.next.GetByCode(ctx, code)
+	})
+}
+
+func (r *PRStatusRepository) GetByID(ctx context.Context, id int) (*domain.PRStatus, error) {
+	return query(ctx, r.mw, "PRStatus.GetByID", func(ctx context.Context) (*domain.PRStatus, error) {
+		return r.next.GetByID(ctx, id)
+	})
+}
+
+func (r *PRStatusRepository) ListAll(ctx context.Context) ([]*domain.PRStatus, error) {
+	return query(ctx, r.mw, "PRStatus.ListAll", func(ctx context.Context) ([]*domain.PRStatus, error) {
+		return r.next.ListAll(ctx)
+	})
+}
+
+type EventsRepository struct {
+	next repository.EventsRepository
+	mw   Middleware
+}
+
+func NewEventsRepository(next repository.EventsRepository, mw Middleware) *EventsRepository {
+	return &EventsRepository{next: next, mw: mw}
+}
+
+func (r *EventsRepository) CreateEvent(ctx context.Context, event *domain.Event) error {
+	return exec(ctx, r.mw, "Events.CreateEvent", func(ctx context.Context) error {
+		return r.next.CreateEvent(ctx, event)
+	})
+}
+
+func (r *EventsRepository) GetEventsByType(ctx context.Context, eventType domain.EventType, limit int) ([]domain.Event, error) {
+	return query(ctx, r.mw, "Events.GetEventsByType", func(ctx context.Context) ([]domain.Event, error) {
+		return r.next.GetEventsByType(ctx, eventType, limit)
+	})
+}
+
+func (r *EventsRepository) GetEventCountsByType(ctx context.Context) (map[domain.EventType]int, error) {
+	return query(ctx, r.mw, "Events.GetEventCountsByType", func(ctx context.Context) (map[domain.EventType]int, error) {
+		return r.next.GetEventCountsByType(ctx)
+	})
+}
+
+type StatsRepository struct {
+	next repository.StatsRepository
+	mw   Middleware
+}
+
+func NewStatsRepository(next repository.StatsRepository, mw Middleware) *StatsRepository {
+	return &StatsRepository{next: next, mw: mw}
+}
+
+func (r *StatsRepository) GetEventStats(ctx context.Context) (*domain.StatsResponse, error) {
+	return query(ctx, r.mw, "Stats.GetEventStats", func(ctx context.Context) (*domain.StatsResponse, error) {
+		return r.next.GetEventStats(ctx)
+	})
+}
+
+func (r *StatsRepository) GetOpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
+	return query(ctx, r.mw, "Stats.GetOpenReviewsByTeam", func(ctx context.Context) (map[string]int, error) {
+		return r.next.GetOpenReviewsByTeam(ctx)
+	})
+}
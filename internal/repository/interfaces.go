@@ -1,44 +1,86 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/111zxc/pr-review-service/internal/domain"
 )
 
 type (
 	UserRepository interface {
-		Create(user *domain.User) error
-		GetByID(id string) (*domain.User, error)
-		Update(user *domain.User) error
-		GetByTeam(teamName string) ([]*domain.User, error)
+		Create(ctx context.Context, user *domain.User) error
+		GetByID(ctx context.Context, id string) (*domain.User, error)
+		Update(ctx context.Context, user *domain.User) error
+		GetByTeam(ctx context.Context, teamName string) ([]*domain.User, error)
+		// List returns a page of the users matching filter, sorted by ID,
+		// and the number of matching users.
+		List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int, error)
+		// CountOpenReviews returns how many open pull requests each of
+		// userIDs reviews; users without any are left out.
+		CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error)
+		// Delete soft-deletes the user and drops their team memberships;
+		// Create with the same ID restores them.
+		Delete(ctx context.Context, userID string) error
+		// Erase replaces the user's ID and username with pseudonym
+		// everywhere, scrubs it from event data and soft-deletes the
+		// pseudonymous user. Team memberships are dropped.
+		Erase(ctx context.Context, userID, pseudonym string) (domain.ErasedRecords, error)
 	}
 
 	TeamRepository interface {
-		Create(team *domain.Team) error
-		GetByName(name string) (*domain.Team, error)
-		Exists(name string) (bool, error)
+		Create(ctx context.Context, team *domain.Team) error
+		GetByName(ctx context.Context, name string) (*domain.Team, error)
+		GetByID(ctx context.Context, id string) (*domain.Team, error)
+		Exists(ctx context.Context, name string) (bool, error)
+		// AddMembers adds existing users to a team; current members are
+		// left as they are.
+		AddMembers(ctx context.Context, teamName string, userIDs []string) error
+		RemoveMember(ctx context.Context, teamName, userID string) error
+		// SetRole changes a member's role; ErrNotTeamMember when userID is
+		// not in the team.
+		SetRole(ctx context.Context, teamName, userID string, role domain.TeamRole) error
+		// Delete soft-deletes a team: it keeps its members and name but is
+		// no longer found by the other methods until Restore.
+		Delete(ctx context.Context, name string) error
+		Restore(ctx context.Context, name string) error
+		// Rename fails with ErrTeamExists when newName is taken, even by a
+		// deleted team. The team keeps its ID.
+		Rename(ctx context.Context, name, newName string) error
+		// SetParent nests a team under parentName, or makes it top-level
+		// when parentName is empty. Cycles are not checked.
+		SetParent(ctx context.Context, name, parentName string) error
+		// ListSubTeams returns the names of a team's live direct
+		// sub-teams, sorted.
+		ListSubTeams(ctx context.Context, name string) ([]string, error)
+		// List returns every live team with its members, sorted by name.
+		List(ctx context.Context) ([]*domain.Team, error)
 	}
 
 	PullRequestRepository interface {
-		Create(pr *domain.PullRequest) error
-		GetByID(id string) (*domain.PullRequest, error)
-		Update(pr *domain.PullRequest) error
-		ListByReviewer(userID string) ([]*domain.PullRequest, error)
-		Exists(id string) (bool, error)
+		Create(ctx context.Context, pr *domain.PullRequest) error
+		GetByID(ctx context.Context, id string) (*domain.PullRequest, error)
+		Update(ctx context.Context, pr *domain.PullRequest) error
+		ListByReviewer(ctx context.Context, userID string) ([]*domain.PullRequest, error)
+		ListOpenByTeam(ctx context.Context, teamName string) ([]*domain.PullRequest, error)
+		Exists(ctx context.Context, id string) (bool, error)
 	}
 
 	PRStatusRepository interface {
-		GetByCode(code string) (*domain.PRStatus, error)
-		GetByID(id int) (*domain.PRStatus, error)
-		ListAll() ([]*domain.PRStatus, error)
+		GetByCode(ctx context.Context, code string) (*domain.PRStatus, error)
+		GetByID(ctx context.Context, id int) (*domain.PRStatus, error)
+		ListAll(ctx context.Context) ([]*domain.PRStatus, error)
 	}
 
 	EventsRepository interface {
-		CreateEvent(event *domain.Event) error
-		GetEventsByType(eventType domain.EventType, limit int) ([]domain.Event, error)
-		GetEventCountsByType() (map[domain.EventType]int, error)
+		CreateEvent(ctx context.Context, event *domain.Event) error
+		GetEventsByType(ctx context.Context, eventType domain.EventType, limit int) ([]domain.Event, error)
+		GetEventCountsByType(ctx context.Context) (map[domain.EventType]int, error)
 	}
 
 	StatsRepository interface {
-		GetEventStats() (*domain.StatsResponse, error)
+		GetEventStats(ctx context.Context) (*domain.StatsResponse, error)
+		// GetOpenReviewsByTeam counts open reviews by the team that owns
+		// the pull request, so each review counts once.
+		GetOpenReviewsByTeam(ctx context.Context) (map[string]int, error)
 	}
 )
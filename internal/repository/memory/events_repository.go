@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type EventsRepository struct {
+	store *Store
+}
+
+func NewEventsRepository(store *Store) *EventsRepository {
+	return &EventsRepository{store: store}
+}
+
+func (r *EventsRepository) CreateEvent(ctx context.Context, event *domain.Event) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.pullRequests[event.PRID]; !ok {
+		return fmt.Errorf("event references unknown pull request %s", event.PRID)
+	}
+	if _, ok := r.store.users[event.UserID]; !ok {
+		return fmt.Errorf("event references unknown user %s", event.UserID)
+	}
+
+	stored := *event
+	stored.ID = r.store.nextEventID
+	stored.AdditionalData = slices.Clone(event.AdditionalData)
+	stored.CreatedAt = time.Now()
+
+	r.store.nextEventID++
+	r.store.events = append(r.store.events, stored)
+	return nil
+}
+
+func (r *EventsRepository) GetEventsByType(ctx context.Context, eventType domain.EventType, limit int) ([]domain.Event, error) {
+	defer r.store.rlock(ctx)()
+
+	var events []domain.Event
+	for i := len(r.store.events) - 1; i >= 0 && len(events) < limit; i-- {
+		event := r.store.events[i]
+		if event.EventType == eventType {
+			event.AdditionalData = slices.Clone(event.AdditionalData)
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *EventsRepository) GetEventCountsByType(ctx context.Context) (map[domain.EventType]int, error) {
+	defer r.store.rlock(ctx)()
+
+	counts := make(map[domain.EventType]int)
+	for _, event := range r.store.events {
+		counts[event.EventType]++
+	}
+	return counts, nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type PRStatusRepository struct {
+	store *Store
+}
+
+func NewPRStatusRepository(store *Store) *PRStatusRepository {
+	return &PRStatusRepository{store: store}
+}
+
+func (r *PRStatusRepository) GetByCode(ctx context.Context, code string) (*domain.PRStatus, error) {
+	defer r.store.rlock(ctx)()
+
+	status, ok := r.store.statusByCode(code)
+	if !ok {
+		return nil, fmt.Errorf("PR status not found: %s", code)
+	}
+	return &status, nil
+}
+
+func (r *PRStatusRepository) GetByID(ctx context.Context, id int) (*domain.PRStatus, error) {
+	defer r.store.rlock(ctx)()
+
+	status, ok := r.store.statusByID(id)
+	if !ok {
+		return nil, fmt.Errorf("PR status not found with ID: %d", id)
+	}
+	return &status, nil
+}
+
+func (r *PRStatusRepository) ListAll(ctx context.Context) ([]*domain.PRStatus, error) {
+	defer r.store.rlock(ctx)()
+
+	statuses := make([]*domain.PRStatus, 0, len(r.store.statuses))
+	for _, status := range r.store.statuses {
+		statuses = append(statuses, &status)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type PullRequestRepository struct {
+	store *Store
+}
+
+func NewPullRequestRepository(store *Store) *PullRequestRepository {
+	return &PullRequestRepository{store: store}
+}
+
+func (r *PullRequestRepository) Create(ctx context.Context, pr *domain.PullRequest) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.pullRequests[pr.ID]; ok {
+		return fmt.Errorf("failed to create pull request: %s already exists", pr.ID)
+	}
+	if _, ok := r.store.users[pr.AuthorID]; !ok {
+		return fmt.Errorf("failed to create pull request: author %s does not exist", pr.AuthorID)
+	}
+	if err := r.checkReviewers(pr.AssignedReviewers); err != nil {
+		return err
+	}
+
+	open, _ := r.store.statusByCode(domain.PRStatusOpen)
+	now := time.Now()
+	r.store.pullRequests[pr.ID] = &pullRequestRecord{
+		id:        pr.ID,
+		name:      pr.Name,
+		authorID:  pr.AuthorID,
+		teamID:    r.teamID(pr.TeamName),
+		statusID:  open.ID,
+		reviewers: slices.Clone(pr.AssignedReviewers),
+		createdAt: now,
+	}
+
+	pr.Status = domain.PRStatusOpen
+	pr.CreatedAt = &now
+	return nil
+}
+
+func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*domain.PullRequest, error) {
+	defer r.store.rlock(ctx)()
+
+	rec, ok := r.store.pullRequests[id]
+	if !ok {
+		return nil, domain.ErrPullRequestNotFound
+	}
+	return r.toDomain(rec), nil
+}
+
+func (r *PullRequestRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
+	defer r.store.lock(ctx)()
+
+	status, ok := r.store.statusByCode(pr.Status)
+	if !ok {
+		return fmt.Errorf("failed to get status: unknown status %s", pr.Status)
+	}
+
+	rec, ok := r.store.pullRequests[pr.ID]
+	if !ok {
+		return domain.ErrPullRequestNotFound
+	}
+	if err := r.checkReviewers(pr.AssignedReviewers); err != nil {
+		return err
+	}
+
+	rec.name = pr.Name
+	rec.teamID = r.teamID(pr.TeamName)
+	rec.statusID = status.ID
+	rec.mergedAt = copyTime(pr.MergedAt)
+	rec.reviewers = slices.Clone(pr.AssignedReviewers)
+	return nil
+}
+
+func (r *PullRequestRepository) ListByReviewer(ctx context.Context, userID string) ([]*domain.PullRequest, error) {
+	defer r.store.rlock(ctx)()
+
+	var prs []*domain.PullRequest
+	for _, rec := range r.store.pullRequests {
+		if slices.Contains(rec.reviewers, userID) {
+			prs = append(prs, r.toDomain(rec))
+		}
+	}
+
+	sort.Slice(prs, func(i, j int) bool {
+		return prs[i].CreatedAt.After(*prs[j].CreatedAt)
+	})
+	return prs, nil
+}
+
+func (r *PullRequestRepository) ListOpenByTeam(ctx context.Context, teamName string) ([]*domain.PullRequest, error) {
+	defer r.store.rlock(ctx)()
+
+	team, ok := r.store.teamByName(teamName)
+	if !ok {
+		return nil, nil
+	}
+
+	open, _ := r.store.statusByCode(domain.PRStatusOpen)
+	var prs []*domain.PullRequest
+	for _, rec := range r.store.pullRequests {
+		if rec.teamID == team.id && rec.statusID == open.ID {
+			prs = append(prs, r.toDomain(rec))
+		}
+	}
+
+	sort.Slice(prs, func(i, j int) bool {
+		return prs[i].CreatedAt.Before(*prs[j].CreatedAt)
+	})
+	return prs, nil
+}
+
+func (r *PullRequestRepository) Exists(ctx context.Context, id string) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	_, ok := r.store.pullRequests[id]
+	return ok, nil
+}
+
+// teamID mirrors the team_id lookup in SQL: an unknown team is not
+// recorded.
+func (r *PullRequestRepository) teamID(name string) string {
+	if t, ok := r.store.teamByName(name); ok {
+		return t.id
+	}
+	return ""
+}
+
+// checkReviewers mirrors the foreign key and primary key on pr_reviewers.
+func (r *PullRequestRepository) checkReviewers(reviewers []string) error {
+	for i, id := range reviewers {
+		if _, ok := r.store.users[id]; !ok {
+			return fmt.Errorf("failed to add reviewer: user %s does not exist", id)
+		}
+		if slices.Contains(reviewers[:i], id) {
+			return fmt.Errorf("failed to add reviewer: %s is assigned twice", id)
+		}
+	}
+	return nil
+}
+
+func (r *PullRequestRepository) toDomain(rec *pullRequestRecord) *domain.PullRequest {
+	status, _ := r.store.statusByID(rec.statusID)
+	createdAt := rec.createdAt
+
+	var teamName string
+	if t, ok := r.store.teams[rec.teamID]; ok {
+		teamName = t.name
+	}
+
+	pr := &domain.PullRequest{
+		ID:        rec.id,
+		Name:      rec.name,
+		AuthorID:  rec.authorID,
+		TeamName:  teamName,
+		Status:    status.Code,
+		CreatedAt: &createdAt,
+		MergedAt:  copyTime(rec.mergedAt),
+	}
+	if len(rec.reviewers) > 0 {
+		pr.AssignedReviewers = slices.Clone(rec.reviewers)
+	}
+	return pr
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type StatsRepository struct {
+	store *Store
+}
+
+func NewStatsRepository(store *Store) *StatsRepository {
+	return &StatsRepository{store: store}
+}
+
+func (r *StatsRepository) GetEventStats(ctx context.Context) (*domain.StatsResponse, error) {
+	defer r.store.rlock(ctx)()
+
+	stats := &domain.StatsResponse{
+		EventCounts: map[domain.EventType]int{
+			domain.EventTypePRCreated:          0,
+			domain.EventTypePRMerged:           0,
+			domain.EventTypePRClosed:           0,
+			domain.EventTypeReviewerAssigned:   0,
+			domain.EventTypeReviewerReassigned: 0,
+			domain.EventTypeReviewerUnassigned: 0,
+		},
+	}
+	for _, event := range r.store.events {
+		stats.EventCounts[event.EventType]++
+		stats.TotalEvents++
+	}
+	return stats, nil
+}
+
+func (r *StatsRepository) GetOpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
+	defer r.store.rlock(ctx)()
+
+	open, _ := r.store.statusByCode(domain.PRStatusOpen)
+
+	counts := make(map[string]int)
+	for _, pr := range r.store.pullRequests {
+		if pr.statusID != open.ID {
+			continue
+		}
+		if team, ok := r.store.teams[pr.teamID]; ok && !team.deleted {
+			counts[team.name] += len(pr.reviewers)
+		}
+	}
+	return counts, nil
+}
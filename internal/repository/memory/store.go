@@ -0,0 +1,141 @@
+// Package memory implements the repository interfaces on top of plain maps.
+// It backs ENV=memory for local development and fast tests; data lives only
+// as long as the process.
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type userRecord struct {
+	id       string
+	username string
+	isActive bool
+	// deleted users are only kept for the records that refer to them.
+	deleted bool
+}
+
+type teamRecord struct {
+	id       string
+	name     string
+	parentID string
+	members  []string
+	// roles holds the members whose role is not RoleMember.
+	roles   map[string]domain.TeamRole
+	deleted bool
+}
+
+func (t *teamRecord) role(userID string) domain.TeamRole {
+	if role, ok := t.roles[userID]; ok {
+		return role
+	}
+	return domain.RoleMember
+}
+
+type pullRequestRecord struct {
+	id        string
+	name      string
+	authorID  string
+	teamID    string
+	statusID  int
+	reviewers []string
+	createdAt time.Time
+	mergedAt  *time.Time
+}
+
+// Store holds the state shared by all in-memory repositories. A single lock
+// keeps multi-record writes atomic, like the transactions in the Postgres
+// backend.
+type Store struct {
+	mu sync.RWMutex
+
+	users        map[string]*userRecord
+	teams        map[string]*teamRecord // by ID
+	teamOrder    []string
+	pullRequests map[string]*pullRequestRecord
+	statuses     []domain.PRStatus
+	events       []domain.Event
+	nextEventID  int
+}
+
+func NewStore() *Store {
+	now := time.Now()
+	return &Store{
+		users:        make(map[string]*userRecord),
+		teams:        make(map[string]*teamRecord),
+		pullRequests: make(map[string]*pullRequestRecord),
+		statuses: []domain.PRStatus{
+			{ID: 1, Code: domain.PRStatusOpen, Name: "Open", Description: "Pull Request is open for review", CreatedAt: now},
+			{ID: 2, Code: domain.PRStatusMerged, Name: "Merged", Description: "Pull Request has been merged", CreatedAt: now},
+			{ID: 3, Code: domain.PRStatusClosed, Name: "Closed", Description: "Pull Request was closed without merging", CreatedAt: now},
+		},
+		nextEventID: 1,
+	}
+}
+
+// team returns a team by name unless it is unknown or soft-deleted. Callers
+// must hold the lock.
+func (s *Store) team(name string) (*teamRecord, bool) {
+	t, ok := s.teamByName(name)
+	if !ok || t.deleted {
+		return nil, false
+	}
+	return t, true
+}
+
+// teamByName also finds soft-deleted teams. Callers must hold the lock.
+func (s *Store) teamByName(name string) (*teamRecord, bool) {
+	for _, id := range s.teamOrder {
+		if t := s.teams[id]; t.name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// teamsOf returns the live teams a user belongs to sorted by name, and the
+// user's role in each. Callers must hold the lock.
+func (s *Store) teamsOf(userID string) ([]string, map[string]domain.TeamRole) {
+	var teams []string
+	var roles map[string]domain.TeamRole
+	for _, id := range s.teamOrder {
+		t := s.teams[id]
+		if !t.deleted && slices.Contains(t.members, userID) {
+			if roles == nil {
+				roles = make(map[string]domain.TeamRole)
+			}
+			teams = append(teams, t.name)
+			roles[t.name] = t.role(userID)
+		}
+	}
+	slices.Sort(teams)
+	return teams, roles
+}
+
+// user builds the domain user for a record. Callers must hold the lock.
+func (s *Store) user(u *userRecord) *domain.User {
+	teams, roles := s.teamsOf(u.id)
+	return &domain.User{ID: u.id, Username: u.username, IsActive: u.isActive, Teams: teams, Roles: roles}
+}
+
+func (s *Store) statusByCode(code string) (domain.PRStatus, bool) {
+	for _, status := range s.statuses {
+		if status.Code == code {
+			return status, true
+		}
+	}
+	return domain.PRStatus{}, false
+}
+
+func (s *Store) statusByID(id int) (domain.PRStatus, bool) {
+	for _, status := range s.statuses {
+		if status.ID == id {
+			return status, true
+		}
+	}
+	return domain.PRStatus{}, false
+}
@@ -0,0 +1,246 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type TeamRepository struct {
+	store *Store
+}
+
+func NewTeamRepository(store *Store) *TeamRepository {
+	return &TeamRepository{store: store}
+}
+
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	defer r.store.lock(ctx)()
+
+	// A soft-deleted team keeps its name until it is restored.
+	if _, ok := r.store.teamByName(team.Name); ok {
+		return domain.ErrTeamExists
+	}
+	if team.ID == "" {
+		team.ID = domain.NewTeamID()
+	}
+	if _, ok := r.store.teams[team.ID]; ok {
+		return fmt.Errorf("failed to create team: id %s is taken", team.ID)
+	}
+
+	var parentID string
+	if team.ParentName != "" {
+		parent, ok := r.store.team(team.ParentName)
+		if !ok {
+			return domain.ErrTeamNotFound
+		}
+		parentID = parent.id
+	}
+
+	members := make([]string, 0, len(team.Members))
+	roles := make(map[string]domain.TeamRole)
+	for _, member := range team.Members {
+		if _, ok := r.store.users[member.UserID]; !ok {
+			return fmt.Errorf("failed to add team member: user %s does not exist", member.UserID)
+		}
+		for _, existing := range members {
+			if existing == member.UserID {
+				return fmt.Errorf("failed to add team member: user %s is listed twice", member.UserID)
+			}
+		}
+		members = append(members, member.UserID)
+		if member.Role != "" && member.Role != domain.RoleMember {
+			roles[member.UserID] = member.Role
+		}
+	}
+
+	r.store.teams[team.ID] = &teamRecord{id: team.ID, name: team.Name, parentID: parentID, members: members, roles: roles}
+	r.store.teamOrder = append(r.store.teamOrder, team.ID)
+	return nil
+}
+
+func (r *TeamRepository) GetByName(ctx context.Context, name string) (*domain.Team, error) {
+	defer r.store.rlock(ctx)()
+
+	t, ok := r.store.team(name)
+	if !ok {
+		return nil, domain.ErrTeamNotFound
+	}
+	return r.toDomain(t), nil
+}
+
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	defer r.store.rlock(ctx)()
+
+	t, ok := r.store.teams[id]
+	if !ok || t.deleted {
+		return nil, domain.ErrTeamNotFound
+	}
+	return r.toDomain(t), nil
+}
+
+func (r *TeamRepository) Exists(ctx context.Context, name string) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	_, ok := r.store.team(name)
+	return ok, nil
+}
+
+func (r *TeamRepository) AddMembers(ctx context.Context, teamName string, userIDs []string) error {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.team(teamName)
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+	for _, id := range userIDs {
+		if _, ok := r.store.users[id]; !ok {
+			return fmt.Errorf("failed to add team member: user %s does not exist", id)
+		}
+	}
+
+	for _, id := range userIDs {
+		if !slices.Contains(t.members, id) {
+			t.members = append(t.members, id)
+		}
+	}
+	return nil
+}
+
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamName, userID string) error {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.team(teamName)
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+
+	i := slices.Index(t.members, userID)
+	if i < 0 {
+		return domain.ErrNotTeamMember
+	}
+	t.members = slices.Delete(t.members, i, i+1)
+	delete(t.roles, userID)
+	return nil
+}
+
+func (r *TeamRepository) SetRole(ctx context.Context, teamName, userID string, role domain.TeamRole) error {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.team(teamName)
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+	if !slices.Contains(t.members, userID) {
+		return domain.ErrNotTeamMember
+	}
+	if role == domain.RoleMember {
+		delete(t.roles, userID)
+	} else {
+		t.roles[userID] = role
+	}
+	return nil
+}
+
+func (r *TeamRepository) Delete(ctx context.Context, name string) error {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.team(name)
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+	t.deleted = true
+	return nil
+}
+
+func (r *TeamRepository) Restore(ctx context.Context, name string) error {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.teamByName(name)
+	if !ok || !t.deleted {
+		return domain.ErrTeamNotFound
+	}
+	t.deleted = false
+	return nil
+}
+
+func (r *TeamRepository) Rename(ctx context.Context, name, newName string) error {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.team(name)
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+	if _, ok := r.store.teamByName(newName); ok {
+		return domain.ErrTeamExists
+	}
+	t.name = newName
+	return nil
+}
+
+func (r *TeamRepository) SetParent(ctx context.Context, name, parentName string) error {
+	defer r.store.lock(ctx)()
+
+	t, ok := r.store.team(name)
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+	if parentName == "" {
+		t.parentID = ""
+		return nil
+	}
+	parent, ok := r.store.team(parentName)
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+	t.parentID = parent.id
+	return nil
+}
+
+func (r *TeamRepository) ListSubTeams(ctx context.Context, name string) ([]string, error) {
+	defer r.store.rlock(ctx)()
+
+	t, ok := r.store.team(name)
+	if !ok {
+		return nil, domain.ErrTeamNotFound
+	}
+
+	var children []string
+	for _, id := range r.store.teamOrder {
+		if child := r.store.teams[id]; child.parentID == t.id && !child.deleted {
+			children = append(children, child.name)
+		}
+	}
+	slices.Sort(children)
+	return children, nil
+}
+
+func (r *TeamRepository) List(ctx context.Context) ([]*domain.Team, error) {
+	defer r.store.rlock(ctx)()
+
+	var teams []*domain.Team
+	for _, id := range r.store.teamOrder {
+		if t := r.store.teams[id]; !t.deleted {
+			teams = append(teams, r.toDomain(t))
+		}
+	}
+	slices.SortFunc(teams, func(a, b *domain.Team) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return teams, nil
+}
+
+func (r *TeamRepository) toDomain(t *teamRecord) *domain.Team {
+	team := &domain.Team{ID: t.id, Name: t.name}
+	if parent, ok := r.store.teams[t.parentID]; ok && !parent.deleted {
+		team.ParentName = parent.name
+	}
+	for _, id := range t.members {
+		u := r.store.users[id]
+		team.Members = append(team.Members, domain.TeamMember{UserID: u.id, Username: u.username, IsActive: u.isActive, Role: t.role(id)})
+	}
+	return team
+}
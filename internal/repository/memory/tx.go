@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/repository"
+)
+
+type txKey struct{}
+
+// TxManager implements repository.Transactor by holding the store's write
+// lock for the whole transaction and restoring a snapshot on rollback.
+type TxManager struct {
+	store *Store
+}
+
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+func (tm *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tm.store.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	ctx, committed := repository.TrackAfterCommit(ctx)
+
+	tm.store.mu.Lock()
+	snapshot := tm.store.snapshot()
+	err := fn(context.WithValue(ctx, txKey{}, tm.store))
+	if err != nil {
+		tm.store.restore(snapshot)
+	}
+	tm.store.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	committed()
+	return nil
+}
+
+func (s *Store) inTx(ctx context.Context) bool {
+	tx, _ := ctx.Value(txKey{}).(*Store)
+	return tx == s
+}
+
+// lock takes the write lock, unless the transaction in ctx already holds it,
+// and returns the matching unlock.
+func (s *Store) lock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// rlock is lock for readers.
+func (s *Store) rlock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+type storeSnapshot struct {
+	users        map[string]*userRecord
+	teams        map[string]*teamRecord
+	teamOrder    []string
+	pullRequests map[string]*pullRequestRecord
+	events       []domain.Event
+	nextEventID  int
+}
+
+// snapshot deep-copies the mutable state. Callers must hold the lock.
+func (s *Store) snapshot() storeSnapshot {
+	snap := storeSnapshot{
+		users:        make(map[string]*userRecord, len(s.users)),
+		teams:        make(map[string]*teamRecord, len(s.teams)),
+		teamOrder:    slices.Clone(s.teamOrder),
+		pullRequests: make(map[string]*pullRequestRecord, len(s.pullRequests)),
+		events:       slices.Clone(s.events),
+		nextEventID:  s.nextEventID,
+	}
+	for id, u := range s.users {
+		c := *u
+		snap.users[id] = &c
+	}
+	for id, t := range s.teams {
+		c := *t
+		c.members = slices.Clone(t.members)
+		snap.teams[id] = &c
+	}
+	for id, pr := range s.pullRequests {
+		c := *pr
+		c.reviewers = slices.Clone(pr.reviewers)
+		c.mergedAt = copyTime(pr.mergedAt)
+		snap.pullRequests[id] = &c
+	}
+	return snap
+}
+
+// restore puts back a snapshot. Callers must hold the lock.
+func (s *Store) restore(snap storeSnapshot) {
+	s.users = snap.users
+	s.teams = snap.teams
+	s.teamOrder = snap.teamOrder
+	s.pullRequests = snap.pullRequests
+	s.events = snap.events
+	s.nextEventID = snap.nextEventID
+}
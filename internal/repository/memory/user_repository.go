@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	defer r.store.lock(ctx)()
+
+	r.store.users[user.ID] = &userRecord{id: user.ID, username: user.Username, isActive: user.IsActive}
+	return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	defer r.store.rlock(ctx)()
+
+	u, ok := r.store.users[id]
+	if !ok || u.deleted {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return r.store.user(u), nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	defer r.store.lock(ctx)()
+
+	u, ok := r.store.users[user.ID]
+	if !ok || u.deleted {
+		return domain.ErrUserNotFound
+	}
+
+	u.username = user.Username
+	u.isActive = user.IsActive
+	return nil
+}
+
+func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]*domain.User, error) {
+	defer r.store.rlock(ctx)()
+
+	team, ok := r.store.team(teamName)
+	if !ok {
+		return nil, nil
+	}
+
+	var users []*domain.User
+	for _, id := range team.members {
+		u := r.store.users[id]
+		if !u.isActive {
+			continue
+		}
+		users = append(users, r.store.user(u))
+	}
+	return users, nil
+}
+
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int, error) {
+	defer r.store.rlock(ctx)()
+
+	var matched []*userRecord
+	for _, u := range r.store.users {
+		if u.deleted {
+			continue
+		}
+		if filter.Username != "" && u.username != filter.Username {
+			continue
+		}
+		if filter.IsActive != nil && u.isActive != *filter.IsActive {
+			continue
+		}
+		if !strings.HasPrefix(u.username, filter.UsernamePrefix) {
+			continue
+		}
+		if filter.Team != "" {
+			team, ok := r.store.team(filter.Team)
+			if !ok || !slices.Contains(team.members, u.id) {
+				continue
+			}
+		}
+		matched = append(matched, u)
+	}
+	slices.SortFunc(matched, func(a, b *userRecord) int {
+		return strings.Compare(a.id, b.id)
+	})
+
+	page := matched[min(filter.Offset, len(matched)):]
+	if filter.Limit > 0 && len(page) > filter.Limit {
+		page = page[:filter.Limit]
+	}
+	users := make([]*domain.User, 0, len(page))
+	for _, u := range page {
+		users = append(users, r.store.user(u))
+	}
+	return users, len(matched), nil
+}
+
+func (r *UserRepository) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	defer r.store.rlock(ctx)()
+
+	open, _ := r.store.statusByCode(domain.PRStatusOpen)
+	counts := make(map[string]int)
+	for _, pr := range r.store.pullRequests {
+		if pr.statusID != open.ID {
+			continue
+		}
+		for _, reviewer := range pr.reviewers {
+			if slices.Contains(userIDs, reviewer) {
+				counts[reviewer]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	defer r.store.lock(ctx)()
+
+	u, ok := r.store.users[userID]
+	if !ok || u.deleted {
+		return domain.ErrUserNotFound
+	}
+
+	for _, team := range r.store.teams {
+		team.members = slices.DeleteFunc(team.members, func(id string) bool { return id == userID })
+		delete(team.roles, userID)
+	}
+	u.isActive = false
+	u.deleted = true
+	return nil
+}
+
+func (r *UserRepository) Erase(ctx context.Context, userID, pseudonym string) (domain.ErasedRecords, error) {
+	defer r.store.lock(ctx)()
+
+	var erased domain.ErasedRecords
+	u, ok := r.store.users[userID]
+	if !ok || u.deleted {
+		return erased, domain.ErrUserNotFound
+	}
+
+	// Rewrite the event data first: it is the only step that can fail.
+	events := slices.Clone(r.store.events)
+	for i, e := range events {
+		scrubbed, changed, err := scrubEventData(e.AdditionalData, userID, pseudonym)
+		if err != nil {
+			return erased, err
+		}
+		if e.UserID == userID {
+			events[i].UserID = pseudonym
+			changed = true
+		}
+		if changed {
+			events[i].AdditionalData = scrubbed
+			erased.Events++
+		}
+	}
+	r.store.events = events
+
+	for _, pr := range r.store.pullRequests {
+		if pr.authorID == userID {
+			pr.authorID = pseudonym
+			erased.PullRequests++
+		}
+		if i := slices.Index(pr.reviewers, userID); i >= 0 {
+			pr.reviewers[i] = pseudonym
+			erased.PastReviews++
+		}
+	}
+	for _, team := range r.store.teams {
+		team.members = slices.DeleteFunc(team.members, func(id string) bool { return id == userID })
+		delete(team.roles, userID)
+	}
+
+	delete(r.store.users, userID)
+	r.store.users[pseudonym] = &userRecord{id: pseudonym, username: pseudonym, deleted: true}
+	return erased, nil
+}
+
+// scrubEventData replaces userID in the user ID fields of an event's
+// additional data.
+func scrubEventData(data json.RawMessage, userID, pseudonym string) (json.RawMessage, bool, error) {
+	if len(data) == 0 {
+		return data, false, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, false, fmt.Errorf("failed to decode event data: %w", err)
+	}
+
+	changed := false
+	for _, key := range []string{"old_user_id", "new_user_id"} {
+		if fields[key] == userID {
+			fields[key] = pseudonym
+			changed = true
+		}
+	}
+	if !changed {
+		return data, false, nil
+	}
+	scrubbed, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode event data: %w", err)
+	}
+	return scrubbed, true, nil
+}
@@ -3,27 +3,24 @@ package postgres
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
 	"github.com/111zxc/pr-review-service/internal/domain"
 )
 
 type EventsRepository struct {
-	pool *pgxpool.Pool
+	db *Router
 }
 
-func NewEventsRepository(pool *pgxpool.Pool) *EventsRepository {
-	return &EventsRepository{pool: pool}
+func NewEventsRepository(db *Router) *EventsRepository {
+	return &EventsRepository{db: db}
 }
 
-func (r *EventsRepository) CreateEvent(event *domain.Event) error {
+func (r *EventsRepository) CreateEvent(ctx context.Context, event *domain.Event) error {
 	query := `
         INSERT INTO events (event_type, pr_id, user_id, additional_data)
         VALUES ($1, $2, $3, $4)
     `
 
-	ctx := context.Background()
-	_, err := r.pool.Exec(ctx, query, event.EventType, event.PRID, event.UserID, event.AdditionalData)
+	_, err := r.db.Write(ctx).Exec(ctx, query, event.EventType, event.PRID, event.UserID, event.AdditionalData)
 	if err != nil {
 		return err
 	}
@@ -31,7 +28,7 @@ func (r *EventsRepository) CreateEvent(event *domain.Event) error {
 	return nil
 }
 
-func (r *EventsRepository) GetEventsByType(eventType domain.EventType, limit int) ([]domain.Event, error) {
+func (r *EventsRepository) GetEventsByType(ctx context.Context, eventType domain.EventType, limit int) ([]domain.Event, error) {
 	query := `
         SELECT id, event_type, pr_id, user_id, additional_data, created_at
         FROM events
@@ -40,8 +37,7 @@ func (r *EventsRepository) GetEventsByType(eventType domain.EventType, limit int
         LIMIT $2
     `
 
-	ctx := context.Background()
-	rows, err := r.pool.Query(ctx, query, eventType, limit)
+	rows, err := r.db.Read(ctx).Query(ctx, query, eventType, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -60,15 +56,14 @@ func (r *EventsRepository) GetEventsByType(eventType domain.EventType, limit int
 	return events, nil
 }
 
-func (r *EventsRepository) GetEventCountsByType() (map[domain.EventType]int, error) {
+func (r *EventsRepository) GetEventCountsByType(ctx context.Context) (map[domain.EventType]int, error) {
 	query := `
         SELECT event_type, COUNT(*) 
         FROM events 
         GROUP BY event_type
     `
 
-	ctx := context.Background()
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.db.Read(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/111zxc/pr-review-service/internal/logger"
+)
+
+// Notifier publishes and receives messages on a Postgres LISTEN/NOTIFY
+// channel, so every instance sharing the database sees them.
+type Notifier struct {
+	pool    *pgxpool.Pool
+	channel string
+}
+
+func NewNotifier(pool *pgxpool.Pool, channel string) *Notifier {
+	return &Notifier{pool: pool, channel: channel}
+}
+
+func (n *Notifier) Publish(ctx context.Context, payload string) error {
+	if _, err := n.pool.Exec(ctx, `SELECT pg_notify($1, $2)`, n.channel, payload); err != nil {
+		return fmt.Errorf("failed to notify %s: %w", n.channel, err)
+	}
+	return nil
+}
+
+// Listen holds one pool connection and calls handle for every message until
+// ctx is done. After the connection is lost it reconnects with backoff and
+// calls resync, since messages sent in the meantime are gone.
+func (n *Notifier) Listen(ctx context.Context, handle func(payload string), resync func()) {
+	log := logger.For(ctx, "repository/postgres")
+	backoff := initialListenBackoff
+
+	for first := true; ; first = false {
+		if !first {
+			resync()
+		}
+
+		err := n.listen(ctx, handle, func() { backoff = initialListenBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn("Lost notification channel, reconnecting", "channel", n.channel, "retry_in", backoff, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxListenBackoff)
+	}
+}
+
+const (
+	initialListenBackoff = 500 * time.Millisecond
+	maxListenBackoff     = 30 * time.Second
+)
+
+func (n *Notifier) listen(ctx context.Context, handle func(string), connected func()) error {
+	pooled, err := n.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection keeps LISTEN state, so it never goes back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background()) //nolint:errcheck
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{n.channel}.Sanitize()); err != nil {
+		return err
+	}
+	connected()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		handle(notification.Payload)
+	}
+}
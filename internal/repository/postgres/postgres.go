@@ -10,30 +10,28 @@ import (
 
 	"github.com/111zxc/pr-review-service/internal/config"
 	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/repository"
+	"github.com/111zxc/pr-review-service/internal/tracing"
 )
 
 type DB struct {
 	pool *pgxpool.Pool
 }
 
-func NewDB(cfg *config.Config) (*pgxpool.Pool, error) {
+// NewDB opens a pool to the primary and pings it. An attempt gives up after
+// ten seconds or when ctx is done, whichever comes first.
+func NewDB(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, error) {
 	dsn := fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
 		cfg.DB.User, cfg.DB.Password, cfg.DB.Host, cfg.DB.Port, cfg.DB.Name, cfg.DB.SSLMode,
 	)
 
-	config, err := pgxpool.ParseConfig(dsn)
+	config, err := poolConfig(cfg, dsn, "primary")
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+		return nil, err
 	}
 
-	config.MaxConns = 25
-	config.MinConns = 5
-	config.MaxConnLifetime = time.Hour
-	config.MaxConnIdleTime = 30 * time.Minute
-	config.HealthCheckPeriod = time.Minute
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
@@ -42,12 +40,46 @@ func NewDB(cfg *config.Config) (*pgxpool.Pool, error) {
 	}
 
 	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	return pool, nil
 }
 
+// NewReplicaDB creates a pool for cfg.DB.ReplicaDSN. Connections are opened
+// lazily, so an unreachable replica doesn't block startup; Router keeps
+// reads on the primary until the replica answers.
+func NewReplicaDB(cfg *config.Config) (*pgxpool.Pool, error) {
+	config, err := poolConfig(cfg, cfg.DB.ReplicaDSN, "replica")
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replica connection pool: %w", err)
+	}
+
+	return pool, nil
+}
+
+func poolConfig(cfg *config.Config, dsn, role string) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s connection string: %w", role, err)
+	}
+
+	config.MaxConns = int32(cfg.DB.MaxConns) //nolint:gosec
+	config.MinConns = int32(cfg.DB.MinConns) //nolint:gosec
+	config.MaxConnLifetime = time.Hour
+	config.MaxConnIdleTime = 30 * time.Minute
+	config.HealthCheckPeriod = time.Minute
+	config.ConnConfig.Tracer = &queryTracer{dbName: config.ConnConfig.Database, role: role}
+
+	return config, nil
+}
+
 func (db *DB) GetPool() *pgxpool.Pool {
 	return db.pool
 }
@@ -70,10 +102,46 @@ func NewTxManager(pool *pgxpool.Pool) *TxManager {
 	}
 }
 
-func (tm *TxManager) WithTx(
+type txKey struct{}
+
+func txFrom(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+// WithinTx implements repository.Transactor.
+func (tm *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFrom(ctx); ok {
+		return fn(ctx)
+	}
+
+	ctx, committed := repository.TrackAfterCommit(ctx)
+	err := tm.begin(ctx, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	if err != nil {
+		return err
+	}
+
+	committed()
+	return nil
+}
+
+// WithTx runs fn in the transaction carried by ctx, or in a new one.
+func (tm *TxManager) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	if tx, ok := txFrom(ctx); ok {
+		return fn(tx)
+	}
+	return tm.begin(ctx, fn)
+}
+
+func (tm *TxManager) begin(
 	ctx context.Context,
 	fn func(tx pgx.Tx) error,
 ) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "db.transaction")
+	defer func() { tracing.End(span, err) }()
+
 	tx, err := tm.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -82,7 +150,7 @@ func (tm *TxManager) WithTx(
 	defer func() {
 		if err != nil {
 			if rbErr := tx.Rollback(ctx); rbErr != nil {
-				logger.Error("transaction rollback failed", "error", rbErr)
+				logger.For(ctx, "repository/postgres").Error("transaction rollback failed", "error", rbErr)
 			}
 		}
 	}()
@@ -2,32 +2,31 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
 )
 
 type PRStatusRepository struct {
-	pool *pgxpool.Pool
+	db *Router
 }
 
-func NewPRStatusRepository(pool *pgxpool.Pool) *PRStatusRepository {
-	return &PRStatusRepository{pool: pool}
+func NewPRStatusRepository(db *Router) *PRStatusRepository {
+	return &PRStatusRepository{db: db}
 }
 
-func (r *PRStatusRepository) GetByCode(code string) (*domain.PRStatus, error) {
+func (r *PRStatusRepository) GetByCode(ctx context.Context, code string) (*domain.PRStatus, error) {
 	query := `
         SELECT id, code, name, description, created_at 
         FROM pr_statuses 
         WHERE code = $1
     `
 
-	ctx := context.Background()
 	var status domain.PRStatus
-	err := r.pool.QueryRow(ctx, query, code).Scan(
+	err := r.db.Read(ctx).QueryRow(ctx, query, code).Scan(
 		&status.ID,
 		&status.Code,
 		&status.Name,
@@ -35,7 +34,7 @@ func (r *PRStatusRepository) GetByCode(code string) (*domain.PRStatus, error) {
 		&status.CreatedAt,
 	)
 
-	if err == pgx.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("PR status not found: %s", code)
 	}
 	if err != nil {
@@ -45,16 +44,15 @@ func (r *PRStatusRepository) GetByCode(code string) (*domain.PRStatus, error) {
 	return &status, nil
 }
 
-func (r *PRStatusRepository) GetByID(id int) (*domain.PRStatus, error) {
+func (r *PRStatusRepository) GetByID(ctx context.Context, id int) (*domain.PRStatus, error) {
 	query := `
         SELECT id, code, name, description, created_at 
         FROM pr_statuses 
         WHERE id = $1
     `
 
-	ctx := context.Background()
 	var status domain.PRStatus
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Read(ctx).QueryRow(ctx, query, id).Scan(
 		&status.ID,
 		&status.Code,
 		&status.Name,
@@ -62,7 +60,7 @@ func (r *PRStatusRepository) GetByID(id int) (*domain.PRStatus, error) {
 		&status.CreatedAt,
 	)
 
-	if err == pgx.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("PR status not found with ID: %d", id)
 	}
 	if err != nil {
@@ -72,11 +70,10 @@ func (r *PRStatusRepository) GetByID(id int) (*domain.PRStatus, error) {
 	return &status, nil
 }
 
-func (r *PRStatusRepository) ListAll() ([]*domain.PRStatus, error) {
+func (r *PRStatusRepository) ListAll(ctx context.Context) ([]*domain.PRStatus, error) {
 	query := `SELECT id, code, name, description, created_at FROM pr_statuses ORDER BY id`
 
-	ctx := context.Background()
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.db.Read(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query PR statuses: %w", err)
 	}
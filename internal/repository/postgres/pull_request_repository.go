@@ -2,27 +2,25 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
 )
 
 type PullRequestRepository struct {
-	pool *pgxpool.Pool
-	tx   *TxManager
+	db *Router
+	tx *TxManager
 }
 
-func NewPullRequestRepository(pool *pgxpool.Pool, tx *TxManager) *PullRequestRepository {
-	return &PullRequestRepository{pool: pool, tx: tx}
+func NewPullRequestRepository(db *Router, tx *TxManager) *PullRequestRepository {
+	return &PullRequestRepository{db: db, tx: tx}
 }
 
-func (r *PullRequestRepository) Create(pr *domain.PullRequest) error {
-	ctx := context.Background()
-
+func (r *PullRequestRepository) Create(ctx context.Context, pr *domain.PullRequest) error {
 	return r.tx.WithTx(ctx, func(tx pgx.Tx) error {
 		statusQuery := `SELECT id FROM pr_statuses WHERE code = 'OPEN'`
 		var statusID int
@@ -31,10 +29,10 @@ func (r *PullRequestRepository) Create(pr *domain.PullRequest) error {
 		}
 
 		prQuery := `
-            INSERT INTO pull_requests (id, name, author_id, status_id, created_at)
-            VALUES ($1, $2, $3, $4, $5)
+            INSERT INTO pull_requests (id, name, author_id, team_id, status_id, created_at)
+            VALUES ($1, $2, $3, (SELECT id FROM teams WHERE name = NULLIF($4, '')), $5, $6)
         `
-		if _, err := tx.Exec(ctx, prQuery, pr.ID, pr.Name, pr.AuthorID, statusID, time.Now()); err != nil {
+		if _, err := tx.Exec(ctx, prQuery, pr.ID, pr.Name, pr.AuthorID, pr.TeamName, statusID, time.Now()); err != nil {
 			return fmt.Errorf("failed to create pull request: %w", err)
 		}
 
@@ -53,32 +51,33 @@ func (r *PullRequestRepository) Create(pr *domain.PullRequest) error {
 	})
 }
 
-func (r *PullRequestRepository) GetByID(id string) (*domain.PullRequest, error) {
+func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*domain.PullRequest, error) {
 	query := `
         SELECT 
-            pr.id, pr.name, pr.author_id, 
+            pr.id, pr.name, pr.author_id, COALESCE(t.name, ''),
             ps.code as status,
             pr.created_at, pr.merged_at
         FROM pull_requests pr
         JOIN pr_statuses ps ON pr.status_id = ps.id
+        LEFT JOIN teams t ON pr.team_id = t.id
         WHERE pr.id = $1
     `
 
-	ctx := context.Background()
 	var pr domain.PullRequest
 	var statusCode string
 	var mergedAt *time.Time
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	err := r.db.Read(ctx).QueryRow(ctx, query, id).Scan(
 		&pr.ID,
 		&pr.Name,
 		&pr.AuthorID,
+		&pr.TeamName,
 		&statusCode,
 		&pr.CreatedAt,
 		&mergedAt,
 	)
 
-	if err == pgx.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domain.ErrPullRequestNotFound
 	}
 	if err != nil {
@@ -97,9 +96,7 @@ func (r *PullRequestRepository) GetByID(id string) (*domain.PullRequest, error)
 	return &pr, nil
 }
 
-func (r *PullRequestRepository) Update(pr *domain.PullRequest) error {
-	ctx := context.Background()
-
+func (r *PullRequestRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
 	return r.tx.WithTx(ctx, func(tx pgx.Tx) error {
 		statusQuery := `SELECT id FROM pr_statuses WHERE code = $1`
 		var statusID int
@@ -109,10 +106,11 @@ func (r *PullRequestRepository) Update(pr *domain.PullRequest) error {
 
 		query := `
             UPDATE pull_requests 
-            SET name = $1, status_id = $2, updated_at = NOW(), merged_at = $3
+            SET name = $1, status_id = $2, updated_at = NOW(), merged_at = $3,
+                team_id = (SELECT id FROM teams WHERE name = NULLIF($5, ''))
             WHERE id = $4
         `
-		result, err := tx.Exec(ctx, query, pr.Name, statusID, pr.MergedAt, pr.ID)
+		result, err := tx.Exec(ctx, query, pr.Name, statusID, pr.MergedAt, pr.ID, pr.TeamName)
 		if err != nil {
 			return fmt.Errorf("failed to update pull request: %w", err)
 		}
@@ -129,23 +127,39 @@ func (r *PullRequestRepository) Update(pr *domain.PullRequest) error {
 	})
 }
 
-func (r *PullRequestRepository) ListByReviewer(userID string) ([]*domain.PullRequest, error) {
-	query := `
-        SELECT 
-            pr.id, pr.name, pr.author_id, 
+const pullRequestColumns = `
+            pr.id, pr.name, pr.author_id, COALESCE(t.name, ''),
             ps.code as status,
             pr.created_at, pr.merged_at
         FROM pull_requests pr
         JOIN pr_statuses ps ON pr.status_id = ps.id
+        LEFT JOIN teams t ON pr.team_id = t.id`
+
+func (r *PullRequestRepository) ListByReviewer(ctx context.Context, userID string) ([]*domain.PullRequest, error) {
+	query := `
+        SELECT` + pullRequestColumns + `
         JOIN pr_reviewers prr ON pr.id = prr.pr_id
         WHERE prr.user_id = $1
         ORDER BY pr.created_at DESC
     `
 
-	ctx := context.Background()
-	rows, err := r.pool.Query(ctx, query, userID)
+	return r.list(ctx, "PRs by reviewer", query, userID)
+}
+
+func (r *PullRequestRepository) ListOpenByTeam(ctx context.Context, teamName string) ([]*domain.PullRequest, error) {
+	query := `
+        SELECT` + pullRequestColumns + `
+        WHERE t.name = $1 AND ps.code = 'OPEN'
+        ORDER BY pr.created_at
+    `
+
+	return r.list(ctx, "open PRs by team", query, teamName)
+}
+
+func (r *PullRequestRepository) list(ctx context.Context, what, query string, args ...any) ([]*domain.PullRequest, error) {
+	rows, err := r.db.Read(ctx).Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query PRs by reviewer: %w", err)
+		return nil, fmt.Errorf("failed to query %s: %w", what, err)
 	}
 	defer rows.Close()
 
@@ -156,7 +170,7 @@ func (r *PullRequestRepository) ListByReviewer(userID string) ([]*domain.PullReq
 		var mergedAt *time.Time
 
 		if err := rows.Scan(
-			&pr.ID, &pr.Name, &pr.AuthorID, &statusCode, &pr.CreatedAt, &mergedAt,
+			&pr.ID, &pr.Name, &pr.AuthorID, &pr.TeamName, &statusCode, &pr.CreatedAt, &mergedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan PR: %w", err)
 		}
@@ -164,24 +178,31 @@ func (r *PullRequestRepository) ListByReviewer(userID string) ([]*domain.PullReq
 		pr.Status = statusCode
 		pr.MergedAt = mergedAt
 
+		prs = append(prs, &pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", what, err)
+	}
+	// Inside a transaction all queries share one connection, so the
+	// reviewers are only loaded once the rows above are drained.
+	rows.Close()
+
+	for _, pr := range prs {
 		reviewers, err := r.getReviewers(ctx, pr.ID)
 		if err != nil {
 			return nil, err
 		}
 		pr.AssignedReviewers = reviewers
-
-		prs = append(prs, &pr)
 	}
 
 	return prs, nil
 }
 
-func (r *PullRequestRepository) Exists(id string) (bool, error) {
+func (r *PullRequestRepository) Exists(ctx context.Context, id string) (bool, error) {
 	query := `SELECT COUNT(*) FROM pull_requests WHERE id = $1`
 
-	ctx := context.Background()
 	var count int
-	err := r.pool.QueryRow(ctx, query, id).Scan(&count)
+	err := r.db.Read(ctx).QueryRow(ctx, query, id).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check PR existence: %w", err)
 	}
@@ -192,7 +213,7 @@ func (r *PullRequestRepository) Exists(id string) (bool, error) {
 func (r *PullRequestRepository) getReviewers(ctx context.Context, prID string) ([]string, error) {
 	query := `SELECT user_id FROM pr_reviewers WHERE pr_id = $1`
 
-	rows, err := r.pool.Query(ctx, query, prID)
+	rows, err := r.db.Read(ctx).Query(ctx, query, prID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query reviewers: %w", err)
 	}
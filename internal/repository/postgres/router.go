@@ -0,0 +1,187 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/repository"
+)
+
+// Querier is what repositories run statements on: a pool, or the
+// transaction carried by the context.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Router picks the pool a query runs on. Writes always go to the primary;
+// read-only queries go to the replica when one is configured, healthy, and
+// the context doesn't require read-your-writes (see repository.WithPrimary).
+// A read that loses its replica connection is retried on the primary.
+type Router struct {
+	primary *pgxpool.Pool
+	replica *pgxpool.Pool
+
+	replicaHealthy atomic.Bool
+}
+
+// NewRouter returns a router over primary and an optional replica. Reads
+// stay on the primary until MonitorReplica has reached the replica.
+func NewRouter(primary, replica *pgxpool.Pool) *Router {
+	return &Router{primary: primary, replica: replica}
+}
+
+func (r *Router) Primary() *pgxpool.Pool {
+	return r.primary
+}
+
+// Replica returns the replica pool, or nil when none is configured.
+func (r *Router) Replica() *pgxpool.Pool {
+	return r.replica
+}
+
+func (r *Router) ReplicaHealthy() bool {
+	return r.replicaHealthy.Load()
+}
+
+// Write returns where a write runs: the transaction in ctx, if any, or the
+// primary.
+func (r *Router) Write(ctx context.Context) Querier {
+	if tx, ok := txFrom(ctx); ok {
+		return tx
+	}
+	return r.primary
+}
+
+// Read returns where a read-only query runs. Inside a transaction that is
+// the transaction itself, so it sees its own writes.
+func (r *Router) Read(ctx context.Context) Querier {
+	if tx, ok := txFrom(ctx); ok {
+		return tx
+	}
+	if r.replica == nil || !r.replicaHealthy.Load() || repository.PrimaryRequired(ctx) {
+		return r.primary
+	}
+	return replicaQuerier{r}
+}
+
+// MonitorReplica pings the replica every interval until ctx is done, moving
+// reads back to the primary while it is unreachable.
+func (r *Router) MonitorReplica(ctx context.Context, interval time.Duration) {
+	if r.replica == nil {
+		return
+	}
+
+	r.checkReplica(ctx, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkReplica(ctx, interval)
+		}
+	}
+}
+
+func (r *Router) checkReplica(ctx context.Context, timeout time.Duration) {
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := r.replica.Ping(pingCtx); err != nil {
+		r.markReplicaUnhealthy(ctx, err)
+		return
+	}
+	if !r.replicaHealthy.Swap(true) {
+		logger.For(ctx, "repository/postgres").Info("Replica is healthy, routing reads to it")
+	}
+}
+
+func (r *Router) markReplicaUnhealthy(ctx context.Context, err error) {
+	if r.replicaHealthy.Swap(false) {
+		logger.For(ctx, "repository/postgres").Warn("Replica is unhealthy, routing reads to the primary", "error", err)
+	}
+}
+
+// fallBack reports whether a read that failed on the replica with err
+// should be retried on the primary, and then routes reads away from the
+// replica until the next successful check.
+func (r *Router) fallBack(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || !isConnectionError(err) {
+		return false
+	}
+	r.markReplicaUnhealthy(ctx, err)
+	return true
+}
+
+// isConnectionError reports whether err means the connection failed rather
+// than the statement: the server could not be reached, went away, or is
+// shutting down.
+func isConnectionError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is connection_exception; 57P01-57P03 are the shutdown
+		// and startup errors.
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P0")
+	}
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) || errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || pgconn.SafeToRetry(err)
+}
+
+// replicaQuerier runs read-only statements on the replica and retries them
+// on the primary when the replica connection fails.
+type replicaQuerier struct {
+	r *Router
+}
+
+func (q replicaQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := q.r.replica.Exec(ctx, sql, args...)
+	if q.r.fallBack(ctx, err) {
+		return q.r.primary.Exec(ctx, sql, args...)
+	}
+	return tag, err
+}
+
+func (q replicaQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := q.r.replica.Query(ctx, sql, args...)
+	if q.r.fallBack(ctx, err) {
+		return q.r.primary.Query(ctx, sql, args...)
+	}
+	return rows, err
+}
+
+func (q replicaQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return replicaRow{q: q, ctx: ctx, sql: sql, args: args}
+}
+
+// replicaRow defers QueryRow until Scan, where pgx reports its errors.
+type replicaRow struct {
+	q    replicaQuerier
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (row replicaRow) Scan(dest ...any) error {
+	err := row.q.r.replica.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+	if row.q.r.fallBack(row.ctx, err) {
+		return row.q.r.primary.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+	}
+	return err
+}
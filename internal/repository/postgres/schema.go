@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/111zxc/pr-review-service/migrations"
+)
+
+// SchemaVersion is the latest embedded migration, the schema this binary is
+// written against.
+var SchemaVersion = migrations.Latest()
+
+// AppliedSchemaVersion returns the migration version recorded by goose. A
+// version counts as applied when its most recent row is marked applied, so
+// rolled back migrations are skipped the same way goose skips them.
+func AppliedSchemaVersion(ctx context.Context, pool *pgxpool.Pool) (int64, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT version_id, is_applied
+		FROM goose_db_version
+		ORDER BY id DESC`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[int64]bool)
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+		)
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, err
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		if applied {
+			return version, nil
+		}
+	}
+
+	return 0, rows.Err()
+}
@@ -3,20 +3,18 @@ package postgres
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
 	"github.com/111zxc/pr-review-service/internal/domain"
 )
 
 type StatsRepository struct {
-	pool *pgxpool.Pool
+	db *Router
 }
 
-func NewStatsRepository(pool *pgxpool.Pool) *StatsRepository {
-	return &StatsRepository{pool: pool}
+func NewStatsRepository(db *Router) *StatsRepository {
+	return &StatsRepository{db: db}
 }
 
-func (r *StatsRepository) GetEventStats() (*domain.StatsResponse, error) {
+func (r *StatsRepository) GetEventStats(ctx context.Context) (*domain.StatsResponse, error) {
 	query := `
         SELECT 
             event_type,
@@ -26,8 +24,7 @@ func (r *StatsRepository) GetEventStats() (*domain.StatsResponse, error) {
         ORDER BY count DESC
     `
 
-	ctx := context.Background()
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.db.Read(ctx).Query(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -54,6 +51,7 @@ func (r *StatsRepository) GetEventStats() (*domain.StatsResponse, error) {
 	allEventTypes := []domain.EventType{
 		domain.EventTypePRCreated,
 		domain.EventTypePRMerged,
+		domain.EventTypePRClosed,
 		domain.EventTypeReviewerAssigned,
 		domain.EventTypeReviewerReassigned,
 		domain.EventTypeReviewerUnassigned,
@@ -67,3 +65,33 @@ func (r *StatsRepository) GetEventStats() (*domain.StatsResponse, error) {
 
 	return stats, nil
 }
+
+func (r *StatsRepository) GetOpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
+	query := `
+        SELECT t.name, COUNT(*)
+        FROM pr_reviewers prr
+        JOIN pull_requests pr ON pr.id = prr.pr_id
+        JOIN pr_statuses ps ON ps.id = pr.status_id
+        JOIN teams t ON t.id = pr.team_id
+        WHERE ps.code = 'OPEN' AND t.deleted_at IS NULL
+        GROUP BY t.name
+    `
+
+	rows, err := r.db.Read(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var teamName string
+		var count int
+		if err := rows.Scan(&teamName, &count); err != nil {
+			return nil, err
+		}
+		counts[teamName] = count
+	}
+
+	return counts, nil
+}
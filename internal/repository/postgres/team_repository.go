@@ -2,35 +2,56 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
 )
 
 type TeamRepository struct {
-	pool *pgxpool.Pool
-	tx   *TxManager
+	db *Router
+	tx *TxManager
 }
 
-func NewTeamRepository(pool *pgxpool.Pool, tx *TxManager) *TeamRepository {
-	return &TeamRepository{pool: pool, tx: tx}
+func NewTeamRepository(db *Router, tx *TxManager) *TeamRepository {
+	return &TeamRepository{db: db, tx: tx}
 }
 
-func (r *TeamRepository) Create(team *domain.Team) error {
-	ctx := context.Background()
-
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
 	return r.tx.WithTx(ctx, func(tx pgx.Tx) error {
-		teamQuery := `INSERT INTO teams (id, name) VALUES ($1, $2)`
-		if _, err := tx.Exec(ctx, teamQuery, team.Name, team.Name); err != nil {
+		// A soft-deleted team keeps its name until it is restored.
+		var taken bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM teams WHERE name = $1)`, team.Name).Scan(&taken); err != nil {
+			return fmt.Errorf("failed to check team name: %w", err)
+		}
+		if taken {
+			return domain.ErrTeamExists
+		}
+
+		var parentID string
+		if team.ParentName != "" {
+			err := tx.QueryRow(ctx, `SELECT id FROM teams WHERE name = $1 AND deleted_at IS NULL`, team.ParentName).Scan(&parentID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrTeamNotFound
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get parent team: %w", err)
+			}
+		}
+
+		if team.ID == "" {
+			team.ID = domain.NewTeamID()
+		}
+		teamQuery := `INSERT INTO teams (id, name, parent_id) VALUES ($1, $2, NULLIF($3, ''))`
+		if _, err := tx.Exec(ctx, teamQuery, team.ID, team.Name, parentID); err != nil {
 			return fmt.Errorf("failed to create team: %w", err)
 		}
 
 		for _, member := range team.Members {
-			memberQuery := `INSERT INTO team_members (team_id, user_id) VALUES ($1, $2)`
-			if _, err := tx.Exec(ctx, memberQuery, team.Name, member.UserID); err != nil {
+			memberQuery := `INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, COALESCE(NULLIF($3, ''), 'member'))`
+			if _, err := tx.Exec(ctx, memberQuery, team.ID, member.UserID, member.Role); err != nil {
 				return fmt.Errorf("failed to add team member: %w", err)
 			}
 		}
@@ -39,14 +60,24 @@ func (r *TeamRepository) Create(team *domain.Team) error {
 	})
 }
 
-func (r *TeamRepository) GetByName(name string) (*domain.Team, error) {
-	ctx := context.Background()
+// teamColumns reads a live team with the name of its live parent.
+const teamColumns = `
+        SELECT t.id, t.name, COALESCE(p.name, '')
+        FROM teams t
+        LEFT JOIN teams p ON p.id = t.parent_id AND p.deleted_at IS NULL`
 
-	teamQuery := `SELECT name FROM teams WHERE name = $1 AND deleted_at IS NULL`
+func (r *TeamRepository) GetByName(ctx context.Context, name string) (*domain.Team, error) {
+	return r.get(ctx, teamColumns+` WHERE t.name = $1 AND t.deleted_at IS NULL`, name)
+}
 
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	return r.get(ctx, teamColumns+` WHERE t.id = $1 AND t.deleted_at IS NULL`, id)
+}
+
+func (r *TeamRepository) get(ctx context.Context, teamQuery, arg string) (*domain.Team, error) {
 	var team domain.Team
-	err := r.pool.QueryRow(ctx, teamQuery, name).Scan(&team.Name)
-	if err == pgx.ErrNoRows {
+	err := r.db.Read(ctx).QueryRow(ctx, teamQuery, arg).Scan(&team.ID, &team.Name, &team.ParentName)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domain.ErrTeamNotFound
 	}
 	if err != nil {
@@ -54,13 +85,13 @@ func (r *TeamRepository) GetByName(name string) (*domain.Team, error) {
 	}
 
 	membersQuery := `
-        SELECT u.id, u.username, u.is_active
+        SELECT u.id, u.username, u.is_active, tm.role
         FROM users u
         JOIN team_members tm ON u.id = tm.user_id
         WHERE tm.team_id = $1 AND u.deleted_at IS NULL
     `
 
-	rows, err := r.pool.Query(ctx, membersQuery, name)
+	rows, err := r.db.Read(ctx).Query(ctx, membersQuery, team.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query team members: %w", err)
 	}
@@ -68,7 +99,7 @@ func (r *TeamRepository) GetByName(name string) (*domain.Team, error) {
 
 	for rows.Next() {
 		var member domain.TeamMember
-		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive); err != nil {
+		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.Role); err != nil {
 			return nil, fmt.Errorf("failed to scan team member: %w", err)
 		}
 		team.Members = append(team.Members, member)
@@ -77,15 +108,219 @@ func (r *TeamRepository) GetByName(name string) (*domain.Team, error) {
 	return &team, nil
 }
 
-func (r *TeamRepository) Exists(name string) (bool, error) {
+func (r *TeamRepository) Exists(ctx context.Context, name string) (bool, error) {
 	query := `SELECT COUNT(*) FROM teams WHERE name = $1 AND deleted_at IS NULL`
 
-	ctx := context.Background()
 	var count int
-	err := r.pool.QueryRow(ctx, query, name).Scan(&count)
+	err := r.db.Read(ctx).QueryRow(ctx, query, name).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check team existence: %w", err)
 	}
 
 	return count > 0, nil
 }
+
+func (r *TeamRepository) AddMembers(ctx context.Context, teamName string, userIDs []string) error {
+	return r.tx.WithTx(ctx, func(tx pgx.Tx) error {
+		var teamID string
+		err := tx.QueryRow(ctx, `SELECT id FROM teams WHERE name = $1 AND deleted_at IS NULL`, teamName).Scan(&teamID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrTeamNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		for _, userID := range userIDs {
+			memberQuery := `INSERT INTO team_members (team_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+			if _, err := tx.Exec(ctx, memberQuery, teamID, userID); err != nil {
+				return fmt.Errorf("failed to add team member: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamName, userID string) error {
+	query := `
+        DELETE FROM team_members
+        WHERE team_id = (SELECT id FROM teams WHERE name = $1 AND deleted_at IS NULL) AND user_id = $2
+    `
+
+	result, err := r.db.Write(ctx).Exec(ctx, query, teamName, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if result.RowsAffected() > 0 {
+		return nil
+	}
+
+	exists, err := r.Exists(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrTeamNotFound
+	}
+	return domain.ErrNotTeamMember
+}
+
+func (r *TeamRepository) SetRole(ctx context.Context, teamName, userID string, role domain.TeamRole) error {
+	query := `
+        UPDATE team_members SET role = $3
+        WHERE team_id = (SELECT id FROM teams WHERE name = $1 AND deleted_at IS NULL) AND user_id = $2
+    `
+
+	result, err := r.db.Write(ctx).Exec(ctx, query, teamName, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to set member role: %w", err)
+	}
+	if result.RowsAffected() > 0 {
+		return nil
+	}
+
+	exists, err := r.Exists(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrTeamNotFound
+	}
+	return domain.ErrNotTeamMember
+}
+
+func (r *TeamRepository) Delete(ctx context.Context, name string) error {
+	query := `UPDATE teams SET deleted_at = NOW(), updated_at = NOW() WHERE name = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.Write(ctx).Exec(ctx, query, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrTeamNotFound
+	}
+	return nil
+}
+
+func (r *TeamRepository) Restore(ctx context.Context, name string) error {
+	query := `UPDATE teams SET deleted_at = NULL, updated_at = NOW() WHERE name = $1 AND deleted_at IS NOT NULL`
+
+	result, err := r.db.Write(ctx).Exec(ctx, query, name)
+	if err != nil {
+		return fmt.Errorf("failed to restore team: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrTeamNotFound
+	}
+	return nil
+}
+
+func (r *TeamRepository) Rename(ctx context.Context, name, newName string) error {
+	return r.tx.WithTx(ctx, func(tx pgx.Tx) error {
+		var taken bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM teams WHERE name = $1)`, newName).Scan(&taken); err != nil {
+			return fmt.Errorf("failed to check team name: %w", err)
+		}
+		if taken {
+			return domain.ErrTeamExists
+		}
+
+		query := `UPDATE teams SET name = $2, updated_at = NOW() WHERE name = $1 AND deleted_at IS NULL`
+		result, err := tx.Exec(ctx, query, name, newName)
+		if err != nil {
+			return fmt.Errorf("failed to rename team: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return domain.ErrTeamNotFound
+		}
+		return nil
+	})
+}
+
+func (r *TeamRepository) SetParent(ctx context.Context, name, parentName string) error {
+	return r.tx.WithTx(ctx, func(tx pgx.Tx) error {
+		var parentID string
+		if parentName != "" {
+			err := tx.QueryRow(ctx, `SELECT id FROM teams WHERE name = $1 AND deleted_at IS NULL`, parentName).Scan(&parentID)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrTeamNotFound
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get parent team: %w", err)
+			}
+		}
+
+		query := `UPDATE teams SET parent_id = NULLIF($2, ''), updated_at = NOW() WHERE name = $1 AND deleted_at IS NULL`
+		result, err := tx.Exec(ctx, query, name, parentID)
+		if err != nil {
+			return fmt.Errorf("failed to set parent team: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return domain.ErrTeamNotFound
+		}
+		return nil
+	})
+}
+
+func (r *TeamRepository) ListSubTeams(ctx context.Context, name string) ([]string, error) {
+	var teamID string
+	err := r.db.Read(ctx).QueryRow(ctx, `SELECT id FROM teams WHERE name = $1 AND deleted_at IS NULL`, name).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	query := `SELECT name FROM teams WHERE parent_id = $1 AND deleted_at IS NULL ORDER BY name`
+	rows, err := r.db.Read(ctx).Query(ctx, query, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sub-teams: %w", err)
+	}
+	defer rows.Close()
+
+	var children []string
+	for rows.Next() {
+		var child string
+		if err := rows.Scan(&child); err != nil {
+			return nil, fmt.Errorf("failed to scan sub-team: %w", err)
+		}
+		children = append(children, child)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sub-teams: %w", err)
+	}
+	return children, nil
+}
+
+func (r *TeamRepository) List(ctx context.Context) ([]*domain.Team, error) {
+	rows, err := r.db.Read(ctx).Query(ctx, `SELECT name FROM teams WHERE deleted_at IS NULL ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read teams: %w", err)
+	}
+	rows.Close()
+
+	teams := make([]*domain.Team, 0, len(names))
+	for _, name := range names {
+		team, err := r.GetByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/tracing"
+)
+
+// queryTracer turns every statement executed through pgx into a client span
+// and a debug log line on the request-scoped logger.
+type queryTracer struct {
+	dbName string
+	role   string
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	span      trace.Span
+	operation string
+	at        time.Time
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := sqlOperation(data.SQL)
+
+	ctx, span := tracing.Tracer().Start(ctx, operation+" "+t.dbName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.name", t.dbName),
+			attribute.String("db.operation", operation),
+			attribute.String("db.role", t.role),
+			attribute.String("db.statement", strings.Join(strings.Fields(data.SQL), " ")),
+		),
+	)
+
+	return context.WithValue(ctx, queryStartKey{}, &queryStart{span: span, operation: operation, at: time.Now()})
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(*queryStart)
+	if !ok {
+		return
+	}
+
+	rows := data.CommandTag.RowsAffected()
+	start.span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+	tracing.End(start.span, data.Err)
+
+	args := []any{
+		"operation", start.operation,
+		"role", t.role,
+		"rows", rows,
+		"duration_ms", float64(time.Since(start.at).Microseconds()) / 1000,
+	}
+	if data.Err != nil {
+		args = append(args, "error", data.Err)
+	}
+	logger.For(ctx, "repository/postgres").Debug("SQL query", args...)
+}
+
+func sqlOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -2,34 +2,62 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
 )
 
+// userTeams selects the names of the teams of the user u as a sorted array,
+// followed by the user's roles in them in the same order.
+const userTeams = `
+    ARRAY(
+        SELECT t.name
+        FROM team_members tm
+        JOIN teams t ON tm.team_id = t.id
+        WHERE tm.user_id = u.id AND t.deleted_at IS NULL
+        ORDER BY t.name
+    ),
+    ARRAY(
+        SELECT tm.role
+        FROM team_members tm
+        JOIN teams t ON tm.team_id = t.id
+        WHERE tm.user_id = u.id AND t.deleted_at IS NULL
+        ORDER BY t.name
+    )`
+
+func setRoles(user *domain.User, roles []string) {
+	if len(user.Teams) == 0 {
+		return
+	}
+	user.Roles = make(map[string]domain.TeamRole, len(user.Teams))
+	for i, team := range user.Teams {
+		user.Roles[team] = domain.TeamRole(roles[i])
+	}
+}
+
 type UserRepository struct {
-	pool *pgxpool.Pool
+	db *Router
 }
 
-func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
-	return &UserRepository{pool: pool}
+func NewUserRepository(db *Router) *UserRepository {
+	return &UserRepository{db: db}
 }
 
-func (r *UserRepository) Create(user *domain.User) error {
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `
         INSERT INTO users (id, username, is_active) 
         VALUES ($1, $2, $3)
         ON CONFLICT (id) DO UPDATE SET
             username = EXCLUDED.username,
             is_active = EXCLUDED.is_active,
+            deleted_at = NULL,
             updated_at = NOW()
     `
 
-	ctx := context.Background()
-	_, err := r.pool.Exec(ctx, query, user.ID, user.Username, user.IsActive)
+	_, err := r.db.Write(ctx).Exec(ctx, query, user.ID, user.Username, user.IsActive)
 	if err != nil {
 		return fmt.Errorf("failed to create/update user: %w", err)
 	}
@@ -37,46 +65,39 @@ func (r *UserRepository) Create(user *domain.User) error {
 	return nil
 }
 
-func (r *UserRepository) GetByID(id string) (*domain.User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
 	query := `
-        SELECT u.id, u.username, u.is_active, t.name as team_name
+        SELECT u.id, u.username, u.is_active,` + userTeams + `
         FROM users u
-        LEFT JOIN team_members tm ON u.id = tm.user_id
-        LEFT JOIN teams t ON tm.team_id = t.id
         WHERE u.id = $1 AND u.deleted_at IS NULL
     `
 
-	ctx := context.Background()
 	var user domain.User
-	var teamName *string
+	var roles []string
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.IsActive, &teamName,
+	err := r.db.Read(ctx).QueryRow(ctx, query, id).Scan(
+		&user.ID, &user.Username, &user.IsActive, &user.Teams, &roles,
 	)
 
-	if err == pgx.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domain.ErrUserNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
-
-	if teamName != nil {
-		user.TeamName = *teamName
-	}
+	setRoles(&user, roles)
 
 	return &user, nil
 }
 
-func (r *UserRepository) Update(user *domain.User) error {
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `
         UPDATE users 
         SET username = $1, is_active = $2, updated_at = NOW()
         WHERE id = $3 AND deleted_at IS NULL
     `
 
-	ctx := context.Background()
-	result, err := r.pool.Exec(ctx, query, user.Username, user.IsActive, user.ID)
+	result, err := r.db.Write(ctx).Exec(ctx, query, user.Username, user.IsActive, user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -88,17 +109,16 @@ func (r *UserRepository) Update(user *domain.User) error {
 	return nil
 }
 
-func (r *UserRepository) GetByTeam(teamName string) ([]*domain.User, error) {
+func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]*domain.User, error) {
 	query := `
-        SELECT u.id, u.username, u.is_active
+        SELECT u.id, u.username, u.is_active,` + userTeams + `
         FROM users u
         JOIN team_members tm ON u.id = tm.user_id
         JOIN teams t ON tm.team_id = t.id
-        WHERE t.name = $1 AND u.deleted_at IS NULL AND u.is_active = true
+        WHERE t.name = $1 AND t.deleted_at IS NULL AND u.deleted_at IS NULL AND u.is_active = true
     `
 
-	ctx := context.Background()
-	rows, err := r.pool.Query(ctx, query, teamName)
+	rows, err := r.db.Read(ctx).Query(ctx, query, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query team users: %w", err)
 	}
@@ -107,12 +127,175 @@ func (r *UserRepository) GetByTeam(teamName string) ([]*domain.User, error) {
 	var users []*domain.User
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.IsActive); err != nil {
+		var roles []string
+		if err := rows.Scan(&user.ID, &user.Username, &user.IsActive, &user.Teams, &roles); err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
-		user.TeamName = teamName
+		setRoles(&user, roles)
 		users = append(users, &user)
 	}
 
 	return users, nil
 }
+
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int, error) {
+	where := `
+        FROM users u
+        WHERE u.deleted_at IS NULL
+            AND ($1 = '' OR u.username = $1)
+            AND ($2::boolean IS NULL OR u.is_active = $2)
+            AND ($3 = '' OR starts_with(u.username, $3))
+            AND ($4 = '' OR EXISTS (
+                SELECT 1
+                FROM team_members tm
+                JOIN teams t ON tm.team_id = t.id
+                WHERE tm.user_id = u.id AND t.name = $4 AND t.deleted_at IS NULL
+            ))`
+	args := []any{filter.Username, filter.IsActive, filter.UsernamePrefix, filter.Team}
+
+	var total int
+	err := r.db.Read(ctx).QueryRow(ctx, `SELECT COUNT(*)`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query := `SELECT u.id, u.username, u.is_active,` + userTeams + where + `
+        ORDER BY u.id
+        OFFSET $5 LIMIT NULLIF($6, 0)`
+	rows, err := r.db.Read(ctx).Query(ctx, query, append(args, filter.Offset, filter.Limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		var user domain.User
+		var roles []string
+		if err := rows.Scan(&user.ID, &user.Username, &user.IsActive, &user.Teams, &roles); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		setRoles(&user, roles)
+		users = append(users, &user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+func (r *UserRepository) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	query := `
+        SELECT rv.user_id, COUNT(*)
+        FROM pr_reviewers rv
+        JOIN pull_requests pr ON rv.pr_id = pr.id
+        JOIN pr_statuses ps ON pr.status_id = ps.id
+        WHERE rv.user_id = ANY($1) AND ps.code = 'OPEN'
+        GROUP BY rv.user_id
+    `
+
+	rows, err := r.db.Read(ctx).Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open reviews: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan open reviews: %w", err)
+		}
+		counts[userID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read open reviews: %w", err)
+	}
+
+	return counts, nil
+}
+
+// Delete soft-deletes the user and drops their team memberships; Create
+// brings the row back. Callers run it in a transaction.
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	db := r.db.Write(ctx)
+
+	result, err := db.Exec(ctx, `
+        UPDATE users
+        SET is_active = false, deleted_at = NOW(), updated_at = NOW()
+        WHERE id = $1 AND deleted_at IS NULL
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	if _, err := db.Exec(ctx, `DELETE FROM team_members WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to drop team memberships: %w", err)
+	}
+	return nil
+}
+
+// Erase moves every reference from userID to a new soft-deleted user named
+// pseudonym before deleting the old row, so neither the RESTRICT on authors
+// nor the SET NULL on events comes into play. Callers run it in a
+// transaction.
+func (r *UserRepository) Erase(ctx context.Context, userID, pseudonym string) (domain.ErasedRecords, error) {
+	db := r.db.Write(ctx)
+	var erased domain.ErasedRecords
+
+	result, err := db.Exec(ctx, `
+        INSERT INTO users (id, username, is_active, deleted_at)
+        SELECT $2, $2, false, NOW()
+        FROM users
+        WHERE id = $1 AND deleted_at IS NULL
+    `, userID, pseudonym)
+	if err != nil {
+		return erased, fmt.Errorf("failed to create pseudonymous user: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return erased, domain.ErrUserNotFound
+	}
+
+	result, err = db.Exec(ctx, `UPDATE pull_requests SET author_id = $2 WHERE author_id = $1`, userID, pseudonym)
+	if err != nil {
+		return erased, fmt.Errorf("failed to move authored pull requests: %w", err)
+	}
+	erased.PullRequests = int(result.RowsAffected())
+
+	result, err = db.Exec(ctx, `UPDATE pr_reviewers SET user_id = $2 WHERE user_id = $1`, userID, pseudonym)
+	if err != nil {
+		return erased, fmt.Errorf("failed to move reviews: %w", err)
+	}
+	erased.PastReviews = int(result.RowsAffected())
+
+	result, err = db.Exec(ctx, `
+        UPDATE events
+        SET user_id = CASE WHEN user_id = $1::text THEN $2::text ELSE user_id END,
+            additional_data = additional_data
+                || CASE WHEN additional_data->>'old_user_id' = $1::text
+                    THEN jsonb_build_object('old_user_id', $2::text) ELSE '{}'::jsonb END
+                || CASE WHEN additional_data->>'new_user_id' = $1::text
+                    THEN jsonb_build_object('new_user_id', $2::text) ELSE '{}'::jsonb END
+        WHERE user_id = $1::text
+            OR additional_data->>'old_user_id' = $1::text
+            OR additional_data->>'new_user_id' = $1::text
+    `, userID, pseudonym)
+	if err != nil {
+		return erased, fmt.Errorf("failed to scrub events: %w", err)
+	}
+	erased.Events = int(result.RowsAffected())
+
+	if _, err := db.Exec(ctx, `DELETE FROM team_members WHERE user_id = $1`, userID); err != nil {
+		return erased, fmt.Errorf("failed to drop team memberships: %w", err)
+	}
+	if _, err := db.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+		return erased, fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	return erased, nil
+}
@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type EventsRepository struct {
+	db *sql.DB
+}
+
+func NewEventsRepository(db *sql.DB) *EventsRepository {
+	return &EventsRepository{db: db}
+}
+
+// CreateEvent stores AdditionalData as JSON text; json() validates and
+// minifies it the way JSONB does in Postgres.
+func (r *EventsRepository) CreateEvent(ctx context.Context, event *domain.Event) error {
+	query := `
+        INSERT INTO events (event_type, pr_id, user_id, additional_data, created_at)
+        VALUES (?, ?, ?, json(?), ?)
+    `
+
+	var data sql.NullString
+	if event.AdditionalData != nil {
+		data = sql.NullString{String: string(event.AdditionalData), Valid: true}
+	}
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, event.EventType, event.PRID, event.UserID, data, time.Now().UTC())
+	return err
+}
+
+func (r *EventsRepository) GetEventsByType(ctx context.Context, eventType domain.EventType, limit int) ([]domain.Event, error) {
+	query := `
+        SELECT id, event_type, pr_id, user_id, additional_data, created_at
+        FROM events
+        WHERE event_type = ?
+        ORDER BY created_at DESC, id DESC
+        LIMIT ?
+    `
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, eventType, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.Event
+	for rows.Next() {
+		var event domain.Event
+		var prID, userID, data sql.NullString
+		if err := rows.Scan(&event.ID, &event.EventType, &prID, &userID, &data, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.PRID = prID.String
+		event.UserID = userID.String
+		if data.Valid {
+			event.AdditionalData = json.RawMessage(data.String)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *EventsRepository) GetEventCountsByType(ctx context.Context) (map[domain.EventType]int, error) {
+	return countEventsByType(ctx, r.db)
+}
+
+func countEventsByType(ctx context.Context, db *sql.DB) (map[domain.EventType]int, error) {
+	query := `
+        SELECT event_type, COUNT(*)
+        FROM events
+        GROUP BY event_type
+    `
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[domain.EventType]int)
+	for rows.Next() {
+		var eventType string
+		var count int
+		if err := rows.Scan(&eventType, &count); err != nil {
+			return nil, err
+		}
+		counts[domain.EventType(eventType)] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type PRStatusRepository struct {
+	db *sql.DB
+}
+
+func NewPRStatusRepository(db *sql.DB) *PRStatusRepository {
+	return &PRStatusRepository{db: db}
+}
+
+func (r *PRStatusRepository) GetByCode(ctx context.Context, code string) (*domain.PRStatus, error) {
+	query := `
+        SELECT id, code, name, description, created_at
+        FROM pr_statuses
+        WHERE code = ?
+    `
+
+	status, err := scanPRStatus(conn(ctx, r.db).QueryRowContext(ctx, query, code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("PR status not found: %s", code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR status: %w", err)
+	}
+
+	return status, nil
+}
+
+func (r *PRStatusRepository) GetByID(ctx context.Context, id int) (*domain.PRStatus, error) {
+	query := `
+        SELECT id, code, name, description, created_at
+        FROM pr_statuses
+        WHERE id = ?
+    `
+
+	status, err := scanPRStatus(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("PR status not found with ID: %d", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PR status: %w", err)
+	}
+
+	return status, nil
+}
+
+func (r *PRStatusRepository) ListAll(ctx context.Context) ([]*domain.PRStatus, error) {
+	query := `SELECT id, code, name, description, created_at FROM pr_statuses ORDER BY id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PR statuses: %w", err)
+	}
+	defer rows.Close()
+
+	var statuses []*domain.PRStatus
+	for rows.Next() {
+		status, err := scanPRStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PR status: %w", err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, rows.Err()
+}
+
+func scanPRStatus(row rowScanner) (*domain.PRStatus, error) {
+	var status domain.PRStatus
+	var description sql.NullString
+
+	if err := row.Scan(&status.ID, &status.Code, &status.Name, &description, &status.CreatedAt); err != nil {
+		return nil, err
+	}
+	status.Description = description.String
+
+	return &status, nil
+}
@@ -0,0 +1,240 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type PullRequestRepository struct {
+	db *sql.DB
+	tx *TxManager
+}
+
+func NewPullRequestRepository(db *sql.DB, tx *TxManager) *PullRequestRepository {
+	return &PullRequestRepository{db: db, tx: tx}
+}
+
+func (r *PullRequestRepository) Create(ctx context.Context, pr *domain.PullRequest) error {
+	return r.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		statusQuery := `SELECT id FROM pr_statuses WHERE code = 'OPEN'`
+		var statusID int
+		if err := tx.QueryRowContext(ctx, statusQuery).Scan(&statusID); err != nil {
+			return fmt.Errorf("failed to get OPEN status: %w", err)
+		}
+
+		now := time.Now().UTC()
+		prQuery := `
+            INSERT INTO pull_requests (id, name, author_id, team_id, status_id, created_at)
+            VALUES (?, ?, ?, (SELECT id FROM teams WHERE name = NULLIF(?, '')), ?, ?)
+        `
+		if _, err := tx.ExecContext(ctx, prQuery, pr.ID, pr.Name, pr.AuthorID, pr.TeamName, statusID, now); err != nil {
+			return fmt.Errorf("failed to create pull request: %w", err)
+		}
+
+		if err := insertReviewers(ctx, tx, pr.ID, pr.AssignedReviewers); err != nil {
+			return err
+		}
+
+		pr.Status = domain.PRStatusOpen
+		pr.CreatedAt = &now
+
+		return nil
+	})
+}
+
+func (r *PullRequestRepository) GetByID(ctx context.Context, id string) (*domain.PullRequest, error) {
+	query := `
+        SELECT
+            pr.id, pr.name, pr.author_id, COALESCE(t.name, ''),
+            ps.code AS status,
+            pr.created_at, pr.merged_at
+        FROM pull_requests pr
+        JOIN pr_statuses ps ON pr.status_id = ps.id
+        LEFT JOIN teams t ON pr.team_id = t.id
+        WHERE pr.id = ?
+    `
+
+	pr, err := scanPullRequest(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrPullRequestNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	reviewers, err := r.getReviewers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pr.AssignedReviewers = reviewers
+
+	return pr, nil
+}
+
+func (r *PullRequestRepository) Update(ctx context.Context, pr *domain.PullRequest) error {
+	return r.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		statusQuery := `SELECT id FROM pr_statuses WHERE code = ?`
+		var statusID int
+		if err := tx.QueryRowContext(ctx, statusQuery, pr.Status).Scan(&statusID); err != nil {
+			return fmt.Errorf("failed to get status: %w", err)
+		}
+
+		var mergedAt sql.NullTime
+		if pr.MergedAt != nil {
+			mergedAt = sql.NullTime{Time: pr.MergedAt.UTC(), Valid: true}
+		}
+
+		query := `
+            UPDATE pull_requests
+            SET name = ?, status_id = ?, updated_at = CURRENT_TIMESTAMP, merged_at = ?,
+                team_id = (SELECT id FROM teams WHERE name = NULLIF(?, ''))
+            WHERE id = ?
+        `
+		result, err := tx.ExecContext(ctx, query, pr.Name, statusID, mergedAt, pr.TeamName, pr.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update pull request: %w", err)
+		}
+
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to update pull request: %w", err)
+		} else if n == 0 {
+			return domain.ErrPullRequestNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM pr_reviewers WHERE pr_id = ?`, pr.ID); err != nil {
+			return fmt.Errorf("failed to delete reviewers: %w", err)
+		}
+
+		return insertReviewers(ctx, tx, pr.ID, pr.AssignedReviewers)
+	})
+}
+
+const pullRequestColumns = `
+            pr.id, pr.name, pr.author_id, COALESCE(t.name, ''),
+            ps.code AS status,
+            pr.created_at, pr.merged_at
+        FROM pull_requests pr
+        JOIN pr_statuses ps ON pr.status_id = ps.id
+        LEFT JOIN teams t ON pr.team_id = t.id`
+
+func (r *PullRequestRepository) ListByReviewer(ctx context.Context, userID string) ([]*domain.PullRequest, error) {
+	query := `
+        SELECT` + pullRequestColumns + `
+        JOIN pr_reviewers prr ON pr.id = prr.pr_id
+        WHERE prr.user_id = ?
+        ORDER BY pr.created_at DESC
+    `
+
+	return r.list(ctx, "PRs by reviewer", query, userID)
+}
+
+func (r *PullRequestRepository) ListOpenByTeam(ctx context.Context, teamName string) ([]*domain.PullRequest, error) {
+	query := `
+        SELECT` + pullRequestColumns + `
+        WHERE t.name = ? AND ps.code = 'OPEN'
+        ORDER BY pr.created_at
+    `
+
+	return r.list(ctx, "open PRs by team", query, teamName)
+}
+
+func (r *PullRequestRepository) list(ctx context.Context, what, query string, args ...any) ([]*domain.PullRequest, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", what, err)
+	}
+	defer rows.Close()
+
+	var prs []*domain.PullRequest
+	for rows.Next() {
+		pr, err := scanPullRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan PR: %w", err)
+		}
+		prs = append(prs, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", what, err)
+	}
+
+	// Reviewers are loaded after the cursor is closed so a single-connection
+	// database doesn't deadlock on the nested query.
+	for _, pr := range prs {
+		reviewers, err := r.getReviewers(ctx, pr.ID)
+		if err != nil {
+			return nil, err
+		}
+		pr.AssignedReviewers = reviewers
+	}
+
+	return prs, nil
+}
+
+func (r *PullRequestRepository) Exists(ctx context.Context, id string) (bool, error) {
+	query := `SELECT COUNT(*) FROM pull_requests WHERE id = ?`
+
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check PR existence: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *PullRequestRepository) getReviewers(ctx context.Context, prID string) ([]string, error) {
+	query := `SELECT user_id FROM pr_reviewers WHERE pr_id = ?`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, prID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	var reviewers []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		reviewers = append(reviewers, userID)
+	}
+
+	return reviewers, rows.Err()
+}
+
+func insertReviewers(ctx context.Context, tx *sql.Tx, prID string, reviewers []string) error {
+	for _, reviewerID := range reviewers {
+		query := `INSERT INTO pr_reviewers (pr_id, user_id) VALUES (?, ?)`
+		if _, err := tx.ExecContext(ctx, query, prID, reviewerID); err != nil {
+			return fmt.Errorf("failed to add reviewer: %w", err)
+		}
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPullRequest(row rowScanner) (*domain.PullRequest, error) {
+	var pr domain.PullRequest
+	var createdAt time.Time
+	var mergedAt sql.NullTime
+
+	if err := row.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.TeamName, &pr.Status, &createdAt, &mergedAt); err != nil {
+		return nil, err
+	}
+
+	pr.CreatedAt = &createdAt
+	if mergedAt.Valid {
+		pr.MergedAt = &mergedAt.Time
+	}
+
+	return &pr, nil
+}
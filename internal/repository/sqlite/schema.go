@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/111zxc/pr-review-service/migrations"
+)
+
+// SchemaVersion is the latest embedded SQLite migration.
+var SchemaVersion = migrations.LatestSQLite()
+
+// AppliedSchemaVersion returns the migration version recorded by goose,
+// skipping rolled back versions the same way goose does.
+func AppliedSchemaVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT version_id, is_applied
+		FROM goose_db_version
+		ORDER BY id DESC`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[int64]bool)
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+		)
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, err
+		}
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		if applied {
+			return version, nil
+		}
+	}
+
+	return 0, rows.Err()
+}
@@ -0,0 +1,127 @@
+// Package sqlite implements the repository interfaces on SQLite for
+// single-node deployments that don't want to run Postgres.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/repository"
+	"github.com/111zxc/pr-review-service/internal/tracing"
+)
+
+// Open opens the database file at path, creating it if needed. Foreign keys
+// are enforced, writers wait on each other instead of failing with
+// SQLITE_BUSY, and timestamps are stored in a format SQLite's date
+// functions understand. ":memory:" gives a private in-memory database.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf(
+		"file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite&_txlock=immediate",
+		path,
+	)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	// Each connection to :memory: is a separate database.
+	if path == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+	db.SetConnMaxIdleTime(30 * time.Minute)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close() //nolint:errcheck
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", path, err)
+	}
+
+	return db, nil
+}
+
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+type txKey struct{}
+
+// dbtx is implemented by both *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn returns the transaction carried by ctx, or db outside of one.
+func conn(ctx context.Context, db *sql.DB) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// WithinTx implements repository.Transactor.
+func (tm *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	ctx, committed := repository.TrackAfterCommit(ctx)
+	err := tm.begin(ctx, func(tx *sql.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	if err != nil {
+		return err
+	}
+
+	committed()
+	return nil
+}
+
+// WithTx runs fn in the transaction carried by ctx, or in a new one.
+func (tm *TxManager) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+	return tm.begin(ctx, fn)
+}
+
+func (tm *TxManager) begin(
+	ctx context.Context,
+	fn func(tx *sql.Tx) error,
+) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "db.transaction")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := tm.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.For(ctx, "repository/sqlite").Error("transaction rollback failed", "error", rbErr)
+			}
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	if commitErr := tx.Commit(); commitErr != nil {
+		return fmt.Errorf("failed to commit transaction: %w", commitErr)
+	}
+
+	return nil
+}
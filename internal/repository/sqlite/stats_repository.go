@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type StatsRepository struct {
+	db *sql.DB
+}
+
+func NewStatsRepository(db *sql.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+func (r *StatsRepository) GetEventStats(ctx context.Context) (*domain.StatsResponse, error) {
+	counts, err := countEventsByType(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.StatsResponse{EventCounts: counts}
+	for _, count := range counts {
+		stats.TotalEvents += count
+	}
+
+	allEventTypes := []domain.EventType{
+		domain.EventTypePRCreated,
+		domain.EventTypePRMerged,
+		domain.EventTypePRClosed,
+		domain.EventTypeReviewerAssigned,
+		domain.EventTypeReviewerReassigned,
+		domain.EventTypeReviewerUnassigned,
+	}
+
+	for _, eventType := range allEventTypes {
+		if _, exists := stats.EventCounts[eventType]; !exists {
+			stats.EventCounts[eventType] = 0
+		}
+	}
+
+	return stats, nil
+}
+
+func (r *StatsRepository) GetOpenReviewsByTeam(ctx context.Context) (map[string]int, error) {
+	query := `
+        SELECT t.name, COUNT(*)
+        FROM pr_reviewers prr
+        JOIN pull_requests pr ON pr.id = prr.pr_id
+        JOIN pr_statuses ps ON ps.id = pr.status_id
+        JOIN teams t ON t.id = pr.team_id
+        WHERE ps.code = 'OPEN' AND t.deleted_at IS NULL
+        GROUP BY t.name
+    `
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var teamName string
+		var count int
+		if err := rows.Scan(&teamName, &count); err != nil {
+			return nil, err
+		}
+		counts[teamName] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,336 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type TeamRepository struct {
+	db *sql.DB
+	tx *TxManager
+}
+
+func NewTeamRepository(db *sql.DB, tx *TxManager) *TeamRepository {
+	return &TeamRepository{db: db, tx: tx}
+}
+
+func (r *TeamRepository) Create(ctx context.Context, team *domain.Team) error {
+	return r.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		// A soft-deleted team keeps its name until it is restored.
+		var taken bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM teams WHERE name = ?)`, team.Name).Scan(&taken); err != nil {
+			return fmt.Errorf("failed to check team name: %w", err)
+		}
+		if taken {
+			return domain.ErrTeamExists
+		}
+
+		var parentID string
+		if team.ParentName != "" {
+			err := tx.QueryRowContext(ctx, `SELECT id FROM teams WHERE name = ? AND deleted_at IS NULL`, team.ParentName).Scan(&parentID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrTeamNotFound
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get parent team: %w", err)
+			}
+		}
+
+		if team.ID == "" {
+			team.ID = domain.NewTeamID()
+		}
+		teamQuery := `INSERT INTO teams (id, name, parent_id) VALUES (?, ?, NULLIF(?, ''))`
+		if _, err := tx.ExecContext(ctx, teamQuery, team.ID, team.Name, parentID); err != nil {
+			return fmt.Errorf("failed to create team: %w", err)
+		}
+
+		for _, member := range team.Members {
+			memberQuery := `INSERT INTO team_members (team_id, user_id, role) VALUES (?, ?, COALESCE(NULLIF(?, ''), 'member'))`
+			if _, err := tx.ExecContext(ctx, memberQuery, team.ID, member.UserID, member.Role); err != nil {
+				return fmt.Errorf("failed to add team member: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// teamColumns reads a live team with the name of its live parent.
+const teamColumns = `
+        SELECT t.id, t.name, COALESCE(p.name, '')
+        FROM teams t
+        LEFT JOIN teams p ON p.id = t.parent_id AND p.deleted_at IS NULL`
+
+func (r *TeamRepository) GetByName(ctx context.Context, name string) (*domain.Team, error) {
+	return r.get(ctx, teamColumns+` WHERE t.name = ? AND t.deleted_at IS NULL`, name)
+}
+
+func (r *TeamRepository) GetByID(ctx context.Context, id string) (*domain.Team, error) {
+	return r.get(ctx, teamColumns+` WHERE t.id = ? AND t.deleted_at IS NULL`, id)
+}
+
+func (r *TeamRepository) get(ctx context.Context, teamQuery, arg string) (*domain.Team, error) {
+	var team domain.Team
+	err := conn(ctx, r.db).QueryRowContext(ctx, teamQuery, arg).Scan(&team.ID, &team.Name, &team.ParentName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	membersQuery := `
+        SELECT u.id, u.username, u.is_active, tm.role
+        FROM users u
+        JOIN team_members tm ON u.id = tm.user_id
+        WHERE tm.team_id = ? AND u.deleted_at IS NULL
+    `
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, membersQuery, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member domain.TeamMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive, &member.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan team member: %w", err)
+		}
+		team.Members = append(team.Members, member)
+	}
+
+	return &team, rows.Err()
+}
+
+func (r *TeamRepository) Exists(ctx context.Context, name string) (bool, error) {
+	query := `SELECT COUNT(*) FROM teams WHERE name = ? AND deleted_at IS NULL`
+
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, name).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check team existence: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *TeamRepository) AddMembers(ctx context.Context, teamName string, userIDs []string) error {
+	return r.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		var teamID string
+		err := tx.QueryRowContext(ctx, `SELECT id FROM teams WHERE name = ? AND deleted_at IS NULL`, teamName).Scan(&teamID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrTeamNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get team: %w", err)
+		}
+
+		for _, userID := range userIDs {
+			memberQuery := `INSERT INTO team_members (team_id, user_id) VALUES (?, ?) ON CONFLICT DO NOTHING`
+			if _, err := tx.ExecContext(ctx, memberQuery, teamID, userID); err != nil {
+				return fmt.Errorf("failed to add team member: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *TeamRepository) RemoveMember(ctx context.Context, teamName, userID string) error {
+	query := `
+        DELETE FROM team_members
+        WHERE team_id = (SELECT id FROM teams WHERE name = ? AND deleted_at IS NULL) AND user_id = ?
+    `
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, teamName, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	} else if n > 0 {
+		return nil
+	}
+
+	exists, err := r.Exists(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrTeamNotFound
+	}
+	return domain.ErrNotTeamMember
+}
+
+func (r *TeamRepository) SetRole(ctx context.Context, teamName, userID string, role domain.TeamRole) error {
+	query := `
+        UPDATE team_members SET role = ?
+        WHERE team_id = (SELECT id FROM teams WHERE name = ? AND deleted_at IS NULL) AND user_id = ?
+    `
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, role, teamName, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set member role: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to set member role: %w", err)
+	} else if n > 0 {
+		return nil
+	}
+
+	exists, err := r.Exists(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrTeamNotFound
+	}
+	return domain.ErrNotTeamMember
+}
+
+func (r *TeamRepository) Delete(ctx context.Context, name string) error {
+	query := `
+        UPDATE teams SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE name = ? AND deleted_at IS NULL
+    `
+	return r.setDeleted(ctx, "delete", query, name)
+}
+
+func (r *TeamRepository) Restore(ctx context.Context, name string) error {
+	query := `
+        UPDATE teams SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+        WHERE name = ? AND deleted_at IS NOT NULL
+    `
+	return r.setDeleted(ctx, "restore", query, name)
+}
+
+func (r *TeamRepository) Rename(ctx context.Context, name, newName string) error {
+	return r.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		var taken bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM teams WHERE name = ?)`, newName).Scan(&taken); err != nil {
+			return fmt.Errorf("failed to check team name: %w", err)
+		}
+		if taken {
+			return domain.ErrTeamExists
+		}
+
+		query := `UPDATE teams SET name = ?, updated_at = CURRENT_TIMESTAMP WHERE name = ? AND deleted_at IS NULL`
+		result, err := tx.ExecContext(ctx, query, newName, name)
+		if err != nil {
+			return fmt.Errorf("failed to rename team: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to rename team: %w", err)
+		} else if n == 0 {
+			return domain.ErrTeamNotFound
+		}
+		return nil
+	})
+}
+
+func (r *TeamRepository) SetParent(ctx context.Context, name, parentName string) error {
+	return r.tx.WithTx(ctx, func(tx *sql.Tx) error {
+		var parentID string
+		if parentName != "" {
+			err := tx.QueryRowContext(ctx, `SELECT id FROM teams WHERE name = ? AND deleted_at IS NULL`, parentName).Scan(&parentID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrTeamNotFound
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get parent team: %w", err)
+			}
+		}
+
+		query := `UPDATE teams SET parent_id = NULLIF(?, ''), updated_at = CURRENT_TIMESTAMP WHERE name = ? AND deleted_at IS NULL`
+		result, err := tx.ExecContext(ctx, query, parentID, name)
+		if err != nil {
+			return fmt.Errorf("failed to set parent team: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to set parent team: %w", err)
+		} else if n == 0 {
+			return domain.ErrTeamNotFound
+		}
+		return nil
+	})
+}
+
+func (r *TeamRepository) ListSubTeams(ctx context.Context, name string) ([]string, error) {
+	var teamID string
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT id FROM teams WHERE name = ? AND deleted_at IS NULL`, name).Scan(&teamID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	query := `SELECT name FROM teams WHERE parent_id = ? AND deleted_at IS NULL ORDER BY name`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sub-teams: %w", err)
+	}
+	defer rows.Close()
+
+	var children []string
+	for rows.Next() {
+		var child string
+		if err := rows.Scan(&child); err != nil {
+			return nil, fmt.Errorf("failed to scan sub-team: %w", err)
+		}
+		children = append(children, child)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sub-teams: %w", err)
+	}
+	return children, nil
+}
+
+func (r *TeamRepository) setDeleted(ctx context.Context, op, query, name string) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, name)
+	if err != nil {
+		return fmt.Errorf("failed to %s team: %w", op, err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to %s team: %w", op, err)
+	} else if n == 0 {
+		return domain.ErrTeamNotFound
+	}
+	return nil
+}
+
+func (r *TeamRepository) List(ctx context.Context) ([]*domain.Team, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT name FROM teams WHERE deleted_at IS NULL ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read teams: %w", err)
+	}
+	rows.Close()
+
+	teams := make([]*domain.Team, 0, len(names))
+	for _, name := range names {
+		team, err := r.GetByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
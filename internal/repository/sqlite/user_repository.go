@@ -0,0 +1,332 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+// userTeams selects the teams of the user u as a JSON object mapping each
+// team name to the user's role in it.
+const userTeams = `
+    (SELECT json_group_object(t.name, tm.role)
+        FROM team_members tm
+        JOIN teams t ON tm.team_id = t.id
+        WHERE tm.user_id = u.id AND t.deleted_at IS NULL
+    )`
+
+// decodeTeams fills the user's Teams and Roles from a userTeams column.
+func decodeTeams(user *domain.User, raw string) error {
+	if err := json.Unmarshal([]byte(raw), &user.Roles); err != nil {
+		return fmt.Errorf("failed to decode user teams: %w", err)
+	}
+	user.Teams = slices.Sorted(maps.Keys(user.Roles))
+	if len(user.Roles) == 0 {
+		user.Roles = nil
+	}
+	return nil
+}
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	query := `
+        INSERT INTO users (id, username, is_active)
+        VALUES (?, ?, ?)
+        ON CONFLICT (id) DO UPDATE SET
+            username = excluded.username,
+            is_active = excluded.is_active,
+            deleted_at = NULL,
+            updated_at = CURRENT_TIMESTAMP
+    `
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, user.ID, user.Username, user.IsActive)
+	if err != nil {
+		return fmt.Errorf("failed to create/update user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	query := `
+        SELECT u.id, u.username, u.is_active,` + userTeams + `
+        FROM users u
+        WHERE u.id = ? AND u.deleted_at IS NULL
+    `
+
+	var user domain.User
+	var teams string
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&user.ID, &user.Username, &user.IsActive, &teams,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := decodeTeams(&user, teams); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	query := `
+        UPDATE users
+        SET username = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+        WHERE id = ? AND deleted_at IS NULL
+    `
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, user.Username, user.IsActive, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	} else if n == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepository) GetByTeam(ctx context.Context, teamName string) ([]*domain.User, error) {
+	query := `
+        SELECT u.id, u.username, u.is_active,` + userTeams + `
+        FROM users u
+        JOIN team_members tm ON u.id = tm.user_id
+        JOIN teams t ON tm.team_id = t.id
+        WHERE t.name = ? AND t.deleted_at IS NULL AND u.deleted_at IS NULL AND u.is_active = TRUE
+    `
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		var user domain.User
+		var teams string
+		if err := rows.Scan(&user.ID, &user.Username, &user.IsActive, &teams); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		if err := decodeTeams(&user, teams); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int, error) {
+	where := `
+        FROM users u
+        WHERE u.deleted_at IS NULL
+            AND (?1 = '' OR u.username = ?1)
+            AND (?2 IS NULL OR u.is_active = ?2)
+            AND (?3 = '' OR substr(u.username, 1, length(?3)) = ?3)
+            AND (?4 = '' OR EXISTS (
+                SELECT 1
+                FROM team_members tm
+                JOIN teams t ON tm.team_id = t.id
+                WHERE tm.user_id = u.id AND t.name = ?4 AND t.deleted_at IS NULL
+            ))`
+	args := []any{filter.Username, filter.IsActive, filter.UsernamePrefix, filter.Team}
+
+	var total int
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*)`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	// A negative LIMIT means no limit in SQLite.
+	limit := filter.Limit
+	if limit == 0 {
+		limit = -1
+	}
+	query := `SELECT u.id, u.username, u.is_active,` + userTeams + where + `
+        ORDER BY u.id
+        LIMIT ?6 OFFSET ?5`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append(args, filter.Offset, limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		var user domain.User
+		var teams string
+		if err := rows.Scan(&user.ID, &user.Username, &user.IsActive, &teams); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		if err := decodeTeams(&user, teams); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, &user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+func (r *UserRepository) CountOpenReviews(ctx context.Context, userIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	args := make([]any, len(userIDs))
+	for i, id := range userIDs {
+		args[i] = id
+	}
+	query := `
+        SELECT rv.user_id, COUNT(*)
+        FROM pr_reviewers rv
+        JOIN pull_requests pr ON rv.pr_id = pr.id
+        JOIN pr_statuses ps ON pr.status_id = ps.id
+        WHERE rv.user_id IN (?` + strings.Repeat(", ?", len(userIDs)-1) + `) AND ps.code = 'OPEN'
+        GROUP BY rv.user_id
+    `
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open reviews: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan open reviews: %w", err)
+		}
+		counts[userID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read open reviews: %w", err)
+	}
+
+	return counts, nil
+}
+
+// Delete soft-deletes the user and drops their team memberships; Create
+// brings the row back. Callers run it in a transaction.
+func (r *UserRepository) Delete(ctx context.Context, userID string) error {
+	db := conn(ctx, r.db)
+
+	result, err := db.ExecContext(ctx, `
+        UPDATE users
+        SET is_active = FALSE, deleted_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'), updated_at = CURRENT_TIMESTAMP
+        WHERE id = ? AND deleted_at IS NULL
+    `, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if n == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	if _, err := db.ExecContext(ctx, `DELETE FROM team_members WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to drop team memberships: %w", err)
+	}
+	return nil
+}
+
+// Erase moves every reference from userID to a new soft-deleted user named
+// pseudonym before deleting the old row, so neither the RESTRICT on authors
+// nor the SET NULL on events comes into play. Callers run it in a
+// transaction.
+func (r *UserRepository) Erase(ctx context.Context, userID, pseudonym string) (domain.ErasedRecords, error) {
+	// exec runs query with ?1 = userID and ?2 = pseudonym.
+	exec := func(what, query string) (int, error) {
+		result, err := conn(ctx, r.db).ExecContext(ctx, query, userID, pseudonym)
+		if err != nil {
+			return 0, fmt.Errorf("failed to %s: %w", what, err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to %s: %w", what, err)
+		}
+		return int(n), nil
+	}
+	var erased domain.ErasedRecords
+
+	n, err := exec("create pseudonymous user", `
+        INSERT INTO users (id, username, is_active, deleted_at)
+        SELECT ?2, ?2, false, strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+        FROM users
+        WHERE id = ?1 AND deleted_at IS NULL
+    `)
+	if err != nil {
+		return erased, err
+	}
+	if n == 0 {
+		return erased, domain.ErrUserNotFound
+	}
+
+	if erased.PullRequests, err = exec("move authored pull requests",
+		`UPDATE pull_requests SET author_id = ?2 WHERE author_id = ?1`); err != nil {
+		return erased, err
+	}
+	if erased.PastReviews, err = exec("move reviews",
+		`UPDATE pr_reviewers SET user_id = ?2 WHERE user_id = ?1`); err != nil {
+		return erased, err
+	}
+	if erased.Events, err = exec("scrub events", `
+        UPDATE events
+        SET user_id = CASE WHEN user_id = ?1 THEN ?2 ELSE user_id END,
+            additional_data = CASE WHEN json_extract(additional_data, '$.new_user_id') = ?1
+                THEN json_set(`+scrubOldUserID+`, '$.new_user_id', ?2)
+                ELSE `+scrubOldUserID+` END
+        WHERE user_id = ?1
+            OR json_extract(additional_data, '$.old_user_id') = ?1
+            OR json_extract(additional_data, '$.new_user_id') = ?1
+    `); err != nil {
+		return erased, err
+	}
+
+	if _, err := exec("drop team memberships", `DELETE FROM team_members WHERE user_id = ?1`); err != nil {
+		return erased, err
+	}
+	if _, err := exec("delete user", `DELETE FROM users WHERE id = ?1`); err != nil {
+		return erased, err
+	}
+
+	return erased, nil
+}
+
+// scrubOldUserID is an event's additional data with old_user_id replaced
+// when it names the erased user ?1.
+const scrubOldUserID = `CASE WHEN json_extract(additional_data, '$.old_user_id') = ?1
+                THEN json_set(additional_data, '$.old_user_id', ?2)
+                ELSE additional_data END`
@@ -0,0 +1,45 @@
+package repository
+
+import "context"
+
+// Transactor runs several repository calls atomically. fn receives a context
+// carrying the transaction: every repository call made with it takes part in
+// the transaction, which commits if fn returns nil and rolls back otherwise.
+// A WithinTx nested in another joins the outer transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type afterCommitKey struct{}
+
+type afterCommitHooks struct {
+	hooks []func()
+}
+
+// InTx reports whether ctx carries a transaction started by WithinTx.
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks)
+	return ok
+}
+
+// AfterCommit runs fn once the transaction carried by ctx has committed, and
+// never if it rolls back. Without a transaction fn runs immediately.
+func AfterCommit(ctx context.Context, fn func()) {
+	if h, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+		h.hooks = append(h.hooks, fn)
+		return
+	}
+	fn()
+}
+
+// TrackAfterCommit is for Transactor implementations starting an outermost
+// transaction: callers of AfterCommit with the returned context are
+// deferred until the implementation calls commit after a successful commit.
+func TrackAfterCommit(ctx context.Context) (_ context.Context, commit func()) {
+	h := &afterCommitHooks{}
+	return context.WithValue(ctx, afterCommitKey{}, h), func() {
+		for _, fn := range h.hooks {
+			fn()
+		}
+	}
+}
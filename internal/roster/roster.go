@@ -0,0 +1,72 @@
+// Package roster reads the org roster files synced by
+// service.TeamService.SyncRoster.
+package roster
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+type file struct {
+	Teams []team `yaml:"teams"`
+}
+
+type team struct {
+	Name       string   `yaml:"team_name"`
+	ParentName string   `yaml:"parent_team"`
+	Members    []member `yaml:"members"`
+}
+
+type member struct {
+	UserID   string `yaml:"user_id"`
+	Username string `yaml:"username"`
+	// IsActive defaults to true, unlike in /team/add.
+	IsActive *bool  `yaml:"is_active"`
+	Role     string `yaml:"role"`
+}
+
+// Decode reads a YAML or JSON roster. Unknown fields and roles are errors;
+// missing roles mean member. The result is not validated, see
+// domain.ValidateRoster.
+func Decode(r io.Reader) ([]*domain.Team, error) {
+	// JSON is a subset of YAML, so one decoder handles both formats.
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	var f file
+	if err := dec.Decode(&f); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid roster: %w", err)
+	}
+
+	teams := make([]*domain.Team, 0, len(f.Teams))
+	for _, t := range f.Teams {
+		out := &domain.Team{Name: t.Name, ParentName: t.ParentName}
+		for _, m := range t.Members {
+			role, err := domain.ParseTeamRole(m.Role)
+			if err != nil {
+				return nil, fmt.Errorf("invalid roster: team %q: user %q: unknown role %q", t.Name, m.UserID, m.Role)
+			}
+			out.Members = append(out.Members, domain.TeamMember{
+				UserID:   m.UserID,
+				Username: m.Username,
+				IsActive: m.IsActive == nil || *m.IsActive,
+				Role:     role,
+			})
+		}
+		teams = append(teams, out)
+	}
+	return teams, nil
+}
+
+func Load(path string) ([]*domain.Team, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read roster: %w", err)
+	}
+	defer f.Close()
+	return Decode(f)
+}
@@ -0,0 +1,82 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Filter is an `attribute eq value` filter, the only form identity
+// providers use for provisioning.
+type Filter struct {
+	// Attribute is lower-cased, since SCIM attribute names are case
+	// insensitive.
+	Attribute string
+	// Value is a string, or "true" or "false" for a boolean.
+	Value string
+}
+
+var filterPattern = regexp.MustCompile(`^\s*([A-Za-z][\w.]*)\s+(?i:eq)\s+("(?:[^"\\]|\\.)*"|true|false)\s*$`)
+
+// ParseFilter parses s; an empty s gives a nil filter.
+func ParseFilter(s string) (*Filter, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	m := filterPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, NewError(http.StatusBadRequest, ErrInvalidFilter, `only "attribute eq value" filters are supported`)
+	}
+
+	value := m[2]
+	if strings.HasPrefix(value, `"`) {
+		if err := json.Unmarshal([]byte(value), &value); err != nil {
+			return nil, NewError(http.StatusBadRequest, ErrInvalidFilter, "invalid filter value")
+		}
+	}
+	return &Filter{Attribute: strings.ToLower(m[1]), Value: value}, nil
+}
+
+// Bool returns the filter value as a boolean.
+func (f *Filter) Bool() (bool, error) {
+	b, err := strconv.ParseBool(f.Value)
+	if err != nil {
+		return false, NewError(http.StatusBadRequest, ErrInvalidFilter, f.Attribute+" must be compared with true or false")
+	}
+	return b, nil
+}
+
+// Page is the 1-based startIndex and count of a list request.
+type Page struct {
+	StartIndex int
+	Count      int
+}
+
+// ParsePage reads startIndex and count, defaulting to the first
+// MaxResults resources. Out-of-range values are clamped as RFC 7644
+// requires.
+func ParsePage(startIndex, count string) (Page, error) {
+	page := Page{StartIndex: 1, Count: MaxResults}
+	if startIndex != "" {
+		n, err := strconv.Atoi(startIndex)
+		if err != nil {
+			return Page{}, NewError(http.StatusBadRequest, ErrInvalidValue, "startIndex must be a number")
+		}
+		page.StartIndex = max(n, 1)
+	}
+	if count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return Page{}, NewError(http.StatusBadRequest, ErrInvalidValue, "count must be a number")
+		}
+		page.Count = min(max(n, 0), MaxResults)
+	}
+	return page, nil
+}
+
+// Offset is the number of resources the page skips.
+func (p Page) Offset() int {
+	return p.StartIndex - 1
+}
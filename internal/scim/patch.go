@@ -0,0 +1,195 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type PatchRequest struct {
+	Schemas    []string    `json:"schemas"`
+	Operations []Operation `json:"Operations"`
+}
+
+// Operation is one PATCH operation. Op is matched case-insensitively since
+// some providers send "Replace".
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply changes the user's userName and active flag as ops say. Operations
+// on attributes the service does not store are ignored.
+func (u *User) Apply(ops []Operation) error {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+		case "remove":
+			continue
+		default:
+			return NewError(http.StatusBadRequest, ErrInvalidSyntax, "unknown op "+strconv.Quote(op.Op))
+		}
+
+		attrs, err := operationAttrs(op)
+		if err != nil {
+			return err
+		}
+		for name, value := range attrs {
+			switch strings.ToLower(name) {
+			case "active":
+				if u.Active, err = parseBool(value); err != nil {
+					return err
+				}
+			case "username":
+				if u.UserName, err = parseString(name, value); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Apply changes the group's displayName and members as ops say. Members can
+// be removed by a `members[value eq "id"]` path or by listing them in the
+// value.
+func (g *Group) Apply(ops []Operation) error {
+	for _, op := range ops {
+		kind := strings.ToLower(op.Op)
+		switch kind {
+		case "add", "replace":
+			attrs, err := operationAttrs(op)
+			if err != nil {
+				return err
+			}
+			for name, value := range attrs {
+				if err := g.set(kind, name, value); err != nil {
+					return err
+				}
+			}
+		case "remove":
+			if err := g.remove(op); err != nil {
+				return err
+			}
+		default:
+			return NewError(http.StatusBadRequest, ErrInvalidSyntax, "unknown op "+strconv.Quote(op.Op))
+		}
+	}
+	return nil
+}
+
+func (g *Group) set(kind, name string, value json.RawMessage) error {
+	var err error
+	switch strings.ToLower(name) {
+	case "displayname":
+		g.DisplayName, err = parseString(name, value)
+	case "members":
+		var refs []Ref
+		if refs, err = parseRefs(value); err != nil {
+			return err
+		}
+		if kind == "replace" {
+			g.Members = nil
+		}
+		for _, ref := range refs {
+			if !g.HasMember(ref.Value) {
+				g.Members = append(g.Members, ref)
+			}
+		}
+	}
+	return err
+}
+
+func (g *Group) remove(op Operation) error {
+	attr, filter, filtered := strings.Cut(strings.TrimSpace(op.Path), "[")
+	if !strings.EqualFold(attr, "members") {
+		return NewError(http.StatusBadRequest, ErrInvalidPath, "only members can be removed")
+	}
+
+	var ids []string
+	switch {
+	case filtered:
+		f, err := ParseFilter(strings.TrimSuffix(filter, "]"))
+		if err != nil || f == nil || f.Attribute != "value" {
+			return NewError(http.StatusBadRequest, ErrInvalidPath, `members must be selected by [value eq "id"]`)
+		}
+		ids = []string{f.Value}
+	case len(op.Value) > 0:
+		refs, err := parseRefs(op.Value)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			ids = append(ids, ref.Value)
+		}
+	default:
+		g.Members = nil
+		return nil
+	}
+
+	g.Members = slices.DeleteFunc(g.Members, func(ref Ref) bool {
+		return slices.Contains(ids, ref.Value)
+	})
+	return nil
+}
+
+func (g *Group) HasMember(id string) bool {
+	return slices.ContainsFunc(g.Members, func(ref Ref) bool { return ref.Value == id })
+}
+
+// operationAttrs returns the attributes an add or replace sets: the one at
+// op.Path, or all of op.Value without a path.
+func operationAttrs(op Operation) (map[string]json.RawMessage, error) {
+	if op.Path != "" {
+		if strings.ContainsAny(op.Path, "[.") {
+			// Sub-attributes such as name.givenName are not stored.
+			return nil, nil
+		}
+		return map[string]json.RawMessage{op.Path: op.Value}, nil
+	}
+
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(op.Value, &attrs); err != nil {
+		return nil, NewError(http.StatusBadRequest, ErrInvalidValue, "an operation without a path needs an object value")
+	}
+	return attrs, nil
+}
+
+// parseBool also accepts "True" and "False" strings, which Azure AD sends.
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, NewError(http.StatusBadRequest, ErrInvalidValue, "active must be a boolean")
+}
+
+func parseString(name string, value json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil || s == "" {
+		return "", NewError(http.StatusBadRequest, ErrInvalidValue, name+" must be a non-empty string")
+	}
+	return s, nil
+}
+
+func parseRefs(value json.RawMessage) ([]Ref, error) {
+	var refs []Ref
+	if err := json.Unmarshal(value, &refs); err != nil {
+		return nil, NewError(http.StatusBadRequest, ErrInvalidValue, "members must be a list of {\"value\": id}")
+	}
+	for _, ref := range refs {
+		if ref.Value == "" {
+			return nil, NewError(http.StatusBadRequest, ErrInvalidValue, "member value is required")
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,136 @@
+// Package scim holds the SCIM 2.0 (RFC 7643, RFC 7644) resources, filters
+// and PATCH operations served under /scim/v2. Only the attributes the
+// service stores are kept; everything else an identity provider sends is
+// ignored.
+package scim
+
+import "strconv"
+
+const (
+	UserSchema            = "urn:ietf:params:scim:schemas:core:2.0:User"
+	GroupSchema           = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	ListSchema            = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PatchSchema           = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ErrorSchema           = "urn:ietf:params:scim:api:messages:2.0:Error"
+	ServiceProviderSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+
+	ContentType = "application/scim+json"
+
+	// MaxResults caps the count of a list request.
+	MaxResults = 100
+)
+
+// User is a service user. ExternalID is the same as ID: a user created with
+// an externalId gets it as ID, otherwise the userName.
+type User struct {
+	Schemas    []string `json:"schemas"`
+	ID         string   `json:"id"`
+	ExternalID string   `json:"externalId,omitempty"`
+	UserName   string   `json:"userName"`
+	Active     bool     `json:"active"`
+	Groups     []Ref    `json:"groups,omitempty"`
+	Meta       *Meta    `json:"meta,omitempty"`
+}
+
+// Group is a team; its ID is the team ID.
+type Group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	DisplayName string   `json:"displayName"`
+	Members     []Ref    `json:"members"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// Ref points at another resource: a user ID in Group.Members, a team ID in
+// User.Groups.
+type Ref struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// NewListResponse wraps one page of resources starting at the 1-based
+// startIndex.
+func NewListResponse[T any](resources []T, total, startIndex int) ListResponse {
+	resp := ListResponse{
+		Schemas:      []string{ListSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    make([]any, 0, len(resources)),
+	}
+	for _, r := range resources {
+		resp.Resources = append(resp.Resources, r)
+	}
+	return resp
+}
+
+// Error is the SCIM error body; Status repeats the HTTP status as a string.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+	status   int
+}
+
+// Error types from RFC 7644, section 3.12.
+const (
+	ErrInvalidFilter = "invalidFilter"
+	ErrInvalidValue  = "invalidValue"
+	ErrInvalidPath   = "invalidPath"
+	ErrInvalidSyntax = "invalidSyntax"
+	ErrUniqueness    = "uniqueness"
+	ErrMutability    = "mutability"
+)
+
+func NewError(status int, scimType, detail string) *Error {
+	return &Error{
+		Schemas:  []string{ErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+		status:   status,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// HTTPStatus is the status the error is served with.
+func (e *Error) HTTPStatus() int {
+	return e.status
+}
+
+// ServiceProviderConfig describes what the endpoints support: PATCH and
+// eq filters, but no bulk, sorting, ETags or passwords.
+func ServiceProviderConfig() map[string]any {
+	unsupported := map[string]bool{"supported": false}
+	return map[string]any{
+		"schemas":        []string{ServiceProviderSchema},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": MaxResults},
+		"changePassword": unsupported,
+		"sort":           unsupported,
+		"etag":           unsupported,
+		"authenticationSchemes": []map[string]string{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "The token from scim.token in the configuration",
+		}},
+	}
+}
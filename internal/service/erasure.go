@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/repository"
+	"github.com/111zxc/pr-review-service/internal/tracing"
+)
+
+// EraseUser handles a data-protection erasure request. The user's open
+// reviews are handed to others, then their ID and username are replaced by
+// a pseudonym everywhere, including event data, and they leave all teams.
+// Pull requests and events stay, so statistics do not change.
+//
+// The erased ID is only returned in the receipt; it is not logged.
+func (s *TeamService) EraseUser(ctx context.Context, userID string) (_ *domain.ErasureReceipt, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.EraseUser")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	receipt := &domain.ErasureReceipt{UserID: userID, Pseudonym: domain.NewPseudonym()}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		receipt.Teams = user.Teams
+
+		// Releasing records events naming the user; Erase scrubs them too.
+		if receipt.Reviews, err = s.reviews.ReleaseAllReviews(ctx, userID); err != nil {
+			return err
+		}
+		receipt.ErasedRecords, err = s.userRepo.Erase(ctx, userID, receipt.Pseudonym)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	receipt.ErasedAt = time.Now().UTC()
+
+	logger.For(ctx, "service").Info("User erased",
+		"pseudonym", receipt.Pseudonym,
+		"teams", len(receipt.Teams),
+		"reviews_released", len(receipt.Reviews),
+		"pull_requests", receipt.PullRequests,
+		"events", receipt.Events,
+	)
+	return receipt, nil
+}
+
+// DeleteUser removes a user without erasing them: their open reviews are
+// handed to others, they leave all teams and are no longer found, but
+// their pull requests and events keep their ID. Creating a user with the
+// same ID brings them back.
+func (s *TeamService) DeleteUser(ctx context.Context, userID string) (_ []domain.ReviewerChange, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.DeleteUser")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	var released []domain.ReviewerChange
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+			return err
+		}
+		if released, err = s.reviews.ReleaseAllReviews(ctx, userID); err != nil {
+			return err
+		}
+		return s.userRepo.Delete(ctx, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(ctx, "service").Info("User deleted", "reviews_released", len(released))
+	return released, nil
+}
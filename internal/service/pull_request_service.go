@@ -1,13 +1,19 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"math/rand"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
 	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/metrics"
 	"github.com/111zxc/pr-review-service/internal/repository"
+	"github.com/111zxc/pr-review-service/internal/tracing"
 )
 
 type PullRequestService struct {
@@ -17,6 +23,7 @@ type PullRequestService struct {
 	prStatusRepo repository.PRStatusRepository
 	eventsRepo   repository.EventsRepository
 	rng          *rand.Rand
+	policy       atomic.Pointer[domain.ReviewPolicy]
 }
 
 func NewPullRequestService(
@@ -26,7 +33,7 @@ func NewPullRequestService(
 	prStatusRepo repository.PRStatusRepository,
 	eventsRepo repository.EventsRepository,
 ) *PullRequestService {
-	return &PullRequestService{
+	s := &PullRequestService{
 		prRepo:       prRepo,
 		userRepo:     userRepo,
 		teamRepo:     teamRepo,
@@ -34,10 +41,23 @@ func NewPullRequestService(
 		eventsRepo:   eventsRepo,
 		rng:          rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
+	s.SetReviewPolicy(domain.DefaultReviewPolicy())
+	return s
 }
 
-func (s *PullRequestService) CreatePullRequest(pr *domain.PullRequest) error {
-	exists, err := s.prRepo.Exists(pr.ID)
+// SetReviewPolicy replaces the reviewer assignment policy. It is safe to call
+// while requests are being served.
+func (s *PullRequestService) SetReviewPolicy(policy domain.ReviewPolicy) {
+	s.policy.Store(&policy)
+}
+
+func (s *PullRequestService) CreatePullRequest(ctx context.Context, pr *domain.PullRequest) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PullRequestService.CreatePullRequest")
+	defer func() { tracing.End(span, err) }()
+	// Writes decide on what they read, so skip a possibly lagging replica.
+	ctx = repository.WithPrimary(ctx)
+
+	exists, err := s.prRepo.Exists(ctx, pr.ID)
 	if err != nil {
 		return err
 	}
@@ -45,39 +65,35 @@ func (s *PullRequestService) CreatePullRequest(pr *domain.PullRequest) error {
 		return domain.ErrPullRequestExists
 	}
 
-	author, err := s.userRepo.GetByID(pr.AuthorID)
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
 	if err != nil {
 		return domain.ErrUserNotFound
 	}
 
-	var authorTeam string
-	if author.TeamName != "" {
-		authorTeam = author.TeamName
-	} else {
-		teams, err := s.findUserTeams(pr.AuthorID)
-		if err != nil || len(teams) == 0 {
-			return domain.ErrTeamNotFound
-		}
-		authorTeam = teams[0].Name
+	team, err := s.reviewTeam(ctx, author, pr.TeamName)
+	if err != nil {
+		return err
 	}
+	pr.TeamName = team
 
-	reviewers, err := s.assignReviewers(authorTeam, pr.AuthorID)
+	reviewers, err := s.assignReviewers(ctx, team, pr.AuthorID)
 	if err != nil {
 		return err
 	}
 	pr.AssignedReviewers = reviewers
 
-	if err := s.prRepo.Create(pr); err != nil {
+	if err := s.prRepo.Create(ctx, pr); err != nil {
 		return err
 	}
+	metrics.PullRequestsCreated.Inc()
+	logger.For(ctx, "service").Info("Pull request created", "reviewers", pr.AssignedReviewers)
 
 	eventData, err := json.Marshal(domain.PRCreatedData{
 		PRName:    pr.Name,
 		CreatedAt: time.Now(),
 	})
 	if err != nil {
-		logger.Error("Failed to marshal created PR data",
-			"error", err, "prname", pr.Name)
+		logger.For(ctx, "service").Error("Failed to marshal created PR data", "error", err)
 		return err
 	}
 
@@ -87,9 +103,8 @@ func (s *PullRequestService) CreatePullRequest(pr *domain.PullRequest) error {
 		UserID:         pr.AuthorID,
 		AdditionalData: eventData,
 	}
-	if err := s.eventsRepo.CreateEvent(event); err != nil {
-		logger.Error("Failed to create PR created event",
-			"error", err, "pr_id", pr.ID)
+	if err := s.eventsRepo.CreateEvent(ctx, event); err != nil {
+		logger.For(ctx, "service").Error("Failed to create PR created event", "error", err)
 	}
 
 	for _, reviewerID := range pr.AssignedReviewers {
@@ -97,7 +112,7 @@ func (s *PullRequestService) CreatePullRequest(pr *domain.PullRequest) error {
 			AssignedAt: time.Now(),
 		})
 		if err != nil {
-			logger.Error("Failed to marshal reviewer assignment data",
+			logger.For(ctx, "service").Error("Failed to marshal reviewer assignment data",
 				"error", err)
 			return err
 		}
@@ -108,17 +123,21 @@ func (s *PullRequestService) CreatePullRequest(pr *domain.PullRequest) error {
 			UserID:         reviewerID,
 			AdditionalData: assignmentData,
 		}
-		if err := s.eventsRepo.CreateEvent(assignmentEvent); err != nil {
-			logger.Error("Failed to create reviewer assigned event",
-				"error", err, "pr_id", pr.ID, "reviewer_id", reviewerID)
+		if err := s.eventsRepo.CreateEvent(ctx, assignmentEvent); err != nil {
+			logger.For(ctx, "service").Error("Failed to create reviewer assigned event",
+				"error", err, "reviewer_id", reviewerID)
 		}
 	}
 
 	return nil
 }
 
-func (s *PullRequestService) MergePullRequest(prID string) (*domain.PullRequest, error) {
-	pr, err := s.prRepo.GetByID(prID)
+func (s *PullRequestService) MergePullRequest(ctx context.Context, prID string) (_ *domain.PullRequest, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PullRequestService.MergePullRequest")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	pr, err := s.prRepo.GetByID(ctx, prID)
 	if err != nil {
 		return nil, err
 	}
@@ -126,20 +145,25 @@ func (s *PullRequestService) MergePullRequest(prID string) (*domain.PullRequest,
 	if pr.IsMerged() {
 		return pr, nil
 	}
+	if pr.IsClosed() {
+		return nil, domain.ErrPullRequestClosed
+	}
 
 	pr.Status = domain.PRStatusMerged
 	now := time.Now()
 	pr.MergedAt = &now
 
-	if err := s.prRepo.Update(pr); err != nil {
+	if err := s.prRepo.Update(ctx, pr); err != nil {
 		return nil, err
 	}
+	metrics.PullRequestsMerged.Inc()
+	logger.For(ctx, "service").Info("Pull request merged")
 
 	mergeData, err := json.Marshal(domain.PRMergedData{
 		MergedAt: time.Now(),
 	})
 	if err != nil {
-		logger.Error("Failed to marshal PR merge data",
+		logger.For(ctx, "service").Error("Failed to marshal PR merge data",
 			"error", err)
 	}
 
@@ -149,16 +173,19 @@ func (s *PullRequestService) MergePullRequest(prID string) (*domain.PullRequest,
 		UserID:         pr.AuthorID,
 		AdditionalData: mergeData,
 	}
-	if err := s.eventsRepo.CreateEvent(event); err != nil {
-		logger.Error("Failed to create PR merged event",
-			"error", err, "pr_id", prID)
+	if err := s.eventsRepo.CreateEvent(ctx, event); err != nil {
+		logger.For(ctx, "service").Error("Failed to create PR merged event", "error", err)
 	}
 
 	return pr, nil
 }
 
-func (s *PullRequestService) ReassignReviewer(prID, oldUserID string) (*domain.PullRequest, string, error) {
-	pr, err := s.prRepo.GetByID(prID)
+func (s *PullRequestService) ReassignReviewer(ctx context.Context, prID, oldUserID string) (_ *domain.PullRequest, _ string, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PullRequestService.ReassignReviewer")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	pr, err := s.prRepo.GetByID(ctx, prID)
 	if err != nil {
 		return nil, "", err
 	}
@@ -166,16 +193,20 @@ func (s *PullRequestService) ReassignReviewer(prID, oldUserID string) (*domain.P
 	if pr.IsMerged() {
 		return nil, "", domain.ErrPullRequestMerged
 	}
+	if pr.IsClosed() {
+		return nil, "", domain.ErrPullRequestClosed
+	}
 
 	if !s.isUserAssigned(pr.AssignedReviewers, oldUserID) {
-		logger.Error("Reviewer not assigned",
-			"pr_id", prID,
-			"old_reviewer_id", oldUserID,
+		logger.For(ctx, "service").Error("Reviewer not assigned",
 			"assigned_reviewers", pr.AssignedReviewers)
 		return nil, "", domain.ErrReviewerNotAssigned
 	}
 
-	newReviewer, err := s.findReplacementReviewer(prID, oldUserID)
+	newReviewer, err := s.findReplacementReviewer(ctx, pr, oldUserID)
+	if errors.Is(err, domain.ErrNoCandidate) {
+		metrics.NoCandidateFailures.Inc()
+	}
 	if err != nil {
 		return nil, "", err
 	}
@@ -187,9 +218,11 @@ func (s *PullRequestService) ReassignReviewer(prID, oldUserID string) (*domain.P
 		}
 	}
 
-	if err := s.prRepo.Update(pr); err != nil {
+	if err := s.prRepo.Update(ctx, pr); err != nil {
 		return nil, "", err
 	}
+	metrics.ReviewersReassigned.Inc()
+	logger.For(ctx, "service").Info("Reviewer reassigned", "new_user_id", newReviewer)
 
 	reassignData, err := json.Marshal(domain.ReviewerReassignedData{
 		OldUserID:    oldUserID,
@@ -197,7 +230,7 @@ func (s *PullRequestService) ReassignReviewer(prID, oldUserID string) (*domain.P
 		ReassignedAt: time.Now(),
 	})
 	if err != nil {
-		logger.Error("Failed to marshal reassigned reviewer data",
+		logger.For(ctx, "service").Error("Failed to marshal reassigned reviewer data",
 			"error", err)
 	}
 
@@ -207,16 +240,19 @@ func (s *PullRequestService) ReassignReviewer(prID, oldUserID string) (*domain.P
 		UserID:         newReviewer,
 		AdditionalData: reassignData,
 	}
-	if err := s.eventsRepo.CreateEvent(event); err != nil {
-		logger.Error("Failed to create reviewer reassigned event",
-			"error", err, "pr_id", prID, "old_user_id", oldUserID, "new_user_id", newReviewer)
+	if err := s.eventsRepo.CreateEvent(ctx, event); err != nil {
+		logger.For(ctx, "service").Error("Failed to create reviewer reassigned event",
+			"error", err, "new_user_id", newReviewer)
 	}
 
 	return pr, newReviewer, nil
 }
 
-func (s *PullRequestService) GetUserReviews(userID string) ([]*domain.PullRequestShort, error) {
-	prs, err := s.prRepo.ListByReviewer(userID)
+func (s *PullRequestService) GetUserReviews(ctx context.Context, userID string) (_ []*domain.PullRequestShort, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PullRequestService.GetUserReviews")
+	defer func() { tracing.End(span, err) }()
+
+	prs, err := s.prRepo.ListByReviewer(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -234,51 +270,388 @@ func (s *PullRequestService) GetUserReviews(userID string) ([]*domain.PullReques
 	return shortPRs, nil
 }
 
-func (s *PullRequestService) assignReviewers(teamName, authorID string) ([]string, error) {
-	teamUsers, err := s.userRepo.GetByTeam(teamName)
+// ReleaseReviews hands each open review userID holds on a pull request of
+// teamName to another member of that team, or unassigns it when nobody is
+// left. Pull requests without a recorded team are left alone. Callers run it
+// in the transaction that changes the membership, so a failed event fails
+// the whole change.
+func (s *PullRequestService) ReleaseReviews(ctx context.Context, teamName, userID string) (_ []domain.ReviewerChange, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PullRequestService.ReleaseReviews")
+	defer func() { tracing.End(span, err) }()
+
+	return s.releaseReviews(ctx, userID, func(pr *domain.PullRequest) bool {
+		return pr.TeamName == teamName
+	})
+}
+
+// ReleaseAllReviews is ReleaseReviews for every open review userID holds,
+// whatever team the pull request belongs to.
+func (s *PullRequestService) ReleaseAllReviews(ctx context.Context, userID string) (_ []domain.ReviewerChange, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PullRequestService.ReleaseAllReviews")
+	defer func() { tracing.End(span, err) }()
+
+	return s.releaseReviews(ctx, userID, func(*domain.PullRequest) bool { return true })
+}
+
+func (s *PullRequestService) releaseReviews(ctx context.Context, userID string, match func(pr *domain.PullRequest) bool) ([]domain.ReviewerChange, error) {
+	ctx = repository.WithPrimary(ctx)
+
+	prs, err := s.prRepo.ListByReviewer(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	var candidates []string
-	for _, user := range teamUsers {
-		if user.IsActive && user.ID != authorID {
-			candidates = append(candidates, user.ID)
+	var changes []domain.ReviewerChange
+	for _, pr := range prs {
+		if !pr.IsOpen() || !match(pr) {
+			continue
+		}
+
+		change, err := s.replaceReviewer(ctx, pr, userID)
+		if err != nil {
+			return nil, err
 		}
+		if err := s.prRepo.Update(ctx, pr); err != nil {
+			return nil, err
+		}
+		if err := s.recordRelease(ctx, change); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
 	}
 
-	if len(candidates) == 0 {
-		return []string{}, nil
+	if len(changes) > 0 {
+		logger.For(ctx, "service").Info("Reviews released", "reviews", len(changes))
 	}
+	return changes, nil
+}
+
+func (s *PullRequestService) HasOpenPullRequests(ctx context.Context, teamName string) (bool, error) {
+	prs, err := s.prRepo.ListOpenByTeam(ctx, teamName)
+	return len(prs) > 0, err
+}
 
-	maxReviewers := min(2, len(candidates))
-	selected := make([]string, maxReviewers)
+// CloseTeamPullRequests closes the open pull requests of a team without
+// merging them and returns their IDs. Like ReleaseReviews it is meant to
+// run in the caller's transaction.
+func (s *PullRequestService) CloseTeamPullRequests(ctx context.Context, teamName string) (_ []string, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PullRequestService.CloseTeamPullRequests")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
 
-	s.rng.Shuffle(len(candidates), func(i, j int) {
-		candidates[i], candidates[j] = candidates[j], candidates[i]
-	})
+	prs, err := s.prRepo.ListOpenByTeam(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
 
-	copy(selected, candidates[:maxReviewers])
+	var closed []string
+	for _, pr := range prs {
+		pr.Status = domain.PRStatusClosed
+		if err := s.prRepo.Update(ctx, pr); err != nil {
+			return nil, err
+		}
 
-	return selected, nil
+		data, err := json.Marshal(domain.PRClosedData{Reason: "team deleted", ClosedAt: time.Now()})
+		if err != nil {
+			return nil, err
+		}
+		event := &domain.Event{
+			EventType:      domain.EventTypePRClosed,
+			PRID:           pr.ID,
+			UserID:         pr.AuthorID,
+			AdditionalData: data,
+		}
+		if err := s.eventsRepo.CreateEvent(ctx, event); err != nil {
+			return nil, err
+		}
+		closed = append(closed, pr.ID)
+	}
+	return closed, nil
 }
 
-func (s *PullRequestService) findUserTeams(userID string) ([]*domain.Team, error) {
-	user, err := s.userRepo.GetByID(userID)
+// MoveTeamPullRequests moves the open pull requests of a team to another
+// one. Reviewers who are not active members of the target team are replaced
+// from it, or unassigned when it has nobody left to offer.
+func (s *PullRequestService) MoveTeamPullRequests(ctx context.Context, from, to string) (moved []string, _ []domain.ReviewerChange, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "PullRequestService.MoveTeamPullRequests")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	prs, err := s.prRepo.ListOpenByTeam(ctx, from)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	members, err := s.userRepo.GetByTeam(ctx, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	stays := make(map[string]bool, len(members))
+	for _, member := range members {
+		stays[member.ID] = member.IsActive
 	}
 
-	if user.TeamName == "" {
-		return []*domain.Team{}, nil
+	var changes []domain.ReviewerChange
+	for _, pr := range prs {
+		pr.TeamName = to
+
+		var prChanges []domain.ReviewerChange
+		for _, reviewer := range slices.Clone(pr.AssignedReviewers) {
+			if stays[reviewer] {
+				continue
+			}
+			change, err := s.replaceReviewer(ctx, pr, reviewer)
+			if err != nil {
+				return nil, nil, err
+			}
+			prChanges = append(prChanges, change)
+		}
+
+		if err := s.prRepo.Update(ctx, pr); err != nil {
+			return nil, nil, err
+		}
+		for _, change := range prChanges {
+			if err := s.recordRelease(ctx, change); err != nil {
+				return nil, nil, err
+			}
+		}
+		moved = append(moved, pr.ID)
+		changes = append(changes, prChanges...)
 	}
+	return moved, changes, nil
+}
 
-	team, err := s.teamRepo.GetByName(user.TeamName)
+// replaceReviewer swaps oldUserID on pr for a replacement from its team, or
+// drops them when there is none. Only pr is changed; nothing is stored.
+func (s *PullRequestService) replaceReviewer(ctx context.Context, pr *domain.PullRequest, oldUserID string) (domain.ReviewerChange, error) {
+	newReviewer, err := s.findReplacementReviewer(ctx, pr, oldUserID)
+	if err != nil && !errors.Is(err, domain.ErrNoCandidate) {
+		return domain.ReviewerChange{}, err
+	}
+
+	reviewers := pr.AssignedReviewers[:0]
+	for _, reviewer := range pr.AssignedReviewers {
+		switch {
+		case reviewer != oldUserID:
+			reviewers = append(reviewers, reviewer)
+		case newReviewer != "":
+			reviewers = append(reviewers, newReviewer)
+		}
+	}
+	pr.AssignedReviewers = reviewers
+
+	return domain.ReviewerChange{PRID: pr.ID, OldUserID: oldUserID, NewUserID: newReviewer}, nil
+}
+
+func (s *PullRequestService) recordRelease(ctx context.Context, change domain.ReviewerChange) error {
+	event := &domain.Event{PRID: change.PRID}
+	var data any
+	if change.NewUserID != "" {
+		event.EventType = domain.EventTypeReviewerReassigned
+		event.UserID = change.NewUserID
+		data = domain.ReviewerReassignedData{OldUserID: change.OldUserID, NewUserID: change.NewUserID, ReassignedAt: time.Now()}
+		repository.AfterCommit(ctx, func() { metrics.ReviewersReassigned.Inc() })
+	} else {
+		event.EventType = domain.EventTypeReviewerUnassigned
+		event.UserID = change.OldUserID
+		data = domain.ReviewerUnassignedData{OldUserID: change.OldUserID, UnassignedAt: time.Now()}
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	event.AdditionalData = payload
+	return s.eventsRepo.CreateEvent(ctx, event)
+}
+
+// assignReviewers picks the team's number of reviewers from the pools
+// reviewerPools offers. Leads are held back and trainees only join once
+// someone else is picked. The nearest lead steps in when nobody else could
+// be found or when a required maintainer is still missing.
+func (s *PullRequestService) assignReviewers(ctx context.Context, teamName, authorID string) ([]string, error) {
+	policy := s.policy.Load()
+	want := policy.ReviewersFor(teamName)
+	needMaintainer := policy.MaintainerRequired(teamName)
+	selected := []string{}
+	offered := map[string]bool{authorID: true}
+	var leads []string
+	maintained := false
+
+	err := s.reviewerPools(ctx, teamName, func(users []*domain.User) bool {
+		var candidates, trainees []string
+		roles := make(map[string]domain.TeamRole)
+		for _, user := range users {
+			if !user.IsActive || offered[user.ID] {
+				continue
+			}
+			offered[user.ID] = true
+			roles[user.ID] = user.RoleFor(teamName)
+			switch roles[user.ID] {
+			case domain.RoleLead:
+				leads = append(leads, user.ID)
+			case domain.RoleTrainee:
+				trainees = append(trainees, user.ID)
+			default:
+				candidates = append(candidates, user.ID)
+			}
+		}
+		s.shuffle(candidates)
+		s.shuffle(trainees)
+
+		if needMaintainer && !maintained {
+			if i := slices.IndexFunc(candidates, func(id string) bool { return roles[id].Maintains() }); i >= 0 {
+				selected = withReviewer(selected, candidates[i], want)
+				candidates = slices.Delete(candidates, i, i+1)
+				maintained = true
+			}
+		}
+		for _, id := range candidates {
+			if len(selected) == want {
+				break
+			}
+			selected = append(selected, id)
+			maintained = maintained || roles[id].Maintains()
+		}
+		if len(selected) > 0 {
+			n := min(want-len(selected), len(trainees))
+			selected = append(selected, trainees[:n]...)
+		}
+		return len(selected) < want || needMaintainer && !maintained
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return []*domain.Team{team}, nil
+	if len(leads) > 0 && (len(selected) == 0 || needMaintainer && !maintained) {
+		selected = withReviewer(selected, leads[0], want)
+	}
+	return selected, nil
+}
+
+// withReviewer adds id to selected, taking the last place when it is full.
+func withReviewer(selected []string, id string, want int) []string {
+	if len(selected) < want {
+		return append(selected, id)
+	}
+	selected[len(selected)-1] = id
+	return selected
+}
+
+func (s *PullRequestService) shuffle(ids []string) {
+	s.rng.Shuffle(len(ids), func(i, j int) {
+		ids[i], ids[j] = ids[j], ids[i]
+	})
+}
+
+// reviewerPools hands visit the members of a team and then, as far as the
+// team's fallback policy allows, the members of each ancestor's sub-tree,
+// nearest first. Teams are offered once. It stops as soon as visit returns
+// false.
+func (s *PullRequestService) reviewerPools(ctx context.Context, teamName string, visit func(users []*domain.User) bool) error {
+	users, err := s.userRepo.GetByTeam(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !visit(users) {
+		return nil
+	}
+
+	fallback := s.policy.Load().FallbackFor(teamName)
+	if fallback == domain.FallbackNone {
+		return nil
+	}
+
+	team, err := s.teamRepo.GetByName(ctx, teamName)
+	if errors.Is(err, domain.ErrTeamNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	offered := map[string]bool{teamName: true}
+	for parent := team.ParentName; parent != "" && !offered[parent]; {
+		teams, err := s.subTree(ctx, parent, offered)
+		if err != nil {
+			return err
+		}
+
+		var users []*domain.User
+		for _, name := range teams {
+			members, err := s.userRepo.GetByTeam(ctx, name)
+			if err != nil {
+				return err
+			}
+			users = append(users, members...)
+		}
+		if !visit(users) || fallback != domain.FallbackAncestors {
+			return nil
+		}
+
+		next, err := s.teamRepo.GetByName(ctx, parent)
+		if err != nil {
+			return err
+		}
+		parent = next.ParentName
+	}
+	return nil
+}
+
+// subTree returns root and its live descendants that are not in offered yet,
+// adding them to offered. Teams already offered are still walked through so
+// that their own sub-teams are reached.
+func (s *PullRequestService) subTree(ctx context.Context, root string, offered map[string]bool) ([]string, error) {
+	var teams []string
+	queue := []string{root}
+	walked := map[string]bool{root: true}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if !offered[name] {
+			offered[name] = true
+			teams = append(teams, name)
+		}
+
+		children, err := s.teamRepo.ListSubTeams(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range children {
+			if !walked[child] {
+				walked[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return teams, nil
+}
+
+// reviewTeam picks the team a new pull request draws its reviewers from: the
+// requested one, which the author must belong to, or else the author's only
+// team.
+func (s *PullRequestService) reviewTeam(ctx context.Context, author *domain.User, requested string) (string, error) {
+	if requested != "" {
+		if author.InTeam(requested) {
+			return requested, nil
+		}
+		exists, err := s.teamRepo.Exists(ctx, requested)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return "", domain.ErrTeamNotFound
+		}
+		return "", domain.ErrNotTeamMember
+	}
+
+	switch len(author.Teams) {
+	case 0:
+		return "", domain.ErrTeamNotFound
+	case 1:
+		return author.Teams[0], nil
+	default:
+		return "", domain.ErrTeamAmbiguous
+	}
 }
 
 func (s *PullRequestService) isUserAssigned(reviewers []string, userID string) bool {
@@ -290,39 +663,109 @@ func (s *PullRequestService) isUserAssigned(reviewers []string, userID string) b
 	return false
 }
 
-func (s *PullRequestService) findReplacementReviewer(prID, oldUserID string) (string, error) {
-	pr, err := s.prRepo.GetByID(prID)
-	if err != nil {
-		return "", err
+// findReplacementReviewer follows the role rules of assignReviewers: a lead
+// is only the last resort, a trainee may not end up as the only reviewer,
+// and a required maintainer is looked for first when oldUserID was the last
+// one on the pull request.
+func (s *PullRequestService) findReplacementReviewer(ctx context.Context, pr *domain.PullRequest, oldUserID string) (string, error) {
+	needMaintainer := false
+	if s.policy.Load().MaintainerRequired(pr.TeamName) {
+		roles, err := s.remainingRoles(ctx, pr, oldUserID)
+		if err != nil {
+			return "", err
+		}
+		needMaintainer = !slices.ContainsFunc(roles, domain.TeamRole.Maintains)
+	}
+
+	var candidates, trainees, leads []string
+	roles := make(map[string]domain.TeamRole)
+	collect := func(users []*domain.User) bool {
+		for _, user := range users {
+			if user.IsActive &&
+				roles[user.ID] == "" &&
+				user.ID != pr.AuthorID &&
+				user.ID != oldUserID &&
+				!s.isUserAssigned(pr.AssignedReviewers, user.ID) {
+				roles[user.ID] = user.RoleFor(pr.TeamName)
+				switch roles[user.ID] {
+				case domain.RoleLead:
+					leads = append(leads, user.ID)
+				case domain.RoleTrainee:
+					trainees = append(trainees, user.ID)
+				default:
+					candidates = append(candidates, user.ID)
+				}
+			}
+		}
+		if needMaintainer {
+			return !slices.ContainsFunc(candidates, func(id string) bool { return roles[id].Maintains() })
+		}
+		return len(candidates) == 0
 	}
 
-	oldReviewer, err := s.userRepo.GetByID(oldUserID)
-	if err != nil {
-		return "", err
+	if pr.TeamName != "" {
+		if err := s.reviewerPools(ctx, pr.TeamName, collect); err != nil {
+			return "", err
+		}
+	} else {
+		// Pull requests from before teams were recorded on them draw from
+		// every team of the reviewer being replaced.
+		oldReviewer, err := s.userRepo.GetByID(ctx, oldUserID)
+		if err != nil {
+			return "", err
+		}
+		for _, team := range oldReviewer.Teams {
+			teamUsers, err := s.userRepo.GetByTeam(ctx, team)
+			if err != nil {
+				return "", err
+			}
+			collect(teamUsers)
+		}
 	}
 
-	if oldReviewer.TeamName == "" {
-		return "", domain.ErrNoCandidate
+	if needMaintainer {
+		maintainers := slices.DeleteFunc(slices.Clone(candidates), func(id string) bool { return !roles[id].Maintains() })
+		if len(maintainers) > 0 {
+			return maintainers[s.rng.Intn(len(maintainers))], nil
+		}
+		if len(leads) > 0 {
+			return leads[0], nil
+		}
 	}
-
-	teamUsers, err := s.userRepo.GetByTeam(oldReviewer.TeamName)
-	if err != nil {
-		return "", err
+	if len(candidates) > 0 {
+		return candidates[s.rng.Intn(len(candidates))], nil
 	}
-
-	var candidates []string
-	for _, user := range teamUsers {
-		if user.IsActive &&
-			user.ID != pr.AuthorID &&
-			user.ID != oldUserID &&
-			!s.isUserAssigned(pr.AssignedReviewers, user.ID) {
-			candidates = append(candidates, user.ID)
+	if len(trainees) > 0 {
+		remaining, err := s.remainingRoles(ctx, pr, oldUserID)
+		if err != nil {
+			return "", err
+		}
+		if slices.ContainsFunc(remaining, func(r domain.TeamRole) bool { return r != domain.RoleTrainee }) {
+			return trainees[s.rng.Intn(len(trainees))], nil
 		}
 	}
-
-	if len(candidates) == 0 {
-		return "", domain.ErrNoCandidate
+	if len(leads) > 0 {
+		return leads[0], nil
 	}
+	return "", domain.ErrNoCandidate
+}
 
-	return candidates[s.rng.Intn(len(candidates))], nil
+// remainingRoles returns the roles of the reviewers that stay on pr once
+// oldUserID is gone.
+func (s *PullRequestService) remainingRoles(ctx context.Context, pr *domain.PullRequest, oldUserID string) ([]domain.TeamRole, error) {
+	var roles []domain.TeamRole
+	for _, id := range pr.AssignedReviewers {
+		if id == oldUserID {
+			continue
+		}
+		user, err := s.userRepo.GetByID(ctx, id)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, user.RoleFor(pr.TeamName))
+	}
+	return roles, nil
 }
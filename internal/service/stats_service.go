@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
+
 	"github.com/111zxc/pr-review-service/internal/domain"
 	"github.com/111zxc/pr-review-service/internal/repository"
+	"github.com/111zxc/pr-review-service/internal/tracing"
 )
 
 type StatsService struct {
@@ -13,6 +16,16 @@ func NewStatsService(statsRepo repository.StatsRepository) *StatsService {
 	return &StatsService{statsRepo: statsRepo}
 }
 
-func (s *StatsService) GetStats() (*domain.StatsResponse, error) {
-	return s.statsRepo.GetEventStats()
+func (s *StatsService) GetStats(ctx context.Context) (_ *domain.StatsResponse, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "StatsService.GetStats")
+	defer func() { tracing.End(span, err) }()
+
+	return s.statsRepo.GetEventStats(ctx)
+}
+
+func (s *StatsService) GetOpenReviewsByTeam(ctx context.Context) (_ map[string]int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "StatsService.GetOpenReviewsByTeam")
+	defer func() { tracing.End(span, err) }()
+
+	return s.statsRepo.GetOpenReviewsByTeam(ctx)
 }
@@ -1,46 +1,649 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/111zxc/pr-review-service/internal/auth"
 	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/logger"
 	"github.com/111zxc/pr-review-service/internal/repository"
+	"github.com/111zxc/pr-review-service/internal/tracing"
 )
 
 type TeamService struct {
 	teamRepo repository.TeamRepository
 	userRepo repository.UserRepository
+	tx       repository.Transactor
+	reviews  *PullRequestService
 }
 
-func NewTeamService(teamRepo repository.TeamRepository, userRepo repository.UserRepository) *TeamService {
+// NewTeamService wires the team service. reviews releases the open reviews
+// of members leaving a team.
+func NewTeamService(
+	teamRepo repository.TeamRepository,
+	userRepo repository.UserRepository,
+	tx repository.Transactor,
+	reviews *PullRequestService,
+) *TeamService {
 	return &TeamService{
 		teamRepo: teamRepo,
 		userRepo: userRepo,
+		tx:       tx,
+		reviews:  reviews,
 	}
 }
 
-func (s *TeamService) CreateTeam(team *domain.Team) error {
-	exists, err := s.teamRepo.Exists(team.Name)
+// CreateTeamOptions controls how CreateTeam treats members that already
+// exist as users.
+type CreateTeamOptions struct {
+	// DryRun only computes the member diff; nothing is written.
+	DryRun     bool
+	OnConflict domain.ConflictPolicy
+}
+
+// CreateTeam creates the team and its members' users and returns what
+// happened to each member. Under ConflictReject it fails with a
+// *domain.MemberConflictError instead of changing users that other teams
+// rely on; a dry run reports such conflicts in the diff instead.
+func (s *TeamService) CreateTeam(ctx context.Context, team *domain.Team, opts CreateTeamOptions) (_ []domain.MemberDiff, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.CreateTeam")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	var diffs []domain.MemberDiff
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		exists, err := s.teamRepo.Exists(ctx, team.Name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrTeamExists
+		}
+		if team.ParentName != "" {
+			parent, err := s.teamRepo.Exists(ctx, team.ParentName)
+			if err != nil {
+				return err
+			}
+			if !parent {
+				return domain.ErrTeamNotFound
+			}
+		}
+
+		diffs, err = s.diffMembers(ctx, team.Members)
+		if err != nil || opts.DryRun {
+			return err
+		}
+
+		if opts.OnConflict != domain.ConflictUpsert {
+			var conflicts []domain.MemberDiff
+			for _, d := range diffs {
+				if d.Conflicts() {
+					conflicts = append(conflicts, d)
+				}
+			}
+			if len(conflicts) > 0 {
+				return &domain.MemberConflictError{Conflicts: conflicts}
+			}
+		}
+
+		for _, d := range diffs {
+			if d.Action == domain.MemberUnchanged {
+				continue
+			}
+			user := &domain.User{
+				ID:       d.UserID,
+				Username: d.Username,
+				IsActive: d.IsActive,
+			}
+			if err := s.userRepo.Create(ctx, user); err != nil {
+				return err
+			}
+		}
+
+		return s.teamRepo.Create(ctx, team)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if exists {
-		return domain.ErrTeamExists
+
+	if !opts.DryRun {
+		logger.For(ctx, "service").Info("Team created", "members", len(team.Members))
 	}
+	return diffs, nil
+}
 
-	for _, member := range team.Members {
-		user := &domain.User{
-			ID:       member.UserID,
+func (s *TeamService) diffMembers(ctx context.Context, members []domain.TeamMember) ([]domain.MemberDiff, error) {
+	diffs := make([]domain.MemberDiff, 0, len(members))
+	for _, member := range members {
+		diff := domain.MemberDiff{
+			UserID:   member.UserID,
+			Action:   domain.MemberCreate,
 			Username: member.Username,
 			IsActive: member.IsActive,
 		}
 
-		if err := s.userRepo.Create(user); err != nil {
+		existing, err := s.userRepo.GetByID(ctx, member.UserID)
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			diff.OldUsername = existing.Username
+			diff.OldIsActive = existing.IsActive
+			diff.OtherTeams = existing.Teams
+			diff.Action = domain.MemberUnchanged
+			if existing.Username != member.Username || existing.IsActive != member.IsActive {
+				diff.Action = domain.MemberUpdate
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, nil
+}
+
+func (s *TeamService) GetTeam(ctx context.Context, ref domain.TeamRef) (_ *domain.Team, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.GetTeam")
+	defer func() { tracing.End(span, err) }()
+
+	team, err := s.findTeam(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillTree(ctx, team); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// ListTeams returns every live team with its members, sorted by name.
+func (s *TeamService) ListTeams(ctx context.Context) (_ []*domain.Team, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.ListTeams")
+	defer func() { tracing.End(span, err) }()
+
+	return s.teamRepo.List(ctx)
+}
+
+// fillTree sets the team's ancestors and sub-teams.
+func (s *TeamService) fillTree(ctx context.Context, team *domain.Team) error {
+	seen := map[string]bool{team.Name: true}
+	for parent := team.ParentName; parent != "" && !seen[parent]; {
+		seen[parent] = true
+		team.Ancestors = append(team.Ancestors, parent)
+
+		next, err := s.teamRepo.GetByName(ctx, parent)
+		if err != nil {
+			return err
+		}
+		parent = next.ParentName
+	}
+
+	var err error
+	team.SubTeams, err = s.subTeams(ctx, team.Name, map[string]bool{team.Name: true})
+	return err
+}
+
+func (s *TeamService) subTeams(ctx context.Context, name string, seen map[string]bool) ([]domain.TeamNode, error) {
+	children, err := s.teamRepo.ListSubTeams(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []domain.TeamNode
+	for _, child := range children {
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+
+		node := domain.TeamNode{Name: child}
+		if node.SubTeams, err = s.subTeams(ctx, child, seen); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// SetParent nests a team under parentName, or makes it top-level when
+// parentName is empty. A team cannot be nested under itself or one of its
+// sub-teams.
+func (s *TeamService) SetParent(ctx context.Context, ref domain.TeamRef, parentName string) (_ *domain.Team, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.SetParent")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	var team *domain.Team
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.findTeam(ctx, ref)
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]bool)
+		for ancestor := parentName; ancestor != "" && !seen[ancestor]; {
+			if ancestor == current.Name {
+				return domain.ErrTeamCycle
+			}
+			seen[ancestor] = true
+			next, err := s.teamRepo.GetByName(ctx, ancestor)
+			if err != nil {
+				return err
+			}
+			ancestor = next.ParentName
+		}
+
+		if err := s.teamRepo.SetParent(ctx, current.Name, parentName); err != nil {
+			return err
+		}
+		if team, err = s.teamRepo.GetByID(ctx, current.ID); err != nil {
+			return err
+		}
+		return s.fillTree(ctx, team)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(ctx, "service").Info("Team parent set", "parent_team", parentName)
+	return team, nil
+}
+
+func (s *TeamService) findTeam(ctx context.Context, ref domain.TeamRef) (*domain.Team, error) {
+	if ref.ID != "" {
+		return s.teamRepo.GetByID(ctx, ref.ID)
+	}
+	return s.teamRepo.GetByName(ctx, ref.Name)
+}
+
+// RenameTeam changes a team's name. The ID stays, so members and pull
+// requests follow without being touched.
+func (s *TeamService) RenameTeam(ctx context.Context, ref domain.TeamRef, newName string) (_ *domain.Team, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.RenameTeam")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	if newName == "" {
+		return nil, domain.ErrInvalidInput
+	}
+
+	var team, current *domain.Team
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if current, err = s.findTeam(ctx, ref); err != nil {
+			return err
+		}
+		if current.Name == newName {
+			team = current
+			return nil
+		}
+
+		if err := s.teamRepo.Rename(ctx, current.Name, newName); err != nil {
+			return err
+		}
+		team, err = s.teamRepo.GetByID(ctx, current.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(ctx, "service").Info("Team renamed", "old_name", current.Name, "new_name", team.Name)
+	return team, nil
+}
+
+// AddMembersOptions controls how AddMembers treats users that already exist.
+type AddMembersOptions struct {
+	OnConflict domain.ConflictPolicy
+}
+
+// AddMembers creates the given users and adds them to the team. Under
+// ConflictReject it fails with a *domain.MemberConflictError instead of
+// changing the username or active flag of an existing user; belonging to
+// other teams is not a conflict here.
+func (s *TeamService) AddMembers(ctx context.Context, teamName string, members []domain.TeamMember, opts AddMembersOptions) (_ *domain.Team, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.AddMembers")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	var team *domain.Team
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		diffs, err := s.diffMembers(ctx, members)
+		if err != nil {
+			return err
+		}
+
+		if opts.OnConflict != domain.ConflictUpsert {
+			var conflicts []domain.MemberDiff
+			for _, d := range diffs {
+				if d.Action == domain.MemberUpdate {
+					conflicts = append(conflicts, d)
+				}
+			}
+			if len(conflicts) > 0 {
+				return &domain.MemberConflictError{Conflicts: conflicts}
+			}
+		}
+
+		userIDs := make([]string, 0, len(diffs))
+		for _, d := range diffs {
+			userIDs = append(userIDs, d.UserID)
+			if d.Action == domain.MemberUnchanged {
+				continue
+			}
+			user := &domain.User{
+				ID:       d.UserID,
+				Username: d.Username,
+				IsActive: d.IsActive,
+			}
+			if err := s.userRepo.Create(ctx, user); err != nil {
+				return err
+			}
+		}
+
+		if err := s.teamRepo.AddMembers(ctx, teamName, userIDs); err != nil {
 			return err
 		}
+		for _, member := range members {
+			if member.Role == "" {
+				continue
+			}
+			if err := s.teamRepo.SetRole(ctx, teamName, member.UserID, member.Role); err != nil {
+				return err
+			}
+		}
+
+		team, err = s.teamRepo.GetByName(ctx, teamName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(ctx, "service").Info("Team members added", "members", len(members))
+	return team, nil
+}
+
+// SetMemberRoleOptions controls SetMemberRole.
+type SetMemberRoleOptions struct {
+	// Force skips the lead check. Only the admin endpoint sets it.
+	Force bool
+}
+
+// SetMemberRole changes the role of a team member. Unless opts.Force is
+// set, the caller must lead the team or, while it has no lead, a team above
+// it; a request without a principal fails with domain.ErrNotTeamLead.
+func (s *TeamService) SetMemberRole(ctx context.Context, ref domain.TeamRef, userID string, role domain.TeamRole, opts SetMemberRoleOptions) (_ *domain.Team, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.SetMemberRole")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	var team *domain.Team
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.findTeam(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if !opts.Force {
+			principal, ok := auth.PrincipalFromContext(ctx)
+			if !ok {
+				return domain.ErrNotTeamLead
+			}
+			allowed, err := s.managesRoles(ctx, principal, current)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return domain.ErrNotTeamLead
+			}
+		}
+
+		if err := s.teamRepo.SetRole(ctx, current.Name, userID, role); err != nil {
+			return err
+		}
+		team, err = s.teamRepo.GetByID(ctx, current.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(ctx, "service").Info("Team member role set", "user_id", userID, "role", role)
+	return team, nil
+}
+
+// managesRoles reports whether principal leads the team or, when the team
+// has no lead, one of its live ancestors. Members cannot appoint themselves.
+func (s *TeamService) managesRoles(ctx context.Context, principal *auth.Principal, team *domain.Team) (bool, error) {
+	if principal.HasRole(team.Name, domain.RoleLead) {
+		return true, nil
 	}
+	hasLead := slices.ContainsFunc(team.Members, func(m domain.TeamMember) bool {
+		return m.Role == domain.RoleLead
+	})
+	if hasLead {
+		return false, nil
+	}
+
+	seen := map[string]bool{team.Name: true}
+	for parent := team.ParentName; parent != "" && !seen[parent]; {
+		if principal.HasRole(parent, domain.RoleLead) {
+			return true, nil
+		}
+		seen[parent] = true
 
-	return s.teamRepo.Create(team)
+		next, err := s.teamRepo.GetByName(ctx, parent)
+		switch {
+		case errors.Is(err, domain.ErrTeamNotFound):
+			return false, nil
+		case err != nil:
+			return false, err
+		}
+		parent = next.ParentName
+	}
+	return false, nil
 }
 
-func (s *TeamService) GetTeam(name string) (*domain.Team, error) {
-	return s.teamRepo.GetByName(name)
+// RemoveMember takes userID out of the team and hands the open reviews they
+// hold for it to the remaining members.
+func (s *TeamService) RemoveMember(ctx context.Context, teamName, userID string) (_ []domain.ReviewerChange, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.RemoveMember")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	var changes []domain.ReviewerChange
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.teamRepo.RemoveMember(ctx, teamName, userID); err != nil {
+			return err
+		}
+
+		var err error
+		changes, err = s.reviews.ReleaseReviews(ctx, teamName, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(ctx, "service").Info("Team member removed", "reviews_released", len(changes))
+	return changes, nil
+}
+
+// TransferMember moves userID from one team to another. Open reviews in the
+// old team are released as in RemoveMember.
+func (s *TeamService) TransferMember(ctx context.Context, userID, fromTeam, toTeam string) (_ []domain.ReviewerChange, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.TransferMember")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	if fromTeam == toTeam {
+		return nil, domain.ErrInvalidInput
+	}
+
+	var changes []domain.ReviewerChange
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.teamRepo.RemoveMember(ctx, fromTeam, userID); err != nil {
+			return err
+		}
+		if err := s.teamRepo.AddMembers(ctx, toTeam, []string{userID}); err != nil {
+			return err
+		}
+
+		var err error
+		changes, err = s.reviews.ReleaseReviews(ctx, fromTeam, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(ctx, "service").Info("Team member transferred", "reviews_released", len(changes))
+	return changes, nil
+}
+
+// SetMembers makes userIDs the members of the team. Users must exist;
+// members who leave have their open reviews released as in RemoveMember.
+func (s *TeamService) SetMembers(ctx context.Context, ref domain.TeamRef, userIDs []string) (_ *domain.Team, _ []domain.ReviewerChange, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.SetMembers")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	var team *domain.Team
+	var changes []domain.ReviewerChange
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.findTeam(ctx, ref)
+		if err != nil {
+			return err
+		}
+
+		var joining []string
+		for _, userID := range userIDs {
+			if current.HasMember(userID) || slices.Contains(joining, userID) {
+				continue
+			}
+			if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+				return err
+			}
+			joining = append(joining, userID)
+		}
+		if len(joining) > 0 {
+			if err := s.teamRepo.AddMembers(ctx, current.Name, joining); err != nil {
+				return err
+			}
+		}
+
+		for _, member := range current.Members {
+			if slices.Contains(userIDs, member.UserID) {
+				continue
+			}
+			if err := s.teamRepo.RemoveMember(ctx, current.Name, member.UserID); err != nil {
+				return err
+			}
+			released, err := s.reviews.ReleaseReviews(ctx, current.Name, member.UserID)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, released...)
+		}
+
+		team, err = s.teamRepo.GetByID(ctx, current.ID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger.For(ctx, "service").Info("Team members set", "members", len(team.Members), "reviews_released", len(changes))
+	return team, changes, nil
+}
+
+// DeleteTeamOptions says what happens to the open pull requests of a team
+// being deleted.
+type DeleteTeamOptions struct {
+	OpenPRs domain.OpenPRPolicy
+	// ReassignTo is the target team for domain.OpenPRsReassign.
+	ReassignTo string
+}
+
+// DeleteTeam soft-deletes a team. Its members, pull requests and events are
+// kept, but it no longer takes part in reviewer assignment.
+func (s *TeamService) DeleteTeam(ctx context.Context, name string, opts DeleteTeamOptions) (_ *domain.TeamDeletion, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.DeleteTeam")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	if opts.OpenPRs == domain.OpenPRsReassign && (opts.ReassignTo == "" || opts.ReassignTo == name) {
+		return nil, domain.ErrInvalidInput
+	}
+
+	deletion := &domain.TeamDeletion{}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		exists, err := s.teamRepo.Exists(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrTeamNotFound
+		}
+
+		switch opts.OpenPRs {
+		case domain.OpenPRsClose:
+			if deletion.ClosedPRs, err = s.reviews.CloseTeamPullRequests(ctx, name); err != nil {
+				return err
+			}
+		case domain.OpenPRsReassign:
+			target, err := s.teamRepo.Exists(ctx, opts.ReassignTo)
+			if err != nil {
+				return err
+			}
+			if !target {
+				return domain.ErrTeamNotFound
+			}
+			if deletion.MovedPRs, deletion.Reviews, err = s.reviews.MoveTeamPullRequests(ctx, name, opts.ReassignTo); err != nil {
+				return err
+			}
+		default:
+			open, err := s.reviews.HasOpenPullRequests(ctx, name)
+			if err != nil {
+				return err
+			}
+			if open {
+				return domain.ErrTeamHasOpenPRs
+			}
+		}
+
+		return s.teamRepo.Delete(ctx, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(ctx, "service").Info("Team deleted",
+		"closed_prs", len(deletion.ClosedPRs), "moved_prs", len(deletion.MovedPRs))
+	return deletion, nil
+}
+
+// RestoreTeam brings back a soft-deleted team with the members it had.
+func (s *TeamService) RestoreTeam(ctx context.Context, name string) (_ *domain.Team, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.RestoreTeam")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	var team *domain.Team
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.teamRepo.Restore(ctx, name); err != nil {
+			return err
+		}
+
+		var err error
+		team, err = s.teamRepo.GetByName(ctx, name)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.For(ctx, "service").Info("Team restored")
+	return team, nil
 }
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/repository"
+	"github.com/111zxc/pr-review-service/internal/tracing"
+)
+
+// SyncOptions controls SyncRoster.
+type SyncOptions struct {
+	// DryRun only computes the plan; nothing is written.
+	DryRun bool
+	// OpenPRs says what happens to the open pull requests of teams missing
+	// from the roster. Only domain.OpenPRsReject and domain.OpenPRsClose
+	// are supported, since a sync has no team to move them to.
+	OpenPRs domain.OpenPRPolicy
+}
+
+// SyncRoster makes the live teams match roster in one transaction: missing
+// teams are deleted, memberships added, moved and removed, roles and users
+// updated, and users who are in no roster team deactivated. Reviews held
+// by members leaving a team are released as in RemoveMember, and every open
+// review of a deactivated user as in UserService.SetUserActive. It fails
+// with a *domain.RosterError for an invalid roster, and with
+// domain.ErrTeamHasOpenPRs when a team to delete has open pull requests
+// and opts.OpenPRs rejects them, dry run or not.
+func (s *TeamService) SyncRoster(ctx context.Context, roster []*domain.Team, opts SyncOptions) (_ *domain.SyncPlan, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "TeamService.SyncRoster")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	if opts.OpenPRs != domain.OpenPRsReject && opts.OpenPRs != domain.OpenPRsClose {
+		return nil, domain.ErrInvalidInput
+	}
+	if err := domain.ValidateRoster(roster); err != nil {
+		return nil, err
+	}
+
+	var plan *domain.SyncPlan
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.teamRepo.List(ctx)
+		if err != nil {
+			return err
+		}
+		plan, err = s.planSync(ctx, roster, current)
+		if err != nil {
+			return err
+		}
+
+		if opts.OpenPRs == domain.OpenPRsReject {
+			for _, c := range plan.Changes {
+				if c.Action != domain.SyncDeleteTeam {
+					continue
+				}
+				open, err := s.reviews.HasOpenPullRequests(ctx, c.Team)
+				if err != nil {
+					return err
+				}
+				if open {
+					return domain.ErrTeamHasOpenPRs
+				}
+			}
+		}
+
+		if opts.DryRun {
+			return nil
+		}
+		return s.applySync(ctx, plan, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.DryRun {
+		logger.For(ctx, "service").Info("Roster synced",
+			"changes", len(plan.Changes), "closed_prs", len(plan.ClosedPRs), "reviews_released", len(plan.Reviews))
+	}
+	return plan, nil
+}
+
+// planSync orders the changes so that each can be applied on its own:
+// teams before their members, parents before sub-teams, user updates
+// before memberships, and joins before leaves so that released reviews can
+// go to the members who join.
+func (s *TeamService) planSync(ctx context.Context, roster, current []*domain.Team) (*domain.SyncPlan, error) {
+	wanted := make(map[string]*domain.Team, len(roster))
+	for _, team := range roster {
+		wanted[team.Name] = team
+	}
+	live := make(map[string]*domain.Team, len(current))
+	for _, team := range current {
+		live[team.Name] = team
+	}
+
+	plan := &domain.SyncPlan{}
+	add := func(c domain.SyncChange) {
+		plan.Changes = append(plan.Changes, c)
+	}
+
+	for _, team := range current {
+		if wanted[team.Name] == nil {
+			add(domain.SyncChange{Action: domain.SyncDeleteTeam, Team: team.Name})
+		}
+	}
+	created := make(map[string]bool)
+	var create func(team *domain.Team)
+	create = func(team *domain.Team) {
+		if live[team.Name] != nil || created[team.Name] {
+			return
+		}
+		created[team.Name] = true
+		if team.ParentName != "" {
+			create(wanted[team.ParentName])
+		}
+		add(domain.SyncChange{Action: domain.SyncCreateTeam, Team: team.Name, Parent: team.ParentName})
+	}
+	for _, team := range roster {
+		create(team)
+	}
+	for _, team := range roster {
+		if have := live[team.Name]; have != nil && have.ParentName != team.ParentName {
+			add(domain.SyncChange{Action: domain.SyncSetParent, Team: team.Name, Parent: team.ParentName})
+		}
+	}
+
+	// Members of teams about to be deleted count as known users, but their
+	// memberships stay with the deleted team.
+	users := make(map[string]domain.TeamMember)
+	memberOf := make(map[string]map[string]domain.TeamRole)
+	for _, team := range current {
+		for _, m := range team.Members {
+			users[m.UserID] = m
+			if wanted[team.Name] != nil {
+				setRole(memberOf, m.UserID, team.Name, m.Role)
+			}
+		}
+	}
+	wantedUsers := make(map[string]domain.TeamMember)
+	wantedOf := make(map[string]map[string]domain.TeamRole)
+	for _, team := range roster {
+		for _, m := range team.Members {
+			wantedUsers[m.UserID] = m
+			setRole(wantedOf, m.UserID, team.Name, m.Role)
+		}
+	}
+
+	ids := slices.Collect(maps.Keys(users))
+	for id := range wantedUsers {
+		if _, ok := users[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		want, listed := wantedUsers[id]
+		have, known := users[id]
+		if listed && !known {
+			existing, err := s.userRepo.GetByID(ctx, id)
+			switch {
+			case errors.Is(err, domain.ErrUserNotFound):
+			case err != nil:
+				return nil, err
+			default:
+				have = domain.TeamMember{UserID: id, Username: existing.Username, IsActive: existing.IsActive}
+				known = true
+			}
+		}
+
+		change := domain.SyncChange{UserID: id, Username: want.Username, IsActive: want.IsActive}
+		switch {
+		case !listed:
+			if !have.IsActive {
+				continue
+			}
+			change.Action, change.Username = domain.SyncDeactivateUser, have.Username
+		case !known:
+			change.Action = domain.SyncCreateUser
+		case have.IsActive && !want.IsActive:
+			change.Action = domain.SyncDeactivateUser
+		case have.Username != want.Username || have.IsActive != want.IsActive:
+			change.Action = domain.SyncUpdateUser
+		default:
+			continue
+		}
+		add(change)
+	}
+
+	var joins, roles, transfers, leaves []domain.SyncChange
+	for _, id := range ids {
+		var joined, left []string
+		for _, team := range slices.Sorted(maps.Keys(wantedOf[id])) {
+			role := wantedOf[id][team]
+			have, ok := memberOf[id][team]
+			switch {
+			case !ok:
+				joined = append(joined, team)
+			case have != role:
+				roles = append(roles, domain.SyncChange{Action: domain.SyncSetRole, Team: team, UserID: id, Role: role})
+			}
+		}
+		for _, team := range slices.Sorted(maps.Keys(memberOf[id])) {
+			if _, ok := wantedOf[id][team]; !ok {
+				left = append(left, team)
+			}
+		}
+
+		// A user who leaves one team and joins another is moved; pairing
+		// is by name order when there are several.
+		moved := min(len(joined), len(left))
+		for i := range moved {
+			transfers = append(transfers, domain.SyncChange{
+				Action: domain.SyncTransferMember, Team: joined[i], FromTeam: left[i], UserID: id, Role: wantedOf[id][joined[i]],
+			})
+		}
+		for _, team := range joined[moved:] {
+			joins = append(joins, domain.SyncChange{Action: domain.SyncAddMember, Team: team, UserID: id, Role: wantedOf[id][team]})
+		}
+		for _, team := range left[moved:] {
+			leaves = append(leaves, domain.SyncChange{Action: domain.SyncRemoveMember, Team: team, UserID: id})
+		}
+	}
+	plan.Changes = slices.Concat(plan.Changes, joins, roles, transfers, leaves)
+
+	return plan, nil
+}
+
+func setRole(roles map[string]map[string]domain.TeamRole, userID, team string, role domain.TeamRole) {
+	if roles[userID] == nil {
+		roles[userID] = make(map[string]domain.TeamRole)
+	}
+	if role == "" {
+		role = domain.RoleMember
+	}
+	roles[userID][team] = role
+}
+
+func (s *TeamService) applySync(ctx context.Context, plan *domain.SyncPlan, opts SyncOptions) error {
+	for _, c := range plan.Changes {
+		var err error
+		switch c.Action {
+		case domain.SyncDeleteTeam:
+			if opts.OpenPRs == domain.OpenPRsClose {
+				var closed []string
+				if closed, err = s.reviews.CloseTeamPullRequests(ctx, c.Team); err != nil {
+					return err
+				}
+				plan.ClosedPRs = append(plan.ClosedPRs, closed...)
+			}
+			err = s.teamRepo.Delete(ctx, c.Team)
+		case domain.SyncCreateTeam:
+			err = s.teamRepo.Create(ctx, &domain.Team{Name: c.Team, ParentName: c.Parent})
+		case domain.SyncSetParent:
+			err = s.teamRepo.SetParent(ctx, c.Team, c.Parent)
+		case domain.SyncCreateUser, domain.SyncUpdateUser, domain.SyncDeactivateUser:
+			// Deactivated users are marked inactive here already, so
+			// that no review released below goes to them.
+			err = s.userRepo.Create(ctx, &domain.User{ID: c.UserID, Username: c.Username, IsActive: c.IsActive})
+		case domain.SyncAddMember:
+			err = s.join(ctx, c.Team, c.UserID, c.Role)
+		case domain.SyncSetRole:
+			err = s.teamRepo.SetRole(ctx, c.Team, c.UserID, c.Role)
+		case domain.SyncTransferMember:
+			if err = s.join(ctx, c.Team, c.UserID, c.Role); err == nil {
+				err = s.leave(ctx, plan, c.FromTeam, c.UserID)
+			}
+		case domain.SyncRemoveMember:
+			err = s.leave(ctx, plan, c.Team, c.UserID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Deactivated users hand over all their open reviews as in
+	// UserService.SetUserActive, once the new memberships are in place to
+	// pick replacements from.
+	var deactivated []*domain.User
+	for _, c := range plan.Changes {
+		if c.Action == domain.SyncDeactivateUser {
+			deactivated = append(deactivated, &domain.User{ID: c.UserID, Username: c.Username})
+		}
+	}
+	released, err := deactivate(ctx, s.userRepo, s.reviews, deactivated...)
+	if err != nil {
+		return err
+	}
+	plan.Reviews = append(plan.Reviews, released...)
+	return nil
+}
+
+func (s *TeamService) join(ctx context.Context, team, userID string, role domain.TeamRole) error {
+	if err := s.teamRepo.AddMembers(ctx, team, []string{userID}); err != nil {
+		return err
+	}
+	if role == domain.RoleMember {
+		return nil
+	}
+	return s.teamRepo.SetRole(ctx, team, userID, role)
+}
+
+func (s *TeamService) leave(ctx context.Context, plan *domain.SyncPlan, team, userID string) error {
+	if err := s.teamRepo.RemoveMember(ctx, team, userID); err != nil {
+		return err
+	}
+	changes, err := s.reviews.ReleaseReviews(ctx, team, userID)
+	if err != nil {
+		return err
+	}
+	plan.Reviews = append(plan.Reviews, changes...)
+	return nil
+}
@@ -1,29 +1,187 @@
 package service
 
 import (
+	"context"
+	"errors"
+
 	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/logger"
 	"github.com/111zxc/pr-review-service/internal/repository"
+	"github.com/111zxc/pr-review-service/internal/tracing"
 )
 
 type UserService struct {
 	userRepo repository.UserRepository
+	tx       repository.Transactor
+	reviews  *PullRequestService
 }
 
-func NewUserService(userRepo repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+// NewUserService wires the user service. reviews releases the open reviews
+// of deactivated users.
+func NewUserService(userRepo repository.UserRepository, tx repository.Transactor, reviews *PullRequestService) *UserService {
+	return &UserService{userRepo: userRepo, tx: tx, reviews: reviews}
 }
 
-func (s *UserService) SetUserActive(userID string, isActive bool) (*domain.User, error) {
-	user, err := s.userRepo.GetByID(userID)
+// SetUserActive activates or deactivates a user. A deactivated user hands
+// over all their open reviews, as in a roster sync.
+func (s *UserService) SetUserActive(ctx context.Context, userID string, isActive bool) (_ *domain.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.SetUserActive")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	var (
+		user     *domain.User
+		released []domain.ReviewerChange
+	)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if user, err = s.userRepo.GetByID(ctx, userID); err != nil {
+			return err
+		}
+		if !isActive {
+			released, err = deactivate(ctx, s.userRepo, s.reviews, user)
+			return err
+		}
+		user.IsActive = true
+		return s.userRepo.Update(ctx, user)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	user.IsActive = isActive
+	logger.For(ctx, "service").Info("User activity changed", "is_active", isActive, "reviews_released", len(released))
+	return user, nil
+}
+
+// deactivate marks users inactive, then hands all their open reviews to
+// other members. Marking them all first keeps one deactivated user from
+// taking over another's reviews. Callers run it in a transaction.
+func deactivate(ctx context.Context, userRepo repository.UserRepository, reviews *PullRequestService, users ...*domain.User) ([]domain.ReviewerChange, error) {
+	for _, user := range users {
+		user.IsActive = false
+		if err := userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	var released []domain.ReviewerChange
+	for _, user := range users {
+		changes, err := reviews.ReleaseAllReviews(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		released = append(released, changes...)
+	}
+	return released, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, userID string) (_ *domain.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.GetUser")
+	defer func() { tracing.End(span, err) }()
+
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// ListUsers returns a page of the users matching filter and their total
+// number.
+func (s *UserService) ListUsers(ctx context.Context, filter domain.UserFilter) (_ []*domain.User, _ int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.ListUsers")
+	defer func() { tracing.End(span, err) }()
+
+	return s.userRepo.List(ctx, filter)
+}
+
+// CreateUser creates a user that is in no team yet; it fails with
+// domain.ErrUserExists instead of overwriting one.
+func (s *UserService) CreateUser(ctx context.Context, user *domain.User) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.CreateUser")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	_, err = s.userRepo.GetByID(ctx, user.ID)
+	switch {
+	case err == nil:
+		return domain.ErrUserExists
+	case !errors.Is(err, domain.ErrUserNotFound):
+		return err
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return err
+	}
+
+	logger.For(ctx, "service").Info("User created", "is_active", user.IsActive)
+	return nil
+}
+
+func (s *UserService) RenameUser(ctx context.Context, userID, username string) (_ *domain.User, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.RenameUser")
+	defer func() { tracing.End(span, err) }()
+	ctx = repository.WithPrimary(ctx)
+
+	if username == "" {
+		return nil, domain.ErrInvalidInput
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := s.userRepo.Update(user); err != nil {
+	user.Username = username
+	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, err
 	}
 
+	logger.For(ctx, "service").Info("User renamed")
 	return user, nil
 }
+
+// GetUserDetails returns a user for the user directory.
+func (s *UserService) GetUserDetails(ctx context.Context, userID string) (_ *domain.UserDetails, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.GetUserDetails")
+	defer func() { tracing.End(span, err) }()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	details, err := s.withOpenReviews(ctx, []*domain.User{user})
+	if err != nil {
+		return nil, err
+	}
+	return &details[0], nil
+}
+
+// ListUserDetails returns a page of the user directory and the number of
+// users matching filter.
+func (s *UserService) ListUserDetails(ctx context.Context, filter domain.UserFilter) (_ []domain.UserDetails, _ int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "UserService.ListUserDetails")
+	defer func() { tracing.End(span, err) }()
+
+	users, total, err := s.userRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	details, err := s.withOpenReviews(ctx, users)
+	if err != nil {
+		return nil, 0, err
+	}
+	return details, total, nil
+}
+
+func (s *UserService) withOpenReviews(ctx context.Context, users []*domain.User) ([]domain.UserDetails, error) {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	counts, err := s.userRepo.CountOpenReviews(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]domain.UserDetails, 0, len(users))
+	for _, user := range users {
+		details = append(details, domain.UserDetails{User: user, OpenReviews: counts[user.ID]})
+	}
+	return details, nil
+}
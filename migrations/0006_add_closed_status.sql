@@ -0,0 +1,11 @@
+-- +goose Up
+INSERT INTO pr_statuses (code, name, description) VALUES
+    ('CLOSED', 'Closed', 'Pull Request was closed without merging');
+
+-- +goose Down
+-- Closed pull requests go back to open; their reviewers were kept.
+UPDATE pull_requests
+SET status_id = (SELECT id FROM pr_statuses WHERE code = 'OPEN')
+WHERE status_id = (SELECT id FROM pr_statuses WHERE code = 'CLOSED');
+
+DELETE FROM pr_statuses WHERE code = 'CLOSED';
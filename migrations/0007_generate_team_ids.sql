@@ -0,0 +1,27 @@
+-- +goose Up
+-- Team IDs used to be copies of the names. Give every team a random ID so
+-- names can change without touching the rows that point at the team.
+ALTER TABLE team_members DROP CONSTRAINT team_members_team_id_fkey;
+ALTER TABLE team_members ADD CONSTRAINT team_members_team_id_fkey
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_team_id_fkey;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_team_id_fkey
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE SET NULL ON UPDATE CASCADE;
+
+UPDATE teams SET id = upper(substr(md5(random()::text || id), 1, 26));
+
+-- +goose Down
+-- IDs go back to being copies of the names, and names are up to 100
+-- characters long. The columns stay wide if the Up migration runs again.
+ALTER TABLE teams ALTER COLUMN id TYPE VARCHAR(100);
+ALTER TABLE team_members ALTER COLUMN team_id TYPE VARCHAR(100);
+ALTER TABLE pull_requests ALTER COLUMN team_id TYPE VARCHAR(100);
+
+UPDATE teams SET id = name;
+
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_team_id_fkey;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_team_id_fkey
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE SET NULL;
+ALTER TABLE team_members DROP CONSTRAINT team_members_team_id_fkey;
+ALTER TABLE team_members ADD CONSTRAINT team_members_team_id_fkey
+    FOREIGN KEY (team_id) REFERENCES teams(id) ON DELETE CASCADE;
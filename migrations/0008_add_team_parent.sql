@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE teams ADD COLUMN parent_id VARCHAR(50) NULL REFERENCES teams(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_teams_parent_id ON teams(parent_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_teams_parent_id;
+ALTER TABLE teams DROP COLUMN IF EXISTS parent_id;
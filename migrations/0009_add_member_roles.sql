@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE team_members ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'member'
+    CHECK (role IN ('lead', 'maintainer', 'member', 'trainee'));
+
+-- +goose Down
+ALTER TABLE team_members DROP COLUMN IF EXISTS role;
@@ -0,0 +1,57 @@
+// Package migrations embeds the SQL schema migrations into the binary. The
+// top-level files target Postgres; sqlite/ holds the SQLite equivalents.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// SQLite holds the migrations for the SQLite backend.
+var SQLite = mustSub(sqliteFS, "sqlite")
+
+// Latest returns the highest Postgres migration version shipped with the
+// binary.
+func Latest() int64 {
+	return latest(FS)
+}
+
+// LatestSQLite returns the highest SQLite migration version.
+func LatestSQLite() int64 {
+	return latest(SQLite)
+}
+
+func latest(fsys fs.FS) int64 {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		panic(err)
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		if !found {
+			continue
+		}
+		if version, err := strconv.ParseInt(prefix, 10, 64); err == nil && version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
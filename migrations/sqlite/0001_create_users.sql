@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    deleted_at TIMESTAMP NULL
+);
+
+CREATE INDEX idx_users_is_active ON users(is_active) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,38 @@
+-- +goose Up
+CREATE TABLE pr_statuses (
+    id INTEGER PRIMARY KEY,
+    code TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+INSERT INTO pr_statuses (code, name, description) VALUES
+    ('OPEN', 'Open', 'Pull Request is open for review'),
+    ('MERGED', 'Merged', 'Pull Request has been merged');
+
+CREATE TABLE pull_requests (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    author_id TEXT REFERENCES users(id) ON DELETE RESTRICT,
+    status_id INTEGER REFERENCES pr_statuses(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    merged_at TIMESTAMP NULL
+);
+
+CREATE TABLE pr_reviewers (
+    pr_id TEXT REFERENCES pull_requests(id) ON DELETE CASCADE,
+    user_id TEXT REFERENCES users(id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    PRIMARY KEY (pr_id, user_id)
+);
+
+CREATE INDEX idx_pull_requests_author_id ON pull_requests(author_id);
+CREATE INDEX idx_pull_requests_status_id ON pull_requests(status_id);
+CREATE INDEX idx_pr_reviewers_user_id ON pr_reviewers(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS pr_reviewers;
+DROP TABLE IF EXISTS pull_requests;
+DROP TABLE IF EXISTS pr_statuses;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_type TEXT NOT NULL,
+    pr_id TEXT REFERENCES pull_requests(id) ON DELETE CASCADE,
+    user_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+    additional_data TEXT CHECK (additional_data IS NULL OR json_valid(additional_data)),
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX idx_events_event_type ON events(event_type);
+CREATE INDEX idx_events_pr_id ON events(pr_id);
+CREATE INDEX idx_events_user_id ON events(user_id);
+CREATE INDEX idx_events_created_at ON events(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS events;
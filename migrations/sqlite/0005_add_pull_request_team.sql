@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE pull_requests ADD COLUMN team_id TEXT NULL REFERENCES teams(id) ON DELETE SET NULL;
+
+-- Existing pull requests take their author's team when it is unambiguous.
+UPDATE pull_requests
+SET team_id = (SELECT tm.team_id FROM team_members tm WHERE tm.user_id = pull_requests.author_id)
+WHERE (SELECT COUNT(*) FROM team_members tm WHERE tm.user_id = pull_requests.author_id) = 1;
+
+CREATE INDEX idx_pull_requests_team_id ON pull_requests(team_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_pull_requests_team_id;
+ALTER TABLE pull_requests DROP COLUMN team_id;
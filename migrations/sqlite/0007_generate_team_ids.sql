@@ -0,0 +1,23 @@
+-- +goose Up
+-- Team IDs used to be copies of the names. Give every team a random ID so
+-- names can change without touching the rows that point at the team.
+-- Foreign keys are checked at commit, once all three tables agree again.
+PRAGMA defer_foreign_keys = ON;
+
+CREATE TABLE team_ids (old_id TEXT PRIMARY KEY, new_id TEXT NOT NULL);
+INSERT INTO team_ids (old_id, new_id) SELECT id, upper(hex(randomblob(13))) FROM teams;
+
+UPDATE team_members SET team_id = (SELECT new_id FROM team_ids WHERE old_id = team_members.team_id);
+UPDATE pull_requests SET team_id = (SELECT new_id FROM team_ids WHERE old_id = pull_requests.team_id)
+WHERE team_id IS NOT NULL;
+UPDATE teams SET id = (SELECT new_id FROM team_ids WHERE old_id = teams.id);
+
+DROP TABLE team_ids;
+
+-- +goose Down
+PRAGMA defer_foreign_keys = ON;
+
+UPDATE team_members SET team_id = (SELECT name FROM teams WHERE teams.id = team_members.team_id);
+UPDATE pull_requests SET team_id = (SELECT name FROM teams WHERE teams.id = pull_requests.team_id)
+WHERE team_id IS NOT NULL;
+UPDATE teams SET id = name;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE team_members ADD COLUMN role TEXT NOT NULL DEFAULT 'member'
+    CHECK (role IN ('lead', 'maintainer', 'member', 'trainee'));
+
+-- +goose Down
+ALTER TABLE team_members DROP COLUMN role;
@@ -0,0 +1,942 @@
+// Package contract holds the behaviour every repository backend must share.
+// Backend-specific tests call Run with a factory that returns empty
+// repositories for each subtest.
+package contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/repository"
+)
+
+// Factory returns repositories over an empty store.
+type Factory func(t *testing.T) app.Repositories
+
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newRepos(t)) })
+	t.Run("Teams", func(t *testing.T) { testTeams(t, newRepos(t)) })
+	t.Run("PullRequests", func(t *testing.T) { testPullRequests(t, newRepos(t)) })
+	t.Run("PRStatuses", func(t *testing.T) { testPRStatuses(t, newRepos(t)) })
+	t.Run("Events", func(t *testing.T) { testEvents(t, newRepos(t)) })
+	t.Run("Stats", func(t *testing.T) { testStats(t, newRepos(t)) })
+	t.Run("ConcurrentWrites", func(t *testing.T) { testConcurrentWrites(t, newRepos(t)) })
+	t.Run("Membership", func(t *testing.T) { testMembership(t, newRepos(t)) })
+	t.Run("Transactions", func(t *testing.T) { testTransactions(t, newRepos(t)) })
+	t.Run("SoftDelete", func(t *testing.T) { testSoftDelete(t, newRepos(t)) })
+	t.Run("Rename", func(t *testing.T) { testRename(t, newRepos(t)) })
+	t.Run("Hierarchy", func(t *testing.T) { testHierarchy(t, newRepos(t)) })
+	t.Run("Roles", func(t *testing.T) { testRoles(t, newRepos(t)) })
+	t.Run("ListTeams", func(t *testing.T) { testListTeams(t, newRepos(t)) })
+	t.Run("ListUsers", func(t *testing.T) { testListUsers(t, newRepos(t)) })
+	t.Run("OpenReviews", func(t *testing.T) { testOpenReviews(t, newRepos(t)) })
+	t.Run("Erase", func(t *testing.T) { testErase(t, newRepos(t)) })
+	t.Run("DeleteUser", func(t *testing.T) { testDeleteUser(t, newRepos(t)) })
+}
+
+func seedTeam(t *testing.T, repos app.Repositories, name string, users ...*domain.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	team := &domain.Team{Name: name}
+	for _, u := range users {
+		require.NoError(t, repos.User.Create(ctx, u))
+		team.Members = append(team.Members, domain.TeamMember{UserID: u.ID, Username: u.Username, IsActive: u.IsActive})
+	}
+	require.NoError(t, repos.Team.Create(ctx, team))
+}
+
+func testUsers(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: false},
+	)
+	seedTeam(t, repos, "api", &domain.User{ID: "u1", Username: "Alice", IsActive: true})
+	require.NoError(t, repos.User.Create(ctx, &domain.User{ID: "u3", Username: "Carol", IsActive: true}))
+
+	// Teams are sorted by name, not by membership order.
+	user, err := repos.User.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, &domain.User{
+		ID: "u1", Username: "Alice", Teams: []string{"api", "backend"}, IsActive: true,
+		Roles: map[string]domain.TeamRole{"api": domain.RoleMember, "backend": domain.RoleMember},
+	}, user)
+
+	loner, err := repos.User.GetByID(ctx, "u3")
+	require.NoError(t, err)
+	assert.Empty(t, loner.Teams)
+
+	_, err = repos.User.GetByID(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	// Create upserts an existing user.
+	require.NoError(t, repos.User.Create(ctx, &domain.User{ID: "u3", Username: "Caroline", IsActive: false}))
+	loner, err = repos.User.GetByID(ctx, "u3")
+	require.NoError(t, err)
+	assert.Equal(t, "Caroline", loner.Username)
+	assert.False(t, loner.IsActive)
+
+	require.NoError(t, repos.User.Update(ctx, &domain.User{ID: "u2", Username: "Bobby", IsActive: true}))
+	user, err = repos.User.GetByID(ctx, "u2")
+	require.NoError(t, err)
+	assert.Equal(t, "Bobby", user.Username)
+	assert.True(t, user.IsActive)
+
+	err = repos.User.Update(ctx, &domain.User{ID: "missing", Username: "Nobody"})
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+
+	require.NoError(t, repos.User.Update(ctx, &domain.User{ID: "u1", Username: "Alice", IsActive: false}))
+	active, err := repos.User.GetByTeam(ctx, "backend")
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, &domain.User{
+		ID: "u2", Username: "Bobby", Teams: []string{"backend"}, IsActive: true,
+		Roles: map[string]domain.TeamRole{"backend": domain.RoleMember},
+	}, active[0])
+
+	none, err := repos.User.GetByTeam(ctx, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func testTeams(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: false},
+	)
+
+	team, err := repos.Team.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", team.Name)
+	assert.ElementsMatch(t, []domain.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleMember},
+		{UserID: "u2", Username: "Bob", IsActive: false, Role: domain.RoleMember},
+	}, team.Members)
+
+	exists, err := repos.Team.Exists(ctx, "backend")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = repos.Team.GetByName(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+
+	exists, err = repos.Team.Exists(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	err = repos.Team.Create(ctx, &domain.Team{Name: "backend"})
+	assert.ErrorIs(t, err, domain.ErrTeamExists, "duplicate team name must be rejected")
+
+	// A team referencing an unknown user is not created at all.
+	err = repos.Team.Create(ctx, &domain.Team{Name: "frontend", Members: []domain.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+		{UserID: "ghost", Username: "Ghost", IsActive: true},
+	}})
+	assert.Error(t, err)
+
+	exists, err = repos.Team.Exists(ctx, "frontend")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func testPullRequests(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: true},
+		&domain.User{ID: "u3", Username: "Carol", IsActive: true},
+	)
+
+	pr := &domain.PullRequest{ID: "pr-1", Name: "Add search", AuthorID: "u1", TeamName: "backend", AssignedReviewers: []string{"u2", "u3"}}
+	require.NoError(t, repos.PR.Create(ctx, pr))
+	assert.Equal(t, domain.PRStatusOpen, pr.Status)
+	assert.NotNil(t, pr.CreatedAt)
+
+	got, err := repos.PR.GetByID(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Add search", got.Name)
+	assert.Equal(t, "u1", got.AuthorID)
+	assert.Equal(t, "backend", got.TeamName)
+	assert.Equal(t, domain.PRStatusOpen, got.Status)
+	assert.ElementsMatch(t, []string{"u2", "u3"}, got.AssignedReviewers)
+	assert.NotNil(t, got.CreatedAt)
+	assert.Nil(t, got.MergedAt)
+
+	_, err = repos.PR.GetByID(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrPullRequestNotFound)
+
+	exists, err := repos.PR.Exists(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repos.PR.Exists(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.Error(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-1", Name: "Again", AuthorID: "u1"}),
+		"duplicate pull request id must be rejected")
+	assert.Error(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-x", Name: "Orphan", AuthorID: "ghost"}),
+		"unknown author must be rejected")
+
+	// Keep created_at strictly increasing for the ordering check below.
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-2", Name: "Fix login", AuthorID: "u3", AssignedReviewers: []string{"u2"}}))
+
+	list, err := repos.PR.ListByReviewer(ctx, "u2")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "pr-2", list[0].ID)
+	assert.Empty(t, list[0].TeamName)
+	assert.Equal(t, "pr-1", list[1].ID)
+	assert.Equal(t, "backend", list[1].TeamName)
+
+	empty, err := repos.PR.ListByReviewer(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	mergedAt := time.Now().UTC().Truncate(time.Millisecond)
+	got.Status = domain.PRStatusMerged
+	got.MergedAt = &mergedAt
+	got.AssignedReviewers = []string{"u3"}
+	require.NoError(t, repos.PR.Update(ctx, got))
+
+	merged, err := repos.PR.GetByID(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.PRStatusMerged, merged.Status)
+	require.NotNil(t, merged.MergedAt)
+	assert.WithinDuration(t, mergedAt, *merged.MergedAt, time.Millisecond)
+	assert.Equal(t, []string{"u3"}, merged.AssignedReviewers)
+	assert.Equal(t, "backend", merged.TeamName)
+
+	list, err = repos.PR.ListByReviewer(ctx, "u2")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "pr-2", list[0].ID)
+
+	err = repos.PR.Update(ctx, &domain.PullRequest{ID: "missing", Name: "Nope", Status: domain.PRStatusOpen})
+	assert.ErrorIs(t, err, domain.ErrPullRequestNotFound)
+}
+
+func testPRStatuses(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	open, err := repos.PRStatus.GetByCode(ctx, domain.PRStatusOpen)
+	require.NoError(t, err)
+	assert.Equal(t, domain.PRStatusOpen, open.Code)
+
+	byID, err := repos.PRStatus.GetByID(ctx, open.ID)
+	require.NoError(t, err)
+	assert.Equal(t, open.Code, byID.Code)
+	assert.Equal(t, open.Name, byID.Name)
+
+	all, err := repos.PRStatus.ListAll(ctx)
+	require.NoError(t, err)
+	codes := make([]string, 0, len(all))
+	for _, s := range all {
+		codes = append(codes, s.Code)
+	}
+	assert.ElementsMatch(t, []string{domain.PRStatusOpen, domain.PRStatusMerged, domain.PRStatusClosed}, codes)
+
+	_, err = repos.PRStatus.GetByCode(ctx, "DRAFT")
+	assert.Error(t, err)
+}
+
+func testEvents(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: true},
+	)
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-1", Name: "Add search", AuthorID: "u1", AssignedReviewers: []string{"u2"}}))
+
+	data := json.RawMessage(`{"old_reviewer_id":"u3"}`)
+	for i, event := range []*domain.Event{
+		{EventType: domain.EventTypePRCreated, PRID: "pr-1", UserID: "u1"},
+		{EventType: domain.EventTypeReviewerAssigned, PRID: "pr-1", UserID: "u2"},
+		{EventType: domain.EventTypeReviewerAssigned, PRID: "pr-1", UserID: "u1", AdditionalData: data},
+	} {
+		if i > 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		require.NoError(t, repos.Events.CreateEvent(ctx, event))
+	}
+
+	assigned, err := repos.Events.GetEventsByType(ctx, domain.EventTypeReviewerAssigned, 10)
+	require.NoError(t, err)
+	require.Len(t, assigned, 2)
+	assert.Equal(t, "u1", assigned[0].UserID)
+	assert.JSONEq(t, string(data), string(assigned[0].AdditionalData))
+	assert.Equal(t, "u2", assigned[1].UserID)
+	assert.Empty(t, assigned[1].AdditionalData)
+	assert.NotZero(t, assigned[0].ID)
+	assert.NotEqual(t, assigned[0].ID, assigned[1].ID)
+	assert.False(t, assigned[0].CreatedAt.IsZero())
+
+	limited, err := repos.Events.GetEventsByType(ctx, domain.EventTypeReviewerAssigned, 1)
+	require.NoError(t, err)
+	require.Len(t, limited, 1)
+	assert.Equal(t, "u1", limited[0].UserID)
+
+	counts, err := repos.Events.GetEventCountsByType(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, counts[domain.EventTypePRCreated])
+	assert.Equal(t, 2, counts[domain.EventTypeReviewerAssigned])
+	assert.Zero(t, counts[domain.EventTypePRMerged])
+
+	assert.Error(t, repos.Events.CreateEvent(ctx, &domain.Event{EventType: domain.EventTypePRMerged, PRID: "missing", UserID: "u1"}),
+		"event for an unknown pull request must be rejected")
+}
+
+func testStats(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	stats, err := repos.Stats.GetEventStats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.TotalEvents)
+	assert.Len(t, stats.EventCounts, 6)
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: true},
+	)
+	seedTeam(t, repos, "frontend",
+		&domain.User{ID: "u3", Username: "Carol", IsActive: true},
+	)
+
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-1", Name: "One", AuthorID: "u1", TeamName: "backend", AssignedReviewers: []string{"u2", "u3"}}))
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-2", Name: "Two", AuthorID: "u3", TeamName: "frontend", AssignedReviewers: []string{"u2"}}))
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-3", Name: "Three", AuthorID: "u2", TeamName: "backend", AssignedReviewers: []string{"u1"}}))
+
+	pr, err := repos.PR.GetByID(ctx, "pr-3")
+	require.NoError(t, err)
+	mergedAt := time.Now()
+	pr.Status = domain.PRStatusMerged
+	pr.MergedAt = &mergedAt
+	require.NoError(t, repos.PR.Update(ctx, pr))
+
+	require.NoError(t, repos.Events.CreateEvent(ctx, &domain.Event{EventType: domain.EventTypePRCreated, PRID: "pr-1", UserID: "u1"}))
+	require.NoError(t, repos.Events.CreateEvent(ctx, &domain.Event{EventType: domain.EventTypePRMerged, PRID: "pr-3", UserID: "u2"}))
+
+	stats, err = repos.Stats.GetEventStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.TotalEvents)
+	assert.Equal(t, 1, stats.EventCounts[domain.EventTypePRCreated])
+	assert.Equal(t, 1, stats.EventCounts[domain.EventTypePRMerged])
+	assert.Equal(t, 0, stats.EventCounts[domain.EventTypeReviewerReassigned])
+
+	open, err := repos.Stats.GetOpenReviewsByTeam(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"backend": 2, "frontend": 1}, open)
+
+	// a reviewer in two teams still counts once per review, for the team
+	// that owns the pull request
+	require.NoError(t, repos.Team.AddMembers(ctx, "frontend", []string{"u2"}))
+	open, err = repos.Stats.GetOpenReviewsByTeam(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"backend": 2, "frontend": 1}, open)
+}
+
+func testConcurrentWrites(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend", &domain.User{ID: "author", Username: "Author", IsActive: true})
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("u%d", i)
+			if err := repos.User.Create(ctx, &domain.User{ID: id, Username: id, IsActive: true}); err != nil {
+				errs <- err
+				return
+			}
+			pr := &domain.PullRequest{ID: "pr-" + id, Name: id, AuthorID: "author", AssignedReviewers: []string{id}}
+			if err := repos.PR.Create(ctx, pr); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := repos.PR.ListByReviewer(ctx, id); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	for i := range workers {
+		list, err := repos.PR.ListByReviewer(ctx, fmt.Sprintf("u%d", i))
+		require.NoError(t, err)
+		assert.Len(t, list, 1)
+	}
+}
+
+func testMembership(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend", &domain.User{ID: "u1", Username: "Alice", IsActive: true})
+	require.NoError(t, repos.User.Create(ctx, &domain.User{ID: "u2", Username: "Bob", IsActive: true}))
+
+	// Existing members are ignored.
+	require.NoError(t, repos.Team.AddMembers(ctx, "backend", []string{"u1", "u2"}))
+	team, err := repos.Team.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 2)
+
+	user, err := repos.User.GetByID(ctx, "u2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend"}, user.Teams)
+
+	assert.ErrorIs(t, repos.Team.AddMembers(ctx, "missing", []string{"u2"}), domain.ErrTeamNotFound)
+	assert.Error(t, repos.Team.AddMembers(ctx, "backend", []string{"ghost"}))
+
+	require.NoError(t, repos.Team.RemoveMember(ctx, "backend", "u2"))
+	user, err = repos.User.GetByID(ctx, "u2")
+	require.NoError(t, err)
+	assert.Empty(t, user.Teams)
+
+	assert.ErrorIs(t, repos.Team.RemoveMember(ctx, "backend", "u2"), domain.ErrNotTeamMember)
+	assert.ErrorIs(t, repos.Team.RemoveMember(ctx, "missing", "u1"), domain.ErrTeamNotFound)
+}
+
+func testTransactions(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+	require.NotNil(t, repos.Tx)
+
+	seedTeam(t, repos, "backend", &domain.User{ID: "u1", Username: "Alice", IsActive: true})
+
+	// A failing transaction leaves nothing behind, not even the writes of a
+	// nested transaction that succeeded on its own.
+	var hooked bool
+	err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, repos.User.Create(ctx, &domain.User{ID: "u2", Username: "Bob", IsActive: true}))
+		require.NoError(t, repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			return repos.Team.AddMembers(ctx, "backend", []string{"u2"})
+		}))
+		require.NoError(t, repos.Team.RemoveMember(ctx, "backend", "u1"))
+
+		// Reads inside the transaction see its writes.
+		team, err := repos.Team.GetByName(ctx, "backend")
+		require.NoError(t, err)
+		assert.Equal(t, []domain.TeamMember{{UserID: "u2", Username: "Bob", IsActive: true, Role: domain.RoleMember}}, team.Members)
+
+		repository.AfterCommit(ctx, func() { hooked = true })
+		return assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.False(t, hooked, "hooks must not run after a rollback")
+
+	_, err = repos.User.GetByID(ctx, "u2")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	team, err := repos.Team.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.TeamMember{{UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleMember}}, team.Members)
+
+	err = repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repos.User.Create(ctx, &domain.User{ID: "u2", Username: "Bob", IsActive: true}); err != nil {
+			return err
+		}
+		repository.AfterCommit(ctx, func() { hooked = true })
+		return repos.Team.AddMembers(ctx, "backend", []string{"u2"})
+	})
+	require.NoError(t, err)
+	assert.True(t, hooked)
+
+	team, err = repos.Team.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 2)
+}
+
+func testSoftDelete(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: true},
+	)
+	seedTeam(t, repos, "api", &domain.User{ID: "u1", Username: "Alice", IsActive: true})
+
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-1", Name: "One", AuthorID: "u1", TeamName: "backend", AssignedReviewers: []string{"u2"}}))
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-2", Name: "Two", AuthorID: "u2", TeamName: "backend"}))
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-3", Name: "Three", AuthorID: "u1", TeamName: "api"}))
+	merged, err := repos.PR.GetByID(ctx, "pr-3")
+	require.NoError(t, err)
+	merged.Status = domain.PRStatusMerged
+	require.NoError(t, repos.PR.Update(ctx, merged))
+
+	open, err := repos.PR.ListOpenByTeam(ctx, "backend")
+	require.NoError(t, err)
+	require.Len(t, open, 2)
+	assert.Equal(t, "pr-1", open[0].ID, "oldest first")
+	assert.Equal(t, []string{"u2"}, open[0].AssignedReviewers)
+
+	open, err = repos.PR.ListOpenByTeam(ctx, "api")
+	require.NoError(t, err)
+	assert.Empty(t, open, "merged pull requests are not open")
+
+	// Update moves a pull request to another team.
+	open, err = repos.PR.ListOpenByTeam(ctx, "backend")
+	require.NoError(t, err)
+	open[1].TeamName = "api"
+	require.NoError(t, repos.PR.Update(ctx, open[1]))
+	moved, err := repos.PR.GetByID(ctx, "pr-2")
+	require.NoError(t, err)
+	assert.Equal(t, "api", moved.TeamName)
+
+	require.NoError(t, repos.Team.Delete(ctx, "backend"))
+	assert.ErrorIs(t, repos.Team.Delete(ctx, "backend"), domain.ErrTeamNotFound)
+
+	_, err = repos.Team.GetByName(ctx, "backend")
+	assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+	exists, err := repos.Team.Exists(ctx, "backend")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	users, err := repos.User.GetByTeam(ctx, "backend")
+	require.NoError(t, err)
+	assert.Empty(t, users, "deleted teams offer no reviewers")
+	user, err := repos.User.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api"}, user.Teams)
+	assert.ErrorIs(t, repos.Team.AddMembers(ctx, "backend", []string{"u1"}), domain.ErrTeamNotFound)
+	assert.ErrorIs(t, repos.Team.RemoveMember(ctx, "backend", "u2"), domain.ErrTeamNotFound)
+
+	// The name stays taken and history keeps pointing at the team.
+	assert.ErrorIs(t, repos.Team.Create(ctx, &domain.Team{Name: "backend"}), domain.ErrTeamExists)
+	pr, err := repos.PR.GetByID(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", pr.TeamName)
+
+	require.NoError(t, repos.Team.Restore(ctx, "backend"))
+	assert.ErrorIs(t, repos.Team.Restore(ctx, "backend"), domain.ErrTeamNotFound)
+	assert.ErrorIs(t, repos.Team.Restore(ctx, "missing"), domain.ErrTeamNotFound)
+
+	team, err := repos.Team.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 2, "members survive a delete and restore")
+	user, err = repos.User.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "backend"}, user.Teams)
+}
+
+func testRename(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: true},
+	)
+	seedTeam(t, repos, "frontend", &domain.User{ID: "u3", Username: "Carol", IsActive: true})
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-1", Name: "One", AuthorID: "u1", TeamName: "backend", AssignedReviewers: []string{"u2"}}))
+
+	team, err := repos.Team.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	require.NotEmpty(t, team.ID, "teams get an ID on creation")
+	assert.NotEqual(t, team.Name, team.ID)
+	id := team.ID
+
+	byID, err := repos.Team.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, team, byID)
+	_, err = repos.Team.GetByID(ctx, "backend")
+	assert.ErrorIs(t, err, domain.ErrTeamNotFound, "names are not IDs")
+
+	explicit := &domain.Team{ID: "team-qa", Name: "qa"}
+	require.NoError(t, repos.Team.Create(ctx, explicit))
+	qa, err := repos.Team.GetByID(ctx, "team-qa")
+	require.NoError(t, err)
+	assert.Equal(t, "qa", qa.Name)
+
+	assert.ErrorIs(t, repos.Team.Rename(ctx, "backend", "frontend"), domain.ErrTeamExists)
+	assert.ErrorIs(t, repos.Team.Rename(ctx, "missing", "platform"), domain.ErrTeamNotFound)
+
+	require.NoError(t, repos.Team.Rename(ctx, "backend", "platform"))
+
+	renamed, err := repos.Team.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "platform", renamed.Name)
+	assert.Len(t, renamed.Members, 2, "members follow the team")
+	_, err = repos.Team.GetByName(ctx, "backend")
+	assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+
+	user, err := repos.User.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform"}, user.Teams)
+	users, err := repos.User.GetByTeam(ctx, "platform")
+	require.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	pr, err := repos.PR.GetByID(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "platform", pr.TeamName, "pull requests follow the team")
+	open, err := repos.PR.ListOpenByTeam(ctx, "platform")
+	require.NoError(t, err)
+	assert.Len(t, open, 1)
+
+	// The old name is free again; a deleted team's name is not.
+	require.NoError(t, repos.Team.Create(ctx, &domain.Team{Name: "backend"}))
+	require.NoError(t, repos.Team.Delete(ctx, "frontend"))
+	assert.ErrorIs(t, repos.Team.Rename(ctx, "platform", "frontend"), domain.ErrTeamExists)
+	assert.ErrorIs(t, repos.Team.Rename(ctx, "frontend", "web"), domain.ErrTeamNotFound, "deleted teams cannot be renamed")
+}
+
+func testHierarchy(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "dept")
+	require.NoError(t, repos.Team.Create(ctx, &domain.Team{Name: "squad-b", ParentName: "dept"}))
+	require.NoError(t, repos.Team.Create(ctx, &domain.Team{Name: "squad-a", ParentName: "dept"}))
+	seedTeam(t, repos, "pod")
+	assert.ErrorIs(t, repos.Team.Create(ctx, &domain.Team{Name: "orphan", ParentName: "missing"}), domain.ErrTeamNotFound)
+
+	squad, err := repos.Team.GetByName(ctx, "squad-a")
+	require.NoError(t, err)
+	assert.Equal(t, "dept", squad.ParentName)
+	byID, err := repos.Team.GetByID(ctx, squad.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "dept", byID.ParentName)
+
+	children, err := repos.Team.ListSubTeams(ctx, "dept")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"squad-a", "squad-b"}, children, "sorted by name")
+	children, err = repos.Team.ListSubTeams(ctx, "pod")
+	require.NoError(t, err)
+	assert.Empty(t, children)
+	_, err = repos.Team.ListSubTeams(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrTeamNotFound)
+
+	require.NoError(t, repos.Team.SetParent(ctx, "pod", "squad-a"))
+	pod, err := repos.Team.GetByName(ctx, "pod")
+	require.NoError(t, err)
+	assert.Equal(t, "squad-a", pod.ParentName)
+	assert.ErrorIs(t, repos.Team.SetParent(ctx, "pod", "missing"), domain.ErrTeamNotFound)
+	assert.ErrorIs(t, repos.Team.SetParent(ctx, "missing", "dept"), domain.ErrTeamNotFound)
+
+	// The parent link survives a rename of the parent.
+	require.NoError(t, repos.Team.Rename(ctx, "squad-a", "squad-x"))
+	pod, err = repos.Team.GetByName(ctx, "pod")
+	require.NoError(t, err)
+	assert.Equal(t, "squad-x", pod.ParentName)
+
+	// Sub-teams of a deleted team look top-level until it is restored, and
+	// deleted sub-teams are not listed.
+	require.NoError(t, repos.Team.Delete(ctx, "squad-b"))
+	children, err = repos.Team.ListSubTeams(ctx, "dept")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"squad-x"}, children)
+
+	require.NoError(t, repos.Team.Delete(ctx, "squad-x"))
+	pod, err = repos.Team.GetByName(ctx, "pod")
+	require.NoError(t, err)
+	assert.Empty(t, pod.ParentName)
+	require.NoError(t, repos.Team.Restore(ctx, "squad-x"))
+	pod, err = repos.Team.GetByName(ctx, "pod")
+	require.NoError(t, err)
+	assert.Equal(t, "squad-x", pod.ParentName)
+
+	require.NoError(t, repos.Team.SetParent(ctx, "pod", ""))
+	pod, err = repos.Team.GetByName(ctx, "pod")
+	require.NoError(t, err)
+	assert.Empty(t, pod.ParentName)
+}
+
+func testRoles(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	for _, u := range []*domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+	} {
+		require.NoError(t, repos.User.Create(ctx, u))
+	}
+	require.NoError(t, repos.Team.Create(ctx, &domain.Team{Name: "backend", Members: []domain.TeamMember{
+		{UserID: "u1", Role: domain.RoleLead},
+		{UserID: "u2"},
+	}}))
+	seedTeam(t, repos, "api", &domain.User{ID: "u2", Username: "Bob", IsActive: true})
+
+	// Members created without a role are plain members.
+	team, err := repos.Team.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleLead},
+		{UserID: "u2", Username: "Bob", IsActive: true, Role: domain.RoleMember},
+	}, team.Members)
+
+	// Roles are per team.
+	require.NoError(t, repos.Team.SetRole(ctx, "backend", "u2", domain.RoleTrainee))
+	user, err := repos.User.GetByID(ctx, "u2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]domain.TeamRole{"api": domain.RoleMember, "backend": domain.RoleTrainee}, user.Roles)
+
+	users, err := repos.User.GetByTeam(ctx, "backend")
+	require.NoError(t, err)
+	roles := make(map[string]domain.TeamRole)
+	for _, u := range users {
+		roles[u.ID] = u.RoleFor("backend")
+	}
+	assert.Equal(t, map[string]domain.TeamRole{"u1": domain.RoleLead, "u2": domain.RoleTrainee}, roles)
+
+	assert.ErrorIs(t, repos.Team.SetRole(ctx, "backend", "u3", domain.RoleMaintainer), domain.ErrNotTeamMember)
+	assert.ErrorIs(t, repos.Team.SetRole(ctx, "missing", "u1", domain.RoleMaintainer), domain.ErrTeamNotFound)
+
+	// A member who leaves and comes back starts over as a member.
+	require.NoError(t, repos.Team.RemoveMember(ctx, "backend", "u2"))
+	require.NoError(t, repos.Team.AddMembers(ctx, "backend", []string{"u2"}))
+	user, err = repos.User.GetByID(ctx, "u2")
+	require.NoError(t, err)
+	assert.Equal(t, domain.RoleMember, user.Roles["backend"])
+
+	// Roles survive a soft delete and restore of the team.
+	require.NoError(t, repos.Team.Delete(ctx, "backend"))
+	user, err = repos.User.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, user.Roles)
+	require.NoError(t, repos.Team.Restore(ctx, "backend"))
+	user, err = repos.User.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]domain.TeamRole{"backend": domain.RoleLead}, user.Roles)
+}
+
+func testListTeams(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	teams, err := repos.Team.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, teams)
+
+	seedTeam(t, repos, "platform", &domain.User{ID: "u1", Username: "Alice", IsActive: true})
+	seedTeam(t, repos, "backend", &domain.User{ID: "u2", Username: "Bob", IsActive: false})
+	seedTeam(t, repos, "api")
+	require.NoError(t, repos.Team.SetParent(ctx, "backend", "platform"))
+	require.NoError(t, repos.Team.SetRole(ctx, "backend", "u2", domain.RoleMaintainer))
+	require.NoError(t, repos.Team.Delete(ctx, "api"))
+
+	teams, err = repos.Team.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, teams, 2, "deleted teams are not listed")
+	assert.Equal(t, "backend", teams[0].Name)
+	assert.Equal(t, "platform", teams[0].ParentName)
+	assert.Equal(t, []domain.TeamMember{{UserID: "u2", Username: "Bob", Role: domain.RoleMaintainer}}, teams[0].Members)
+	assert.Equal(t, "platform", teams[1].Name)
+	assert.NotEmpty(t, teams[1].ID)
+	assert.Equal(t, []domain.TeamMember{{UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleMember}}, teams[1].Members)
+}
+
+func testListUsers(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	users, total, err := repos.User.List(ctx, domain.UserFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+	assert.Zero(t, total)
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u3", Username: "Carol", IsActive: true},
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+	)
+	require.NoError(t, repos.User.Create(ctx, &domain.User{ID: "u2", Username: "Bob", IsActive: false}))
+	require.NoError(t, repos.User.Create(ctx, &domain.User{ID: "u4", Username: "Alice", IsActive: false}))
+
+	ids := func(users []*domain.User) []string {
+		var out []string
+		for _, u := range users {
+			out = append(out, u.ID)
+		}
+		return out
+	}
+
+	users, total, err = repos.User.List(ctx, domain.UserFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u2", "u3", "u4"}, ids(users))
+	assert.Equal(t, 4, total)
+	assert.Equal(t, []string{"backend"}, users[0].Teams)
+	assert.Equal(t, map[string]domain.TeamRole{"backend": domain.RoleMember}, users[0].Roles)
+
+	users, total, err = repos.User.List(ctx, domain.UserFilter{Username: "Alice"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u4"}, ids(users))
+	assert.Equal(t, 2, total)
+
+	inactive := false
+	users, total, err = repos.User.List(ctx, domain.UserFilter{IsActive: &inactive})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u4"}, ids(users))
+	assert.Equal(t, 2, total)
+
+	users, total, err = repos.User.List(ctx, domain.UserFilter{Offset: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u3"}, ids(users))
+	assert.Equal(t, 4, total, "the total ignores paging")
+
+	users, _, err = repos.User.List(ctx, domain.UserFilter{Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	users, total, err = repos.User.List(ctx, domain.UserFilter{UsernamePrefix: "Al"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1", "u4"}, ids(users))
+	assert.Equal(t, 2, total)
+
+	users, _, err = repos.User.List(ctx, domain.UserFilter{UsernamePrefix: "al"})
+	require.NoError(t, err)
+	assert.Empty(t, users, "the prefix is case-sensitive")
+
+	users, total, err = repos.User.List(ctx, domain.UserFilter{Team: "backend", UsernamePrefix: "Al"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, ids(users))
+	assert.Equal(t, 1, total)
+
+	require.NoError(t, repos.Team.Delete(ctx, "backend"))
+	users, _, err = repos.User.List(ctx, domain.UserFilter{Team: "backend"})
+	require.NoError(t, err)
+	assert.Empty(t, users, "deleted teams have no members to list")
+}
+
+func testOpenReviews(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: true},
+		&domain.User{ID: "u3", Username: "Carol", IsActive: true},
+	)
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-1", Name: "One", AuthorID: "u1", AssignedReviewers: []string{"u2", "u3"}}))
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-2", Name: "Two", AuthorID: "u1", AssignedReviewers: []string{"u2"}}))
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-3", Name: "Three", AuthorID: "u2", AssignedReviewers: []string{"u3"}}))
+
+	merged, err := repos.PR.GetByID(ctx, "pr-3")
+	require.NoError(t, err)
+	merged.Status = domain.PRStatusMerged
+	require.NoError(t, repos.PR.Update(ctx, merged))
+
+	counts, err := repos.User.CountOpenReviews(ctx, []string{"u1", "u2", "u3"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"u2": 2, "u3": 1}, counts)
+
+	counts, err = repos.User.CountOpenReviews(ctx, []string{"u3"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"u3": 1}, counts)
+
+	counts, err = repos.User.CountOpenReviews(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func testErase(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: true},
+		&domain.User{ID: "u3", Username: "Carol", IsActive: true},
+	)
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-1", Name: "One", AuthorID: "u1", AssignedReviewers: []string{"u2"}}))
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-2", Name: "Two", AuthorID: "u2", AssignedReviewers: []string{"u1", "u3"}}))
+
+	events := []*domain.Event{
+		{EventType: domain.EventTypePRCreated, PRID: "pr-1", UserID: "u1", AdditionalData: json.RawMessage(`{"pr_name":"One"}`)},
+		{EventType: domain.EventTypeReviewerReassigned, PRID: "pr-2", UserID: "u3", AdditionalData: json.RawMessage(`{"old_user_id":"u1","new_user_id":"u3"}`)},
+		{EventType: domain.EventTypeReviewerReassigned, PRID: "pr-1", UserID: "u1", AdditionalData: json.RawMessage(`{"old_user_id":"u3","new_user_id":"u1"}`)},
+		{EventType: domain.EventTypePRMerged, PRID: "pr-2", UserID: "u2"},
+	}
+	for _, e := range events {
+		require.NoError(t, repos.Events.CreateEvent(ctx, e))
+	}
+	before, err := repos.Stats.GetEventStats(ctx)
+	require.NoError(t, err)
+
+	erased, err := repos.User.Erase(ctx, "u1", "erased-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ErasedRecords{PullRequests: 1, PastReviews: 1, Events: 3}, erased)
+
+	_, err = repos.User.GetByID(ctx, "u1")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repos.User.GetByID(ctx, "erased-1")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound, "the pseudonymous user is soft-deleted")
+	users, _, err := repos.User.List(ctx, domain.UserFilter{})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	team, err := repos.Team.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 2)
+
+	pr, err := repos.PR.GetByID(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "erased-1", pr.AuthorID)
+	pr, err = repos.PR.GetByID(ctx, "pr-2")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"erased-1", "u3"}, pr.AssignedReviewers)
+
+	reassigned, err := repos.Events.GetEventsByType(ctx, domain.EventTypeReviewerReassigned, 10)
+	require.NoError(t, err)
+	require.Len(t, reassigned, 2)
+	for _, e := range reassigned {
+		assert.NotContains(t, string(e.AdditionalData), `"u1"`)
+		assert.NotEqual(t, "u1", e.UserID)
+	}
+	after, err := repos.Stats.GetEventStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, before, after, "erasure keeps the event counts")
+
+	_, err = repos.User.Erase(ctx, "u1", "erased-2")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repos.User.Erase(ctx, "erased-1", "erased-3")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+}
+
+func testDeleteUser(t *testing.T, repos app.Repositories) {
+	ctx := context.Background()
+
+	seedTeam(t, repos, "backend",
+		&domain.User{ID: "u1", Username: "Alice", IsActive: true},
+		&domain.User{ID: "u2", Username: "Bob", IsActive: true},
+	)
+	require.NoError(t, repos.PR.Create(ctx, &domain.PullRequest{ID: "pr-1", Name: "One", AuthorID: "u1", AssignedReviewers: []string{"u2"}}))
+
+	require.NoError(t, repos.User.Delete(ctx, "u1"))
+	_, err := repos.User.GetByID(ctx, "u1")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	users, total, err := repos.User.List(ctx, domain.UserFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "u2", users[0].ID)
+	team, err := repos.Team.GetByName(ctx, "backend")
+	require.NoError(t, err)
+	require.Len(t, team.Members, 1)
+	assert.Equal(t, "u2", team.Members[0].UserID)
+	pr, err := repos.PR.GetByID(ctx, "pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", pr.AuthorID, "pull requests keep the deleted user's ID")
+
+	assert.ErrorIs(t, repos.User.Delete(ctx, "u1"), domain.ErrUserNotFound)
+	assert.ErrorIs(t, repos.User.Update(ctx, &domain.User{ID: "u1", Username: "Alice"}), domain.ErrUserNotFound)
+
+	require.NoError(t, repos.User.Create(ctx, &domain.User{ID: "u1", Username: "Alice", IsActive: true}))
+	user, err := repos.User.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.True(t, user.IsActive)
+	assert.Empty(t, user.Teams, "a restored user rejoins no team")
+}
@@ -0,0 +1,187 @@
+package e2e
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/111zxc/pr-review-service/internal/auth"
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler"
+)
+
+const roleAdminToken = "role-admin-token"
+
+func startJWKSServer(t *testing.T, keys map[string]crypto.PublicKey) *httptest.Server {
+	t.Helper()
+
+	jwks, err := auth.EncodeJWKS(keys)
+	if err != nil {
+		t.Fatalf("failed to encode JWKS: %v", err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jwks) //nolint:errcheck
+	}))
+}
+
+func issueToken(t *testing.T, method jwt.SigningMethod, kid string, key any, sub string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, jwt.MapClaims{
+		"sub": sub,
+		"iss": "https://idp.test",
+		"aud": "pr-review-service",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func TestAuthFlow(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+
+	jwksServer := startJWKSServer(t, map[string]crypto.PublicKey{
+		"rsa-1": &rsaKey.PublicKey,
+		"ec-1":  &ecKey.PublicKey,
+	})
+	defer jwksServer.Close()
+
+	env := SetupTestEnv(t, WithAdminToken(roleAdminToken), WithAuth(config.AuthConfig{
+		Enabled:             true,
+		JWKSURL:             jwksServer.URL,
+		Issuer:              "https://idp.test",
+		Audience:            "pr-review-service",
+		UserClaim:           "sub",
+		TeamsClaim:          "groups",
+		JWKSRefreshInterval: time.Minute,
+	}))
+	defer TearDown(env)
+
+	base := env.Server.URL
+
+	// seed an initial admin user directly, the API is closed without a token
+	env.Exec(t, `INSERT INTO users (id, username, is_active) VALUES ('admin', 'admin', true)`)
+
+	rsaToken := issueToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, "admin")
+	ecToken := issueToken(t, jwt.SigningMethodES256, "ec-1", ecKey, "admin")
+
+	// 1. health is public
+	resp := GET(t, base+"/health")
+	ExpectStatus(t, resp, http.StatusOK)
+
+	// 2. missing token -> 401
+	resp = GET(t, base+"/stats")
+	ExpectStatus(t, resp, http.StatusUnauthorized)
+
+	// 3. RS256 token -> 201
+	resp = DO(t, http.MethodPost, base+"/team/add", rsaToken, map[string]any{
+		"team_name": "platform",
+		"members": []map[string]any{
+			{"user_id": "admin", "username": "admin", "is_active": true},
+			{"user_id": "p1", "username": "petr", "is_active": true},
+		},
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	// 4. ES256 token -> 200
+	resp = DO(t, http.MethodGet, base+"/team/get?team_name=platform", ecToken, nil)
+	ExpectStatus(t, resp, http.StatusOK)
+
+	// 5. token for unknown user -> 401
+	resp = DO(t, http.MethodGet, base+"/stats", issueToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, "ghost"), nil)
+	ExpectStatus(t, resp, http.StatusUnauthorized)
+
+	// 6. token signed by a key not in the JWKS -> 401
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	resp = DO(t, http.MethodGet, base+"/stats", issueToken(t, jwt.SigningMethodRS256, "rsa-1", otherKey, "admin"), nil)
+	ExpectStatus(t, resp, http.StatusUnauthorized)
+	ExpectErrorCode(t, resp, "UNAUTHORIZED")
+
+	// 7. deactivated user -> 403
+	resp = DO(t, http.MethodPost, base+"/users/setIsActive", rsaToken, map[string]any{
+		"user_id": "p1", "is_active": false,
+	})
+	ExpectStatus(t, resp, http.StatusOK)
+
+	resp = DO(t, http.MethodGet, base+"/stats", issueToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, "p1"), nil)
+	ExpectStatus(t, resp, http.StatusForbidden)
+
+	// 8. a member of a team without a lead cannot appoint themselves; the
+	// admin endpoint can
+	resp = DO(t, http.MethodPost, base+"/team/setRole", rsaToken, map[string]any{
+		"team_name": "platform", "user_id": "admin", "role": "lead",
+	})
+	ExpectErrorCode(t, resp, "FORBIDDEN")
+
+	resp = DO(t, http.MethodPost, base+"/admin/team/setRole", "", map[string]any{
+		"team_name": "platform", "user_id": "admin", "role": "lead",
+	})
+	ExpectStatus(t, resp, http.StatusUnauthorized)
+	resp = setRoleAsAdmin(t, base, map[string]any{"team_name": "platform", "user_id": "admin", "role": "lead"})
+	ExpectStatus(t, resp, http.StatusOK)
+
+	// 9. after that only the lead changes roles
+	resp = DO(t, http.MethodPost, base+"/team/addMembers", rsaToken, map[string]any{
+		"team_name": "platform",
+		"members":   []map[string]any{{"user_id": "p2", "username": "polina", "is_active": true}},
+	})
+	ExpectStatus(t, resp, http.StatusOK)
+
+	p2Token := issueToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, "p2")
+	resp = DO(t, http.MethodPost, base+"/team/setRole", p2Token, map[string]any{
+		"team_name": "platform", "user_id": "p2", "role": "maintainer",
+	})
+	ExpectErrorCode(t, resp, "FORBIDDEN")
+
+	resp = DO(t, http.MethodPost, base+"/team/setRole", rsaToken, map[string]any{
+		"team_name": "platform", "user_id": "p2", "role": "maintainer",
+	})
+	ExpectStatus(t, resp, http.StatusOK)
+}
+
+func setRoleAsAdmin(t *testing.T, base string, body map[string]any) *http.Response {
+	t.Helper()
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to encode request: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, base+"/admin/team/setRole", bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handler.AdminTokenHeader, roleAdminToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	return resp
+}
@@ -0,0 +1,32 @@
+package e2e
+
+import (
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/internal/config"
+	pg "github.com/111zxc/pr-review-service/internal/repository/postgres"
+	"github.com/111zxc/pr-review-service/tests/contract"
+)
+
+func TestPostgresRepositoryContract(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TearDown(env)
+
+	contract.Run(t, func(t *testing.T) app.Repositories {
+		env.Exec(t, `TRUNCATE events, pr_reviewers, pull_requests, team_members, teams, users RESTART IDENTITY CASCADE`)
+		return app.NewPostgresRepositories(pg.NewRouter(env.DB, nil))
+	})
+}
+
+func TestSQLiteRepositoryContract(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite))
+	defer TearDown(env)
+
+	contract.Run(t, func(t *testing.T) app.Repositories {
+		for _, table := range []string{"events", "pr_reviewers", "pull_requests", "team_members", "teams", "users"} {
+			env.Exec(t, "DELETE FROM "+table)
+		}
+		return app.NewSQLiteRepositories(env.SQLite)
+	})
+}
@@ -1,15 +1,33 @@
 package e2e
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/migrations"
 )
 
 func TestFullFlow(t *testing.T) {
 	env := SetupTestEnv(t)
 	defer TearDown(env)
 
+	runFullFlow(t, env)
+}
+
+func TestFullFlowSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite))
+	defer TearDown(env)
+
+	runFullFlow(t, env)
+}
+
+func runFullFlow(t *testing.T, env *TestEnv) {
 	base := env.Server.URL
 
 	// 1. create team -> 201
@@ -98,4 +116,50 @@ func TestFullFlow(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodPost, base+"/stats", nil)
 	resp, _ = http.DefaultClient.Do(req)
 	ExpectErrorCode(t, resp, "METHOD_NOT_ALLOWED")
+
+	// 14. metrics
+	resp = GET(t, base+"/metrics")
+	ExpectStatus(t, resp, http.StatusOK)
+	body, _ := io.ReadAll(resp.Body)
+	wantMetrics := []string{
+		"pull_requests_created_total",
+		"pull_requests_merged_total",
+		`http_request_duration_seconds_count{route="/pullRequest/create",method="POST",status="201"}`,
+		"open_reviews",
+	}
+	if env.Backend == config.BackendPostgres {
+		wantMetrics = append(wantMetrics, "db_pool_max_conns")
+	}
+	for _, want := range wantMetrics {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("metrics output is missing %s", want)
+		}
+	}
+	// 15. readiness
+	resp = GET(t, base+"/health/ready")
+	ExpectStatus(t, resp, http.StatusOK)
+	var ready handler.ReadinessResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ready); err != nil {
+		t.Fatalf("failed to decode readiness response: %v", err)
+	}
+	wantChecks := []string{"database", "migrations"}
+	if env.Backend == config.BackendPostgres {
+		wantChecks = append(wantChecks, "pool")
+	}
+	for _, check := range wantChecks {
+		if ready.Checks[check].Status != "ok" {
+			t.Fatalf("readiness check %s is not ok: %+v", check, ready.Checks[check])
+		}
+	}
+
+	// 16. a schema behind the binary makes the instance unready, but still live
+	latest := migrations.Latest()
+	if env.Backend == config.BackendSQLite {
+		latest = migrations.LatestSQLite()
+	}
+	env.Exec(t, fmt.Sprintf(`INSERT INTO goose_db_version (version_id, is_applied) VALUES (%d, false)`, latest))
+	resp = GET(t, base+"/health/ready")
+	ExpectStatus(t, resp, http.StatusServiceUnavailable)
+	resp = GET(t, base+"/health/live")
+	ExpectStatus(t, resp, http.StatusOK)
 }
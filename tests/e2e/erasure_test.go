@@ -0,0 +1,131 @@
+package e2e
+
+import (
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/handler/dto"
+)
+
+const erasureAdminToken = "erasure-token"
+
+func TestUserErasure(t *testing.T) {
+	env := SetupTestEnv(t, WithAdminToken(erasureAdminToken))
+	defer TearDown(env)
+
+	runUserErasure(t, env)
+}
+
+func TestUserErasureSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite), WithAdminToken(erasureAdminToken))
+	defer TearDown(env)
+
+	runUserErasure(t, env)
+}
+
+func runUserErasure(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	resp := POST(t, base+"/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "e1", "username": "alice", "is_active": true},
+			{"user_id": "e2", "username": "bob", "is_active": true},
+			{"user_id": "e3", "username": "carol", "is_active": true},
+		},
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-e1", "pull_request_name": "Authored", "author_id": "e1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	resp = POST(t, base+"/pullRequest/merge", map[string]any{"pull_request_id": "pr-e1"})
+	ExpectStatus(t, resp, http.StatusOK)
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-e2", "pull_request_name": "Reviewed", "author_id": "e2",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	resp = POST(t, base+"/team/addMembers", map[string]any{
+		"team_name": "backend",
+		"members":   []map[string]any{{"user_id": "e4", "username": "dan", "is_active": true}},
+	})
+	ExpectStatus(t, resp, http.StatusOK)
+
+	resp = GET(t, base+"/stats")
+	ExpectStatus(t, resp, http.StatusOK)
+	var before domain.StatsResponse
+	decodeJSON(t, resp, &before)
+
+	// 1. the admin token is required and the user must exist
+	resp = eraseUser(t, base, "", "e1")
+	ExpectStatus(t, resp, http.StatusUnauthorized)
+	resp = eraseUser(t, base, erasureAdminToken, "missing")
+	ExpectStatus(t, resp, http.StatusNotFound)
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	// 2. the receipt lists what changed
+	resp = eraseUser(t, base, erasureAdminToken, "e1")
+	ExpectStatus(t, resp, http.StatusOK)
+	var receipt dto.ErasureReceiptResponse
+	decodeJSON(t, resp, &receipt)
+	if receipt.UserID != "e1" || !strings.HasPrefix(receipt.Pseudonym, domain.PseudonymPrefix) || receipt.ErasedAt.IsZero() {
+		t.Fatalf("unexpected receipt: %+v", receipt)
+	}
+	if !slices.Equal(receipt.Teams, []string{"backend"}) || receipt.PullRequestsKept != 1 || receipt.EventsScrubbed == 0 {
+		t.Fatalf("unexpected receipt: %+v", receipt)
+	}
+	wantReviews := []dto.ReviewerChangeResponse{{PullRequestID: "pr-e2", OldReviewerID: "e1", NewReviewerID: "e4"}}
+	if !slices.Equal(receipt.Reviews, wantReviews) {
+		t.Fatalf("expected reviews %+v, got %+v", wantReviews, receipt.Reviews)
+	}
+
+	// 3. the user is gone but their pull requests and statistics stay
+	resp = GET(t, base+"/users/get?user_id=e1")
+	ExpectStatus(t, resp, http.StatusNotFound)
+	list := listUsers(t, base, "?team_name=backend")
+	if got := userIDs(list); !slices.Equal(got, []string{"e2", "e3", "e4"}) {
+		t.Fatalf("expected e1 to leave backend, got %v", got)
+	}
+	if ids := reviewPRIDs(t, base, "e4"); !slices.Equal(ids, []string{"pr-e2"}) {
+		t.Fatalf("expected e4 to review pr-e2, got %v", ids)
+	}
+
+	resp = GET(t, base+"/stats")
+	ExpectStatus(t, resp, http.StatusOK)
+	var after domain.StatsResponse
+	decodeJSON(t, resp, &after)
+	// Handing over the review adds one reviewer_reassigned event.
+	before.EventCounts[domain.EventTypeReviewerReassigned]++
+	before.TotalEvents++
+	if !reflect.DeepEqual(before, after) {
+		t.Fatalf("expected stats %+v, got %+v", before, after)
+	}
+
+	// 4. erasing is not repeatable
+	resp = eraseUser(t, base, erasureAdminToken, "e1")
+	ExpectStatus(t, resp, http.StatusNotFound)
+}
+
+func eraseUser(t *testing.T, base, token, userID string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, base+"/admin/users/erase", strings.NewReader(`{"user_id": "`+userID+`"}`))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(handler.AdminTokenHeader, token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	return resp
+}
@@ -29,6 +29,36 @@ func POST_RAW(t *testing.T, url string, b []byte) *http.Response { //nolint:styl
 	return resp
 }
 
+func DO(t *testing.T, method, url, token string, body any) *http.Response { //nolint:stylecheck
+	t.Helper()
+
+	var reader *bytes.Buffer
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("error marshalling request: %v", err)
+		}
+		reader = bytes.NewBuffer(b)
+	} else {
+		reader = &bytes.Buffer{}
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s failed: %v", method, err)
+	}
+	return resp
+}
+
 func GET(t *testing.T, url string) *http.Response {
 	t.Helper()
 
@@ -0,0 +1,102 @@
+package e2e
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+)
+
+var hierarchyReview = config.ReviewConfig{
+	ReviewersPerPR: 2,
+	Fallback:       "none",
+	Teams:          map[string]config.TeamPolicy{"ios": {Fallback: "parent"}},
+}
+
+func TestTeamHierarchy(t *testing.T) {
+	env := SetupTestEnv(t, WithReviewConfig(hierarchyReview))
+	defer TearDown(env)
+
+	runTeamHierarchy(t, env)
+}
+
+func TestTeamHierarchySQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite), WithReviewConfig(hierarchyReview))
+	defer TearDown(env)
+
+	runTeamHierarchy(t, env)
+}
+
+func runTeamHierarchy(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	for _, team := range []map[string]any{
+		{"team_name": "mobile-dept", "members": []map[string]any{}},
+		{"team_name": "ios", "parent_team": "mobile-dept", "members": []map[string]any{
+			{"user_id": "h1", "username": "pavel", "is_active": true},
+			{"user_id": "h2", "username": "rita", "is_active": true},
+		}},
+		{"team_name": "android", "parent_team": "mobile-dept", "members": []map[string]any{
+			{"user_id": "h3", "username": "sasha", "is_active": true},
+		}},
+		{"team_name": "watch", "members": []map[string]any{
+			{"user_id": "h4", "username": "tanya", "is_active": true},
+		}},
+	} {
+		resp := POST(t, base+"/team/add", team)
+		ExpectStatus(t, resp, http.StatusCreated)
+	}
+
+	resp := POST(t, base+"/team/add", map[string]any{"team_name": "web", "parent_team": "nowhere", "members": []map[string]any{}})
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	// 1. a sub-team can be attached later and the tree shows up in /team/get
+	resp = POST(t, base+"/team/setParent", map[string]any{"team_name": "watch", "parent_team": "ios"})
+	ExpectStatus(t, resp, http.StatusOK)
+	if watch := decodeTeam(t, resp, "team"); !slices.Equal(watch.Ancestors, []string{"ios", "mobile-dept"}) {
+		t.Fatalf("expected watch under ios and mobile-dept, got %v", watch.Ancestors)
+	}
+
+	resp = GET(t, base+"/team/get?team_name=mobile-dept")
+	ExpectStatus(t, resp, http.StatusOK)
+	dept := decodeTeam(t, resp, "")
+	if len(dept.SubTeams) != 2 || dept.SubTeams[0].Name != "android" || dept.SubTeams[1].Name != "ios" ||
+		len(dept.SubTeams[1].SubTeams) != 1 || dept.SubTeams[1].SubTeams[0].Name != "watch" {
+		t.Fatalf("unexpected tree under mobile-dept: %+v", dept.SubTeams)
+	}
+
+	resp = POST(t, base+"/team/setParent", map[string]any{"team_name": "mobile-dept", "parent_team": "watch"})
+	ExpectErrorCode(t, resp, "INVALID_INPUT")
+
+	// 2. with the parent fallback ios borrows a reviewer from its siblings
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "hr-1", "pull_request_name": "ios change", "author_id": "h1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	pr := decodePR(t, resp)
+	if len(pr.AssignedReviewers) != 2 || pr.AssignedReviewers[0] != "h2" {
+		t.Fatalf("expected h2 and a reviewer from the department, got %v", pr.AssignedReviewers)
+	}
+	borrowed := pr.AssignedReviewers[1]
+	if borrowed != "h3" && borrowed != "h4" {
+		t.Fatalf("expected h3 or h4 to be borrowed, got %s", borrowed)
+	}
+
+	// 3. teams without a fallback stay on their own
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "hr-2", "pull_request_name": "android change", "author_id": "h3",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	if pr := decodePR(t, resp); len(pr.AssignedReviewers) != 0 {
+		t.Fatalf("expected no reviewers for android, got %v", pr.AssignedReviewers)
+	}
+
+	// 4. reassignment walks up the same way
+	resp = POST(t, base+"/pullRequest/reassign", map[string]any{"pull_request_id": "hr-1", "old_reviewer_id": "h2"})
+	ExpectStatus(t, resp, http.StatusOK)
+	pr = decodePR(t, resp)
+	if slices.Contains(pr.AssignedReviewers, "h2") || !slices.Contains(pr.AssignedReviewers, borrowed) {
+		t.Fatalf("expected h2 to be replaced from the department, got %v", pr.AssignedReviewers)
+	}
+}
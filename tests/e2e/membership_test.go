@@ -0,0 +1,142 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/handler/dto"
+)
+
+func TestTeamMembership(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TearDown(env)
+
+	runTeamMembership(t, env)
+}
+
+func TestTeamMembershipSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite))
+	defer TearDown(env)
+
+	runTeamMembership(t, env)
+}
+
+func runTeamMembership(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	for _, team := range []map[string]any{
+		{"team_name": "core", "members": []map[string]any{
+			{"user_id": "c1", "username": "anya", "is_active": true},
+			{"user_id": "c2", "username": "boris", "is_active": true},
+			{"user_id": "c3", "username": "vera", "is_active": true},
+		}},
+		{"team_name": "infra", "members": []map[string]any{
+			{"user_id": "i1", "username": "gleb", "is_active": true},
+		}},
+	} {
+		resp := POST(t, base+"/team/add", team)
+		ExpectStatus(t, resp, http.StatusCreated)
+	}
+
+	resp := POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "mb-1", "pull_request_name": "core change", "author_id": "c1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	if pr := decodePR(t, resp); len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("expected c2 and c3 as reviewers, got %v", pr.AssignedReviewers)
+	}
+
+	// 1. removing a reviewer nobody can replace unassigns the review
+	resp = POST(t, base+"/team/removeMember", map[string]any{"team_name": "core", "user_id": "c2"})
+	ExpectStatus(t, resp, http.StatusOK)
+	change := decodeMembershipChange(t, resp)
+	if len(change.Reviews) != 1 || change.Reviews[0].PullRequestID != "mb-1" || change.Reviews[0].NewReviewerID != "" {
+		t.Fatalf("expected mb-1 to be unassigned, got %+v", change.Reviews)
+	}
+	if ids := reviewPRIDs(t, base, "c2"); len(ids) != 0 {
+		t.Fatalf("expected c2 to have no reviews left, got %v", ids)
+	}
+
+	// 2. new members can be added to an existing team
+	resp = POST(t, base+"/team/addMembers", map[string]any{
+		"team_name": "core",
+		"members":   []map[string]any{{"user_id": "c4", "username": "dima", "is_active": true}},
+	})
+	ExpectStatus(t, resp, http.StatusOK)
+
+	// existing users are not overwritten unless asked to
+	resp = POST(t, base+"/team/addMembers", map[string]any{
+		"team_name": "infra",
+		"members":   []map[string]any{{"user_id": "c4", "username": "dmitry", "is_active": true}},
+	})
+	ExpectStatus(t, resp, http.StatusConflict)
+	ExpectErrorCode(t, resp, "MEMBER_CONFLICT")
+	resp = GET(t, base+"/users/get?user_id=c4")
+	ExpectStatus(t, resp, http.StatusOK)
+	var c4 struct {
+		User dto.UserDetailsResponse `json:"user"`
+	}
+	decodeJSON(t, resp, &c4)
+	if c4.User.Username != "dima" || len(c4.User.Memberships) != 1 {
+		t.Fatalf("expected c4 to be unchanged, got %+v", c4.User)
+	}
+
+	// 3. a transferred reviewer hands the review to a remaining member
+	resp = POST(t, base+"/team/transferMember", map[string]any{"user_id": "c3", "from_team": "core", "to_team": "infra"})
+	ExpectStatus(t, resp, http.StatusOK)
+	change = decodeMembershipChange(t, resp)
+	if len(change.Reviews) != 1 || change.Reviews[0].OldReviewerID != "c3" || change.Reviews[0].NewReviewerID != "c4" {
+		t.Fatalf("expected mb-1 to move from c3 to c4, got %+v", change.Reviews)
+	}
+	if ids := reviewPRIDs(t, base, "c4"); len(ids) != 1 || ids[0] != "mb-1" {
+		t.Fatalf("expected c4 to review mb-1, got %v", ids)
+	}
+
+	// 4. invalid changes are rejected and leave membership untouched
+	resp = POST(t, base+"/team/transferMember", map[string]any{"user_id": "c3", "from_team": "core", "to_team": "infra"})
+	ExpectErrorCode(t, resp, "NOT_TEAM_MEMBER")
+
+	resp = POST(t, base+"/team/transferMember", map[string]any{"user_id": "c3", "from_team": "infra", "to_team": "nowhere"})
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	resp = POST(t, base+"/team/addMembers", map[string]any{
+		"team_name": "nowhere",
+		"members":   []map[string]any{{"user_id": "c5", "username": "egor", "is_active": true}},
+	})
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	resp = GET(t, base+"/team/get?team_name=infra")
+	ExpectStatus(t, resp, http.StatusOK)
+	var infra domain.Team
+	if err := json.NewDecoder(resp.Body).Decode(&infra); err != nil {
+		t.Fatalf("failed to decode team: %v", err)
+	}
+	if len(infra.Members) != 2 {
+		t.Fatalf("expected i1 and c3 in infra, got %+v", infra.Members)
+	}
+
+	// 5. both kinds of change are recorded as events
+	resp = GET(t, base+"/stats")
+	ExpectStatus(t, resp, http.StatusOK)
+	var stats domain.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode stats: %v", err)
+	}
+	if stats.EventCounts[domain.EventTypeReviewerUnassigned] != 1 || stats.EventCounts[domain.EventTypeReviewerReassigned] != 1 {
+		t.Fatalf("expected one unassigned and one reassigned event, got %v", stats.EventCounts)
+	}
+}
+
+func decodeMembershipChange(t *testing.T, resp *http.Response) dto.MembershipChangeResponse {
+	t.Helper()
+	defer resp.Body.Close()
+
+	var body dto.MembershipChangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode membership change: %v", err)
+	}
+	return body
+}
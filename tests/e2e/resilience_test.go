@@ -0,0 +1,149 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler/dto"
+	"github.com/111zxc/pr-review-service/internal/repository/decorator"
+)
+
+func TestResilience(t *testing.T) {
+	faults := decorator.NewFaults(1)
+	env := SetupTestEnv(t, WithRepositoryMiddleware(faults.Middleware()))
+	defer TearDown(env)
+
+	runResilience(t, env, faults)
+}
+
+func TestResilienceSQLite(t *testing.T) {
+	faults := decorator.NewFaults(1)
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite), WithRepositoryMiddleware(faults.Middleware()))
+	defer TearDown(env)
+
+	runResilience(t, env, faults)
+}
+
+func runResilience(t *testing.T, env *TestEnv, faults *decorator.Faults) {
+	base := env.Server.URL
+
+	resp := POST(t, base+"/team/add", map[string]any{
+		"team_name": "core",
+		"members": []map[string]any{
+			{"user_id": "c1", "username": "anna", "is_active": true},
+			{"user_id": "c2", "username": "boris", "is_active": true},
+			{"user_id": "c3", "username": "vera", "is_active": true},
+			{"user_id": "c4", "username": "gleb", "is_active": true},
+		},
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	// 1. event writes are best effort: a PR is created even when some or
+	// all of its events are lost
+	faults.Set(decorator.FaultRule{Operation: "Events.CreateEvent", ErrorRate: 0.1})
+	for i := range 20 {
+		resp = POST(t, base+"/pullRequest/create", map[string]any{
+			"pull_request_id":   fmt.Sprintf("flaky-%d", i),
+			"pull_request_name": "flaky events",
+			"author_id":         "c1",
+		})
+		ExpectStatus(t, resp, http.StatusCreated)
+	}
+
+	faults.Set(decorator.FaultRule{Operation: "Events.*", ErrorRate: 1})
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-no-events",
+		"pull_request_name": "no events",
+		"author_id":         "c1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	reviewers := decodePR(t, resp).AssignedReviewers
+	if len(reviewers) == 0 {
+		t.Fatalf("expected reviewers to be assigned")
+	}
+
+	faults.Set()
+	if !slices.Contains(reviewPRIDs(t, base, reviewers[0]), "pr-no-events") {
+		t.Fatalf("reviewer %s does not see pr-no-events", reviewers[0])
+	}
+
+	// 2. a failed reviewer update leaves the assignment untouched
+	faults.Set(decorator.FaultRule{Operation: "PR.Update", ErrorRate: 1})
+	resp = POST(t, base+"/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-no-events",
+		"old_reviewer_id": reviewers[0],
+	})
+	ExpectStatus(t, resp, http.StatusInternalServerError)
+	ExpectErrorCode(t, resp, "INTERNAL_ERROR")
+
+	faults.Set()
+	if !slices.Contains(reviewPRIDs(t, base, reviewers[0]), "pr-no-events") {
+		t.Fatalf("failed reassignment removed reviewer %s", reviewers[0])
+	}
+	resp = POST(t, base+"/pullRequest/reassign", map[string]any{
+		"pull_request_id": "pr-no-events",
+		"old_reviewer_id": reviewers[0],
+	})
+	ExpectStatus(t, resp, http.StatusOK)
+
+	// 3. a failed status lookup neither merges the PR nor breaks a retry
+	faults.Set(decorator.FaultRule{Operation: "PR.GetByID", ErrorRate: 1})
+	resp = POST(t, base+"/pullRequest/merge", map[string]any{"pull_request_id": "pr-no-events"})
+	ExpectStatus(t, resp, http.StatusInternalServerError)
+
+	faults.Set()
+	resp = POST(t, base+"/pullRequest/merge", map[string]any{"pull_request_id": "pr-no-events"})
+	ExpectStatus(t, resp, http.StatusOK)
+	if status := decodePR(t, resp).Status; status != "MERGED" {
+		t.Fatalf("expected MERGED after retry, got %s", status)
+	}
+
+	// 4. slow storage only slows requests down
+	faults.Set(decorator.FaultRule{Operation: "User.*", Delay: 50 * time.Millisecond})
+	start := time.Now()
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id":   "pr-slow",
+		"pull_request_name": "slow users",
+		"author_id":         "c2",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected the injected delay, request took %s", elapsed)
+	}
+	faults.Set()
+}
+
+func decodePR(t *testing.T, resp *http.Response) dto.PullRequestResponse {
+	t.Helper()
+
+	var body struct {
+		PR dto.PullRequestResponse `json:"pr"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode pull request: %v", err)
+	}
+	return body.PR
+}
+
+func reviewPRIDs(t *testing.T, base, userID string) []string {
+	t.Helper()
+
+	resp := GET(t, base+"/users/getReview?user_id="+userID)
+	ExpectStatus(t, resp, http.StatusOK)
+
+	var reviews dto.UserReviewsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&reviews); err != nil {
+		t.Fatalf("failed to decode reviews: %v", err)
+	}
+
+	ids := make([]string, 0, len(reviews.PullRequests))
+	for _, pr := range reviews.PullRequests {
+		ids = append(ids, pr.ID)
+	}
+	return ids
+}
@@ -0,0 +1,117 @@
+package e2e
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/domain"
+)
+
+var required = true
+
+var rolesReview = config.ReviewConfig{
+	ReviewersPerPR: 2,
+	Fallback:       "none",
+	Teams:          map[string]config.TeamPolicy{"payments": {ReviewersPerPR: 1, RequireMaintainer: &required}},
+}
+
+func TestTeamRoles(t *testing.T) {
+	env := SetupTestEnv(t, WithReviewConfig(rolesReview), WithAdminToken(roleAdminToken))
+	defer TearDown(env)
+
+	runTeamRoles(t, env)
+}
+
+func TestTeamRolesSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite), WithReviewConfig(rolesReview), WithAdminToken(roleAdminToken))
+	defer TearDown(env)
+
+	runTeamRoles(t, env)
+}
+
+func runTeamRoles(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	resp := POST(t, base+"/team/add", map[string]any{
+		"team_name": "payments",
+		"members": []map[string]any{
+			{"user_id": "r1", "username": "anna", "is_active": true},
+			{"user_id": "r2", "username": "boris", "is_active": true, "role": "lead"},
+			{"user_id": "r3", "username": "vera", "is_active": true, "role": "maintainer"},
+			{"user_id": "r4", "username": "gleb", "is_active": true},
+			{"user_id": "r5", "username": "dina", "is_active": true, "role": "trainee"},
+		},
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	// 1. roles show up in /team/get
+	resp = GET(t, base+"/team/get?team_name=payments")
+	ExpectStatus(t, resp, http.StatusOK)
+	roles := make(map[string]domain.TeamRole)
+	for _, m := range decodeTeam(t, resp, "").Members {
+		roles[m.UserID] = m.Role
+	}
+	want := map[string]domain.TeamRole{
+		"r1": domain.RoleMember, "r2": domain.RoleLead, "r3": domain.RoleMaintainer,
+		"r4": domain.RoleMember, "r5": domain.RoleTrainee,
+	}
+	if !maps.Equal(roles, want) {
+		t.Fatalf("unexpected roles: %v", roles)
+	}
+
+	// 2. payments requires a maintainer, so its only reviewer is r3
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "ro-1", "pull_request_name": "refunds", "author_id": "r1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	if pr := decodePR(t, resp); !slices.Equal(pr.AssignedReviewers, []string{"r3"}) {
+		t.Fatalf("expected the maintainer r3, got %v", pr.AssignedReviewers)
+	}
+
+	// 3. without a maintainer the review is escalated to the lead; with
+	// authentication off, only the admin endpoint changes roles
+	resp = POST(t, base+"/team/setRole", map[string]any{"team_name": "payments", "user_id": "r3", "role": "member"})
+	ExpectStatus(t, resp, http.StatusForbidden)
+	resp = setRoleAsAdmin(t, base, map[string]any{"team_name": "payments", "user_id": "r3", "role": "member"})
+	ExpectStatus(t, resp, http.StatusOK)
+	if r3 := decodeTeam(t, resp, "team").Members; !slices.Contains(r3, domain.TeamMember{UserID: "r3", Username: "vera", IsActive: true, Role: domain.RoleMember}) {
+		t.Fatalf("expected r3 to be a member now, got %v", r3)
+	}
+
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "ro-2", "pull_request_name": "payouts", "author_id": "r1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	if pr := decodePR(t, resp); !slices.Equal(pr.AssignedReviewers, []string{"r2"}) {
+		t.Fatalf("expected the lead r2, got %v", pr.AssignedReviewers)
+	}
+
+	// 4. bad requests
+	resp = setRoleAsAdmin(t, base, map[string]any{"team_name": "payments", "user_id": "r4", "role": "owner"})
+	ExpectErrorCode(t, resp, "INVALID_INPUT")
+
+	resp = POST(t, base+"/team/add", map[string]any{
+		"team_name": "interns",
+		"members": []map[string]any{
+			{"user_id": "i1", "username": "egor", "is_active": true},
+			{"user_id": "i2", "username": "zoya", "is_active": true, "role": "trainee"},
+			{"user_id": "i3", "username": "ilya", "is_active": true, "role": "lead"},
+		},
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	resp = setRoleAsAdmin(t, base, map[string]any{"team_name": "interns", "user_id": "r4", "role": "member"})
+	ExpectErrorCode(t, resp, "NOT_TEAM_MEMBER")
+
+	// 5. a trainee never reviews alone: the lead takes it instead
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "ro-3", "pull_request_name": "onboarding", "author_id": "i1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	if pr := decodePR(t, resp); !slices.Equal(pr.AssignedReviewers, []string{"i3"}) {
+		t.Fatalf("expected the lead i3, got %v", pr.AssignedReviewers)
+	}
+}
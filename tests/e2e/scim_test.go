@@ -0,0 +1,202 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/scim"
+)
+
+const scimToken = "idp-token"
+
+func TestSCIM(t *testing.T) {
+	env := SetupTestEnv(t, WithSCIMToken(scimToken))
+	defer TearDown(env)
+
+	runSCIM(t, env)
+}
+
+func TestSCIMSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite), WithSCIMToken(scimToken))
+	defer TearDown(env)
+
+	runSCIM(t, env)
+}
+
+// runSCIM replays requests recorded from Okta and Azure AD provisioning.
+func runSCIM(t *testing.T, env *TestEnv) {
+	scimURL := env.Server.URL + "/scim/v2"
+
+	// 1. the bearer token is required
+	resp := DO(t, http.MethodGet, scimURL+"/Users", "", nil)
+	ExpectStatus(t, resp, http.StatusUnauthorized)
+	resp = DO(t, http.MethodGet, scimURL+"/Users", "wrong", nil)
+	ExpectStatus(t, resp, http.StatusUnauthorized)
+	resp = DO(t, http.MethodGet, scimURL+"/ServiceProviderConfig", scimToken, nil)
+	ExpectStatus(t, resp, http.StatusOK)
+
+	// 2. users are created with their externalId, or their userName without one
+	resp = DO(t, http.MethodPost, scimURL+"/Users", scimToken, scimPayload(t, "okta_create_user.json"))
+	ExpectStatus(t, resp, http.StatusCreated)
+	if user := decodeSCIM[scim.User](t, resp); user.ID != "dana@example.com" || !user.Active {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+	resp = DO(t, http.MethodPost, scimURL+"/Users", scimToken, scimPayload(t, "azure_create_user.json"))
+	ExpectStatus(t, resp, http.StatusCreated)
+	if resp.Header.Get("Location") != scimURL+"/Users/erik" {
+		t.Fatalf("unexpected location %q", resp.Header.Get("Location"))
+	}
+	resp = DO(t, http.MethodPost, scimURL+"/Users", scimToken, scimPayload(t, "okta_create_user.json"))
+	ExpectStatus(t, resp, http.StatusConflict)
+	if e := decodeSCIM[scim.Error](t, resp); e.ScimType != scim.ErrUniqueness {
+		t.Fatalf("expected a uniqueness error, got %+v", e)
+	}
+
+	// 3. users are found by the filters identity providers send
+	for filter, total := range map[string]int{
+		`userName eq "dana@example.com"`: 1,
+		`externalId eq "erik"`:           1,
+		`userName eq "nobody"`:           0,
+	} {
+		resp = DO(t, http.MethodGet, scimURL+"/Users?filter="+url.QueryEscape(filter), scimToken, nil)
+		ExpectStatus(t, resp, http.StatusOK)
+		if list := decodeSCIM[scim.ListResponse](t, resp); list.TotalResults != total {
+			t.Fatalf("%s: expected %d users, got %+v", filter, total, list)
+		}
+	}
+	resp = DO(t, http.MethodGet, scimURL+"/Users?filter="+url.QueryEscape(`name.familyName eq "Sand"`), scimToken, nil)
+	ExpectStatus(t, resp, http.StatusBadRequest)
+
+	// 4. a group is a team; members are added by user ID
+	resp = DO(t, http.MethodPost, scimURL+"/Groups", scimToken, scimPayload(t, "okta_create_group.json"))
+	ExpectStatus(t, resp, http.StatusCreated)
+	group := decodeSCIM[scim.Group](t, resp)
+	if group.ID == "" || group.DisplayName != "platform" {
+		t.Fatalf("unexpected group: %+v", group)
+	}
+	groupURL := scimURL + "/Groups/" + group.ID
+
+	resp = DO(t, http.MethodPatch, groupURL, scimToken, scimPayload(t, "okta_add_members.json"))
+	ExpectStatus(t, resp, http.StatusOK)
+	if group = decodeSCIM[scim.Group](t, resp); len(group.Members) != 2 {
+		t.Fatalf("expected two members, got %+v", group.Members)
+	}
+	resp = GET(t, env.Server.URL+"/team/get?team_name=platform")
+	ExpectStatus(t, resp, http.StatusOK)
+	if team := decodeTeam(t, resp, ""); len(team.Members) != 2 {
+		t.Fatalf("expected the team to have two members, got %+v", team.Members)
+	}
+
+	// 5. removing a member hands their reviews back
+	resp = POST(t, env.Server.URL+"/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-scim", "pull_request_name": "SCIM", "author_id": "dana@example.com",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	if ids := reviewPRIDs(t, env.Server.URL, "erik"); len(ids) != 1 {
+		t.Fatalf("expected erik to review pr-scim, got %v", ids)
+	}
+	resp = DO(t, http.MethodPatch, groupURL, scimToken, scimPayload(t, "azure_remove_member.json"))
+	ExpectStatus(t, resp, http.StatusOK)
+	if group = decodeSCIM[scim.Group](t, resp); len(group.Members) != 1 || group.Members[0].Value != "dana@example.com" {
+		t.Fatalf("expected only dana to be left, got %+v", group.Members)
+	}
+	if ids := reviewPRIDs(t, env.Server.URL, "erik"); len(ids) != 0 {
+		t.Fatalf("expected erik's review to be released, got %v", ids)
+	}
+
+	// 6. users are renamed and deactivated by PATCH
+	resp = DO(t, http.MethodPatch, scimURL+"/Users/erik", scimToken, scimPayload(t, "azure_rename_user.json"))
+	ExpectStatus(t, resp, http.StatusOK)
+	if user := decodeSCIM[scim.User](t, resp); user.UserName != "erik.sand@example.com" || !user.Active {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+	resp = DO(t, http.MethodPatch, scimURL+"/Users/erik", scimToken, scimPayload(t, "azure_deactivate_user.json"))
+	ExpectStatus(t, resp, http.StatusOK)
+	if user := decodeSCIM[scim.User](t, resp); user.Active {
+		t.Fatalf("expected erik to be inactive, got %+v", user)
+	}
+
+	// 7. renaming the group renames the team
+	resp = DO(t, http.MethodPatch, groupURL, scimToken, scimPayload(t, "okta_rename_group.json"))
+	ExpectStatus(t, resp, http.StatusOK)
+	resp = GET(t, env.Server.URL+"/team/get?team_name=platform-core")
+	ExpectStatus(t, resp, http.StatusOK)
+	resp = DO(t, http.MethodGet, scimURL+"/Users/dana@example.com", scimToken, nil)
+	ExpectStatus(t, resp, http.StatusOK)
+	if user := decodeSCIM[scim.User](t, resp); len(user.Groups) != 1 || user.Groups[0].Value != group.ID {
+		t.Fatalf("expected dana to be in %s, got %+v", group.ID, user.Groups)
+	}
+
+	resp = DO(t, http.MethodPatch, scimURL+"/Users/dana@example.com", scimToken, scimPayload(t, "okta_deactivate_user.json"))
+	ExpectStatus(t, resp, http.StatusOK)
+	resp = DO(t, http.MethodGet, scimURL+"/Users?filter="+url.QueryEscape("active eq false")+"&startIndex=2&count=1", scimToken, nil)
+	ExpectStatus(t, resp, http.StatusOK)
+	if list := decodeSCIM[scim.ListResponse](t, resp); list.TotalResults != 2 || list.ItemsPerPage != 1 || list.StartIndex != 2 {
+		t.Fatalf("unexpected page: %+v", list)
+	}
+
+	// 8. a deleted user is gone until provisioned again; their pull requests stay
+	resp = DO(t, http.MethodDelete, scimURL+"/Users/dana@example.com", scimToken, nil)
+	ExpectStatus(t, resp, http.StatusNoContent)
+	resp = DO(t, http.MethodGet, groupURL, scimToken, nil)
+	ExpectStatus(t, resp, http.StatusOK)
+	if group = decodeSCIM[scim.Group](t, resp); len(group.Members) != 0 {
+		t.Fatalf("expected the group to be empty, got %+v", group.Members)
+	}
+	resp = DO(t, http.MethodGet, scimURL+"/Users/dana@example.com", scimToken, nil)
+	ExpectStatus(t, resp, http.StatusNotFound)
+	resp = DO(t, http.MethodGet, scimURL+"/Users?filter="+url.QueryEscape(`userName eq "dana@example.com"`), scimToken, nil)
+	ExpectStatus(t, resp, http.StatusOK)
+	if list := decodeSCIM[scim.ListResponse](t, resp); list.TotalResults != 0 {
+		t.Fatalf("expected dana to be gone from the list, got %+v", list)
+	}
+	resp = DO(t, http.MethodDelete, scimURL+"/Users/dana@example.com", scimToken, nil)
+	ExpectStatus(t, resp, http.StatusNotFound)
+	resp = DO(t, http.MethodPost, scimURL+"/Users", scimToken, scimPayload(t, "okta_create_user.json"))
+	ExpectStatus(t, resp, http.StatusCreated)
+	if user := decodeSCIM[scim.User](t, resp); user.ID != "dana@example.com" || !user.Active || len(user.Groups) != 0 {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	// 9. a group with open pull requests is kept until they are finished
+	resp = DO(t, http.MethodDelete, groupURL, scimToken, nil)
+	ExpectStatus(t, resp, http.StatusConflict)
+	if e := decodeSCIM[scim.Error](t, resp); e.ScimType != scim.ErrMutability {
+		t.Fatalf("expected a mutability error, got %+v", e)
+	}
+	resp = POST(t, env.Server.URL+"/pullRequest/merge", map[string]any{"pull_request_id": "pr-scim"})
+	ExpectStatus(t, resp, http.StatusOK)
+	resp = DO(t, http.MethodDelete, groupURL, scimToken, nil)
+	ExpectStatus(t, resp, http.StatusNoContent)
+	resp = DO(t, http.MethodGet, groupURL, scimToken, nil)
+	ExpectStatus(t, resp, http.StatusNotFound)
+}
+
+func scimPayload(t *testing.T, name string) json.RawMessage {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata", "scim", name))
+	if err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+	return b
+}
+
+func decodeSCIM[T any](t *testing.T, resp *http.Response) T {
+	t.Helper()
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != scim.ContentType {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	var body T
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode SCIM response: %v", err)
+	}
+	return body
+}
@@ -0,0 +1,205 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/handler/dto"
+)
+
+const syncAdminToken = "sync-token"
+
+func TestRosterSync(t *testing.T) {
+	env := SetupTestEnv(t, WithAdminToken(syncAdminToken))
+	defer TearDown(env)
+
+	runRosterSync(t, env)
+}
+
+func TestRosterSyncSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite), WithAdminToken(syncAdminToken))
+	defer TearDown(env)
+
+	runRosterSync(t, env)
+}
+
+const orgRoster = `
+teams:
+  - team_name: platform
+    members:
+      - {user_id: s1, username: alice, role: lead}
+  - team_name: backend
+    parent_team: platform
+    members:
+      - {user_id: s1, username: alice}
+      - {user_id: s3, username: carol}
+      - {user_id: s4, username: dan}
+  - team_name: frontend
+    members:
+      - {user_id: s2, username: bob}
+`
+
+func runRosterSync(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	resp := POST(t, base+"/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "s1", "username": "alice", "is_active": true},
+			{"user_id": "s2", "username": "bob", "is_active": true},
+			{"user_id": "s3", "username": "carol", "is_active": true},
+		},
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-s1", "pull_request_name": "Sync", "author_id": "s1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	// 1. the token is required and the roster must be valid
+	resp = syncRoster(t, base, "", "", orgRoster)
+	ExpectStatus(t, resp, http.StatusUnauthorized)
+	resp = syncRoster(t, base, syncAdminToken, "", "teams:\n  - team_name: a\n    parent_team: b\n")
+	ExpectStatus(t, resp, http.StatusBadRequest)
+	ExpectErrorCode(t, resp, "INVALID_INPUT")
+	for _, empty := range []string{"", `{"teams": []}`} {
+		resp = syncRoster(t, base, syncAdminToken, "?open_prs=close", empty)
+		ExpectStatus(t, resp, http.StatusBadRequest)
+		ExpectErrorCode(t, resp, "INVALID_INPUT")
+	}
+
+	// 2. a dry run reports the plan without applying it
+	resp = syncRoster(t, base, syncAdminToken, "?dry_run=true", orgRoster)
+	ExpectStatus(t, resp, http.StatusOK)
+	plan := decodeSyncPlan(t, resp)
+	if !plan.DryRun || len(plan.Reviews) != 0 {
+		t.Fatalf("expected a dry run without reviews, got %+v", plan)
+	}
+	want := []string{
+		"create_team platform", "create_team frontend", "set_parent backend", "create_user s4",
+		"add_member platform s1", "add_member backend s4", "transfer_member frontend s2",
+	}
+	if got := syncActions(plan); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected plan %v, got %v", want, got)
+	}
+	resp = GET(t, base+"/team/get?team_name=platform")
+	ExpectStatus(t, resp, http.StatusNotFound)
+
+	// 3. applying it hands bob's review to the new backend member
+	resp = syncRoster(t, base, syncAdminToken, "", orgRoster)
+	ExpectStatus(t, resp, http.StatusOK)
+	plan = decodeSyncPlan(t, resp)
+	if len(plan.Changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), plan.Changes)
+	}
+	if len(plan.Reviews) != 1 || plan.Reviews[0].OldReviewerID != "s2" || plan.Reviews[0].NewReviewerID != "s4" {
+		t.Fatalf("expected s2's review to go to s4, got %+v", plan.Reviews)
+	}
+
+	resp = GET(t, base+"/team/get?team_name=backend")
+	ExpectStatus(t, resp, http.StatusOK)
+	backend := decodeTeam(t, resp, "")
+	if backend.ParentName != "platform" || len(backend.Members) != 3 {
+		t.Fatalf("unexpected backend team: %+v", backend)
+	}
+	resp = GET(t, base+"/team/get?team_name=platform")
+	ExpectStatus(t, resp, http.StatusOK)
+	if platform := decodeTeam(t, resp, ""); len(platform.Members) != 1 || platform.Members[0].Role != "lead" {
+		t.Fatalf("expected alice to lead platform, got %+v", platform.Members)
+	}
+
+	// 4. syncing the same roster again changes nothing
+	resp = syncRoster(t, base, syncAdminToken, "", orgRoster)
+	ExpectStatus(t, resp, http.StatusOK)
+	if plan = decodeSyncPlan(t, resp); len(plan.Changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", plan.Changes)
+	}
+
+	// 5. dropping a team deletes it and deactivates whoever is left without one
+	shrunk, _, _ := strings.Cut(orgRoster, "  - team_name: frontend")
+	resp = syncRoster(t, base, syncAdminToken, "", shrunk)
+	ExpectStatus(t, resp, http.StatusOK)
+	want = []string{"delete_team frontend", "deactivate_user s2"}
+	if got := syncActions(decodeSyncPlan(t, resp)); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected plan %v, got %v", want, got)
+	}
+	resp = GET(t, base+"/team/get?team_name=frontend")
+	ExpectStatus(t, resp, http.StatusNotFound)
+
+	// 6. a user the roster marks inactive hands over their reviews
+	if ids := reviewPRIDs(t, base, "s4"); len(ids) != 1 || ids[0] != "pr-s1" {
+		t.Fatalf("expected s4 to review pr-s1, got %v", ids)
+	}
+	inactive := strings.Replace(shrunk, "{user_id: s4, username: dan}", "{user_id: s4, username: dan, is_active: false}", 1)
+	resp = syncRoster(t, base, syncAdminToken, "", inactive)
+	ExpectStatus(t, resp, http.StatusOK)
+	plan = decodeSyncPlan(t, resp)
+	if got := syncActions(plan); strings.Join(got, ",") != "deactivate_user s4" {
+		t.Fatalf("expected only s4 to be deactivated, got %v", got)
+	}
+	if len(plan.Reviews) != 1 || plan.Reviews[0].PullRequestID != "pr-s1" || plan.Reviews[0].OldReviewerID != "s4" {
+		t.Fatalf("expected s4's review of pr-s1 to be released, got %+v", plan.Reviews)
+	}
+	if ids := reviewPRIDs(t, base, "s4"); len(ids) != 0 {
+		t.Fatalf("expected s4 to have no reviews left, got %v", ids)
+	}
+
+	// 7. a team with open pull requests is only dropped with open_prs=close
+	platformOnly, _, _ := strings.Cut(orgRoster, "  - team_name: backend")
+	resp = syncRoster(t, base, syncAdminToken, "?dry_run=true", platformOnly)
+	ExpectStatus(t, resp, http.StatusConflict)
+	ExpectErrorCode(t, resp, "TEAM_HAS_OPEN_PRS")
+	resp = syncRoster(t, base, syncAdminToken, "?open_prs=close", platformOnly)
+	ExpectStatus(t, resp, http.StatusOK)
+	if plan = decodeSyncPlan(t, resp); len(plan.ClosedPullRequests) != 1 || plan.ClosedPullRequests[0] != "pr-s1" {
+		t.Fatalf("expected pr-s1 to be closed, got %+v", plan.ClosedPullRequests)
+	}
+}
+
+func syncRoster(t *testing.T, base, token, query, roster string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, base+"/admin/sync"+query, strings.NewReader(roster))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/yaml")
+	if token != "" {
+		req.Header.Set(handler.AdminTokenHeader, token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	return resp
+}
+
+func decodeSyncPlan(t *testing.T, resp *http.Response) dto.SyncPlanResponse {
+	t.Helper()
+	defer resp.Body.Close()
+
+	var body dto.SyncPlanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode sync plan: %v", err)
+	}
+	return body
+}
+
+// syncActions summarises each change as "action team-or-user [user]".
+func syncActions(plan dto.SyncPlanResponse) []string {
+	actions := make([]string, 0, len(plan.Changes))
+	for _, c := range plan.Changes {
+		fields := []string{c.Action}
+		for _, f := range []string{c.TeamName, c.UserID} {
+			if f != "" {
+				fields = append(fields, f)
+			}
+		}
+		actions = append(actions, strings.Join(fields, " "))
+	}
+	return actions
+}
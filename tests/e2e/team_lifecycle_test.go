@@ -0,0 +1,236 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/handler/dto"
+)
+
+func TestTeamLifecycle(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TearDown(env)
+
+	runTeamLifecycle(t, env)
+}
+
+func TestTeamLifecycleSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite))
+	defer TearDown(env)
+
+	runTeamLifecycle(t, env)
+}
+
+func runTeamLifecycle(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	for _, team := range []map[string]any{
+		{"team_name": "legacy", "members": []map[string]any{
+			{"user_id": "l1", "username": "ilya", "is_active": true},
+			{"user_id": "l2", "username": "katya", "is_active": true},
+		}},
+		{"team_name": "platform", "members": []map[string]any{
+			{"user_id": "p1", "username": "lev", "is_active": true},
+			{"user_id": "p2", "username": "masha", "is_active": true},
+		}},
+	} {
+		resp := POST(t, base+"/team/add", team)
+		ExpectStatus(t, resp, http.StatusCreated)
+	}
+
+	for _, id := range []string{"lc-1", "lc-2"} {
+		resp := POST(t, base+"/pullRequest/create", map[string]any{
+			"pull_request_id": id, "pull_request_name": "legacy change", "author_id": "l1",
+		})
+		ExpectStatus(t, resp, http.StatusCreated)
+	}
+
+	// 1. a team with open pull requests is only deleted on request
+	resp := DO(t, http.MethodDelete, base+"/team?team_name=legacy", "", nil)
+	ExpectStatus(t, resp, http.StatusConflict)
+	ExpectErrorCode(t, resp, "TEAM_HAS_OPEN_PRS")
+
+	resp = DO(t, http.MethodDelete, base+"/team?team_name=legacy&open_prs=reassign&reassign_to=legacy", "", nil)
+	ExpectErrorCode(t, resp, "INVALID_INPUT")
+
+	// 2. reassigning moves the pull requests and their reviews to the target team
+	resp = DO(t, http.MethodDelete, base+"/team?team_name=legacy&open_prs=reassign&reassign_to=platform", "", nil)
+	ExpectStatus(t, resp, http.StatusOK)
+	deletion := decodeTeamDeletion(t, resp)
+	if len(deletion.MovedPullRequests) != 2 || len(deletion.Reviews) != 2 {
+		t.Fatalf("expected two moved pull requests with one review each, got %+v", deletion)
+	}
+	if ids := reviewPRIDs(t, base, "l2"); len(ids) != 0 {
+		t.Fatalf("expected l2 to have no reviews left, got %v", ids)
+	}
+
+	resp = GET(t, base+"/team/get?team_name=legacy")
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	resp = DO(t, http.MethodDelete, base+"/team?team_name=legacy", "", nil)
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	// 3. the name stays taken until the team is restored
+	resp = POST(t, base+"/team/add", map[string]any{"team_name": "legacy", "members": []map[string]any{}})
+	ExpectErrorCode(t, resp, "TEAM_EXISTS")
+
+	resp = POST(t, base+"/team/restore", map[string]any{"team_name": "legacy"})
+	ExpectStatus(t, resp, http.StatusOK)
+	var restored struct {
+		Team domain.Team `json:"team"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&restored); err != nil {
+		t.Fatalf("failed to decode team: %v", err)
+	}
+	if len(restored.Team.Members) != 2 {
+		t.Fatalf("expected members to survive, got %+v", restored.Team.Members)
+	}
+
+	resp = POST(t, base+"/team/restore", map[string]any{"team_name": "legacy"})
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	// 4. closing leaves pull requests that can no longer be merged
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "lc-3", "pull_request_name": "last change", "author_id": "l1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	resp = DO(t, http.MethodDelete, base+"/team?team_name=legacy&open_prs=close", "", nil)
+	ExpectStatus(t, resp, http.StatusOK)
+	deletion = decodeTeamDeletion(t, resp)
+	if len(deletion.ClosedPullRequests) != 1 || deletion.ClosedPullRequests[0] != "lc-3" {
+		t.Fatalf("expected lc-3 to be closed, got %+v", deletion)
+	}
+
+	resp = POST(t, base+"/pullRequest/merge", map[string]any{"pull_request_id": "lc-3"})
+	ExpectStatus(t, resp, http.StatusConflict)
+	ExpectErrorCode(t, resp, "PR_CLOSED")
+
+	resp = POST(t, base+"/pullRequest/merge", map[string]any{"pull_request_id": "lc-1"})
+	ExpectStatus(t, resp, http.StatusOK)
+
+	// 5. history of the deleted team stays in the stats
+	resp = GET(t, base+"/stats")
+	ExpectStatus(t, resp, http.StatusOK)
+	var stats domain.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode stats: %v", err)
+	}
+	if stats.EventCounts[domain.EventTypePRCreated] != 3 || stats.EventCounts[domain.EventTypePRClosed] != 1 {
+		t.Fatalf("expected three created and one closed event, got %v", stats.EventCounts)
+	}
+}
+
+func decodeTeamDeletion(t *testing.T, resp *http.Response) dto.TeamDeletionResponse {
+	t.Helper()
+	defer resp.Body.Close()
+
+	var body dto.TeamDeletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode team deletion: %v", err)
+	}
+	return body
+}
+
+func TestTeamRename(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TearDown(env)
+
+	runTeamRename(t, env)
+}
+
+func TestTeamRenameSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite))
+	defer TearDown(env)
+
+	runTeamRename(t, env)
+}
+
+func runTeamRename(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	resp := POST(t, base+"/team/add", map[string]any{"team_name": "mobile", "members": []map[string]any{
+		{"user_id": "m1", "username": "nina", "is_active": true},
+		{"user_id": "m2", "username": "oleg", "is_active": true},
+	}})
+	ExpectStatus(t, resp, http.StatusCreated)
+	created := decodeTeam(t, resp, "team")
+	if created.ID == "" || created.ID == created.Name {
+		t.Fatalf("expected a generated team ID, got %q", created.ID)
+	}
+
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "rn-1", "pull_request_name": "mobile change", "author_id": "m1",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	// 1. the team is found by ID as well as by name
+	resp = GET(t, base+"/team/get?team_id="+created.ID)
+	ExpectStatus(t, resp, http.StatusOK)
+	if team := decodeTeam(t, resp, ""); team.Name != "mobile" {
+		t.Fatalf("expected mobile, got %+v", team)
+	}
+
+	// 2. renaming keeps the ID, members and pull requests
+	resp = POST(t, base+"/team/rename", map[string]any{"team_id": created.ID, "new_name": "apps"})
+	ExpectStatus(t, resp, http.StatusOK)
+	renamed := decodeTeam(t, resp, "team")
+	if renamed.ID != created.ID || renamed.Name != "apps" || len(renamed.Members) != 2 {
+		t.Fatalf("expected %s to be renamed to apps, got %+v", created.ID, renamed)
+	}
+
+	resp = GET(t, base+"/team/get?team_name=mobile")
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	resp = POST(t, base+"/pullRequest/merge", map[string]any{"pull_request_id": "rn-1"})
+	ExpectStatus(t, resp, http.StatusOK)
+	if pr := decodePR(t, resp); pr.TeamName != "apps" {
+		t.Fatalf("expected rn-1 to belong to apps, got %q", pr.TeamName)
+	}
+
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "rn-2", "pull_request_name": "apps change", "author_id": "m2", "team_name": "apps",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	// 3. taken names and unknown teams are rejected
+	resp = POST(t, base+"/team/add", map[string]any{"team_name": "web", "members": []map[string]any{}})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	resp = POST(t, base+"/team/rename", map[string]any{"team_name": "apps", "new_name": "web"})
+	ExpectErrorCode(t, resp, "TEAM_EXISTS")
+
+	resp = POST(t, base+"/team/rename", map[string]any{"team_id": "missing", "new_name": "desktop"})
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	resp = POST(t, base+"/team/rename", map[string]any{"team_name": "apps"})
+	ExpectErrorCode(t, resp, "INVALID_INPUT")
+}
+
+// decodeTeam reads a team from the response body, or from its field when
+// the team is wrapped in an object.
+func decodeTeam(t *testing.T, resp *http.Response, field string) domain.Team {
+	t.Helper()
+	defer resp.Body.Close()
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		t.Fatalf("failed to decode team: %v", err)
+	}
+	if field != "" {
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &body); err != nil {
+			t.Fatalf("failed to decode team: %v", err)
+		}
+		raw = body[field]
+	}
+
+	var team domain.Team
+	if err := json.Unmarshal(raw, &team); err != nil {
+		t.Fatalf("failed to decode team: %v", err)
+	}
+	return team
+}
@@ -0,0 +1,172 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler/dto"
+)
+
+func TestMultiTeamUsers(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TearDown(env)
+
+	runMultiTeamUsers(t, env)
+}
+
+func TestMultiTeamUsersSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite))
+	defer TearDown(env)
+
+	runMultiTeamUsers(t, env)
+}
+
+func runMultiTeamUsers(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	for _, team := range []map[string]any{
+		{"team_name": "web", "members": []map[string]any{
+			{"user_id": "m1", "username": "mira", "is_active": true},
+			{"user_id": "m2", "username": "oleg", "is_active": true},
+		}},
+		// m1 is already in web, which a new team may only take over explicitly.
+		{"team_name": "api", "on_conflict": "upsert", "members": []map[string]any{
+			{"user_id": "m1", "username": "mira", "is_active": true},
+			{"user_id": "m3", "username": "pavel", "is_active": true},
+		}},
+		{"team_name": "ops", "members": []map[string]any{
+			{"user_id": "m4", "username": "rita", "is_active": true},
+		}},
+	} {
+		resp := POST(t, base+"/team/add", team)
+		ExpectStatus(t, resp, http.StatusCreated)
+	}
+
+	// 1. an author in several teams has to pick one
+	resp := POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "mt-1", "pull_request_name": "shared", "author_id": "m1",
+	})
+	ExpectStatus(t, resp, http.StatusBadRequest)
+	ExpectErrorCode(t, resp, "TEAM_AMBIGUOUS")
+
+	// 2. the picked team must exist and include the author
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "mt-1", "pull_request_name": "shared", "author_id": "m1", "team_name": "nowhere",
+	})
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "mt-1", "pull_request_name": "shared", "author_id": "m1", "team_name": "ops",
+	})
+	ExpectStatus(t, resp, http.StatusBadRequest)
+	ExpectErrorCode(t, resp, "NOT_TEAM_MEMBER")
+
+	// 3. reviewers come from the picked team only
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "mt-1", "pull_request_name": "shared", "author_id": "m1", "team_name": "api",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	pr := decodePR(t, resp)
+	if pr.TeamName != "api" || len(pr.AssignedReviewers) != 1 || pr.AssignedReviewers[0] != "m3" {
+		t.Fatalf("expected team api with reviewer m3, got %+v", pr)
+	}
+
+	// 4. single-team authors keep working without team_name
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "mt-2", "pull_request_name": "solo", "author_id": "m2",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	if pr := decodePR(t, resp); pr.TeamName != "web" {
+		t.Fatalf("expected team web, got %q", pr.TeamName)
+	}
+
+	// 5. a reassignment stays within the pull request's team
+	resp = POST(t, base+"/pullRequest/reassign", map[string]any{
+		"pull_request_id": "mt-1", "old_reviewer_id": "m3",
+	})
+	ExpectErrorCode(t, resp, "NO_CANDIDATE")
+
+	// 6. users report every team they belong to
+	resp = POST(t, base+"/users/setIsActive", map[string]any{"user_id": "m1", "is_active": true})
+	ExpectStatus(t, resp, http.StatusOK)
+	var body struct {
+		User dto.UserResponse `json:"user"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode user: %v", err)
+	}
+	if len(body.User.Teams) != 2 || body.User.Teams[0] != "api" || body.User.Teams[1] != "web" {
+		t.Fatalf("expected teams [api web], got %v", body.User.Teams)
+	}
+	if body.User.TeamName != "" {
+		t.Fatalf("expected no single team_name for a multi-team user, got %q", body.User.TeamName)
+	}
+}
+
+func TestCreateTeamConflicts(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TearDown(env)
+
+	runCreateTeamConflicts(t, env)
+}
+
+func TestCreateTeamConflictsSQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite))
+	defer TearDown(env)
+
+	runCreateTeamConflicts(t, env)
+}
+
+func runCreateTeamConflicts(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	resp := POST(t, base+"/team/add", map[string]any{"team_name": "red", "members": []map[string]any{
+		{"user_id": "k1", "username": "kira", "is_active": true},
+	}})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	blue := map[string]any{"team_name": "blue", "members": []map[string]any{
+		{"user_id": "k1", "username": "kirill", "is_active": true},
+		{"user_id": "k2", "username": "lev", "is_active": true},
+	}}
+
+	// 1. a dry run shows the diff without writing anything
+	blue["dry_run"] = true
+	resp = POST(t, base+"/team/add", blue)
+	ExpectStatus(t, resp, http.StatusOK)
+	var plan dto.TeamPlanResponse
+	if err := json.NewDecoder(resp.Body).Decode(&plan); err != nil {
+		t.Fatalf("failed to decode plan: %v", err)
+	}
+	if len(plan.Members) != 2 || plan.Members[0].Action != "update" || plan.Members[0].OldUsername != "kira" ||
+		!plan.Members[0].Conflict || plan.Members[1].Action != "create" {
+		t.Fatalf("unexpected plan: %+v", plan.Members)
+	}
+	resp = GET(t, base+"/team/get?team_name=blue")
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	// 2. by default a member of another team is not taken over
+	delete(blue, "dry_run")
+	resp = POST(t, base+"/team/add", blue)
+	ExpectStatus(t, resp, http.StatusConflict)
+	var conflict dto.MemberConflictResponse
+	if err := json.NewDecoder(resp.Body).Decode(&conflict); err != nil {
+		t.Fatalf("failed to decode conflict: %v", err)
+	}
+	if conflict.Error.Code != "MEMBER_CONFLICT" || len(conflict.Conflicts) != 1 || conflict.Conflicts[0].UserID != "k1" {
+		t.Fatalf("unexpected conflict: %+v", conflict)
+	}
+
+	resp = POST(t, base+"/users/setIsActive", map[string]any{"user_id": "k2", "is_active": true})
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+
+	// 3. on_conflict=upsert keeps the old behaviour
+	blue["on_conflict"] = "upsert"
+	resp = POST(t, base+"/team/add", blue)
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	resp = POST(t, base+"/team/add", map[string]any{"team_name": "green", "on_conflict": "merge"})
+	ExpectErrorCode(t, resp, "INVALID_INPUT")
+}
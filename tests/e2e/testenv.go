@@ -2,10 +2,12 @@ package e2e
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -18,22 +20,143 @@ import (
 	"github.com/111zxc/pr-review-service/internal/app"
 	"github.com/111zxc/pr-review-service/internal/config"
 	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/repository/decorator"
 	pg "github.com/111zxc/pr-review-service/internal/repository/postgres"
+	"github.com/111zxc/pr-review-service/internal/repository/sqlite"
 	"github.com/111zxc/pr-review-service/internal/service"
 )
 
 type TestEnv struct {
-	Ctx       context.Context
+	Ctx     context.Context
+	Backend string
+	// DB is set for the postgres backend, SQLite for the sqlite one.
 	DB        *pgxpool.Pool
+	SQLite    *sql.DB
+	Repos     app.Repositories
 	Server    *httptest.Server
 	Container tc.Container
 }
 
-func SetupTestEnv(t *testing.T) *TestEnv {
+type Option func(*options)
+
+type options struct {
+	auth       *config.AuthConfig
+	backend    string
+	middleware decorator.Middleware
+	review     *config.ReviewConfig
+	adminToken string
+	scimToken  string
+}
+
+func WithAuth(cfg config.AuthConfig) Option {
+	return func(o *options) {
+		o.auth = &cfg
+	}
+}
+
+// WithBackend selects the storage backend; postgres is the default.
+func WithBackend(backend string) Option {
+	return func(o *options) {
+		o.backend = backend
+	}
+}
+
+// WithRepositoryMiddleware wraps every repository the services use in mw,
+// e.g. to inject faults.
+func WithRepositoryMiddleware(mw decorator.Middleware) Option {
+	return func(o *options) {
+		o.middleware = mw
+	}
+}
+
+// WithReviewConfig replaces the default reviewer assignment policy.
+func WithReviewConfig(cfg config.ReviewConfig) Option {
+	return func(o *options) {
+		o.review = &cfg
+	}
+}
+
+// WithAdminToken enables the /admin endpoints.
+func WithAdminToken(token string) Option {
+	return func(o *options) {
+		o.adminToken = token
+	}
+}
+
+// WithSCIMToken enables the /scim/v2 endpoints.
+func WithSCIMToken(token string) Option {
+	return func(o *options) {
+		o.scimToken = token
+	}
+}
+
+func SetupTestEnv(t *testing.T, opts ...Option) *TestEnv {
 	t.Helper()
 
-	ctx := context.Background()
+	o := options{backend: config.BackendPostgres}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	env := &TestEnv{Ctx: context.Background(), Backend: o.backend}
+
+	switch o.backend {
+	case config.BackendSQLite:
+		setupSQLite(t, env)
+	default:
+		setupPostgres(t, env)
+	}
+
+	repos := env.Repos
+	if o.middleware != nil {
+		repos = repos.Decorate(o.middleware)
+	}
+
+	prService := service.NewPullRequestService(repos.PR, repos.User, repos.Team, repos.PRStatus, repos.Events)
+	if o.review != nil {
+		prService.SetReviewPolicy(app.ReviewPolicy(*o.review))
+	}
+	userService := service.NewUserService(repos.User, repos.Tx, prService)
+	teamService := service.NewTeamService(repos.Team, repos.User, repos.Tx, prService)
+	statsService := service.NewStatsService(repos.Stats)
+
+	metricsCtx, stopMetrics := context.WithCancel(env.Ctx)
+	t.Cleanup(stopMetrics)
+	app.RegisterMetrics(metricsCtx, env.DB, statsService)
 
+	h := handler.New(teamService, userService, prService, statsService)
+	if o.adminToken != "" {
+		h.Admin = handler.NewAdminHandler(o.adminToken, teamService)
+	}
+	if o.scimToken != "" {
+		h.SCIM = handler.NewSCIMHandler(o.scimToken, userService, teamService)
+	}
+	switch {
+	case env.DB != nil:
+		app.RegisterReadinessChecks(h.Health, env.DB)
+	case env.SQLite != nil:
+		app.RegisterSQLiteReadinessChecks(h.Health, env.SQLite)
+	}
+
+	var middlewares []func(http.Handler) http.Handler
+	if o.auth != nil {
+		authMiddleware, err := app.NewAuthMiddleware(*o.auth, repos.User)
+		if err != nil {
+			t.Fatalf("failed to configure auth: %v", err)
+		}
+		middlewares = append(middlewares, authMiddleware)
+	}
+
+	router := app.NewRouter(h, middlewares...)
+	env.Server = httptest.NewServer(router)
+
+	return env
+}
+
+func setupPostgres(t *testing.T, env *TestEnv) {
+	t.Helper()
+
+	ctx := env.Ctx
 	port := nat.Port("5432/tcp")
 
 	req := tc.ContainerRequest{
@@ -63,6 +186,7 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 	if err != nil {
 		t.Fatalf("Failed to start postgres: %v", err)
 	}
+	env.Container = container
 
 	host, err := container.Host(ctx)
 	if err != nil {
@@ -73,11 +197,6 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 		t.Fatalf("Failed to establish mapped port: %v", err)
 	}
 
-	dbURL := fmt.Sprintf(
-		"postgres://testuser:testpass@%s:%s/testdb?sslmode=disable",
-		host, mapped.Port(),
-	)
-
 	os.Setenv("DB_HOST", host)           //nolint:errcheck
 	os.Setenv("DB_PORT", mapped.Port())  //nolint:errcheck
 	os.Setenv("DB_USER", "testuser")     //nolint:errcheck
@@ -87,39 +206,57 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 
 	os.Setenv("ENV", "test") //nolint:errcheck
 
-	cfg := config.Load()
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Fatalf("invalid test config: %v", err)
+	}
 
-	pool, err := pg.NewDB(cfg)
+	pool, err := pg.NewDB(env.Ctx, cfg)
 	if err != nil {
 		t.Fatalf("failed to init pgxpool: %v", err)
 	}
+	env.DB = pool
 
-	runMigrations(t, dbURL)
+	runMigrations(t, pool)
 
-	tx := pg.NewTxManager(pool)
+	env.Repos = app.NewPostgresRepositories(pg.NewRouter(pool, nil))
+}
 
-	userRepo := pg.NewUserRepository(pool)
-	teamRepo := pg.NewTeamRepository(pool, tx)
-	prRepo := pg.NewPullRequestRepository(pool, tx)
-	prStatusRepo := pg.NewPRStatusRepository(pool)
-	statsRepo := pg.NewStatsRepository(pool)
-	eventRepo := pg.NewEventsRepository(pool)
+func setupSQLite(t *testing.T, env *TestEnv) {
+	t.Helper()
 
-	teamService := service.NewTeamService(teamRepo, userRepo)
-	userService := service.NewUserService(userRepo)
-	prService := service.NewPullRequestService(prRepo, userRepo, teamRepo, prStatusRepo, eventRepo)
-	statsService := service.NewStatsService(statsRepo)
+	db, err := sqlite.Open(env.Ctx, filepath.Join(t.TempDir(), "e2e.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	env.SQLite = db
 
-	h := handler.New(teamService, userService, prService, statsService)
+	migrator, err := sqlite.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(env.Ctx); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
 
-	router := app.NewRouter(h)
-	server := httptest.NewServer(router)
+	env.Repos = app.NewSQLiteRepositories(db)
+}
 
-	return &TestEnv{
-		Ctx:       ctx,
-		DB:        pool,
-		Server:    server,
-		Container: container,
+// Exec runs a statement directly against the backing database.
+func (env *TestEnv) Exec(t *testing.T, query string) {
+	t.Helper()
+
+	var err error
+	switch {
+	case env.DB != nil:
+		_, err = env.DB.Exec(env.Ctx, query)
+	case env.SQLite != nil:
+		_, err = env.SQLite.ExecContext(env.Ctx, query)
+	default:
+		t.Fatalf("no database for backend %s", env.Backend)
+	}
+	if err != nil {
+		t.Fatalf("failed to exec %q: %v", query, err)
 	}
 }
 
@@ -130,6 +267,9 @@ func TearDown(env *TestEnv) {
 	if env.DB != nil {
 		env.DB.Close()
 	}
+	if env.SQLite != nil {
+		env.SQLite.Close() //nolint:errcheck
+	}
 	if env.Container != nil {
 		if err := env.Container.Terminate(env.Ctx); err != nil {
 			return
@@ -137,14 +277,16 @@ func TearDown(env *TestEnv) {
 	}
 }
 
-func runMigrations(t *testing.T, dbURL string) {
+func runMigrations(t *testing.T, pool *pgxpool.Pool) {
 	t.Helper()
 
-	cmd := exec.Command("goose", "-dir", "../../migrations", "postgres", dbURL, "up")
-	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
-
-	out, err := cmd.CombinedOutput()
+	migrator, err := pg.NewMigrator(pool)
 	if err != nil {
-		t.Fatalf("goose failed: %v\n%s", err, out)
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	defer migrator.Close() //nolint:errcheck
+
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
 	}
 }
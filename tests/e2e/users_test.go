@@ -0,0 +1,126 @@
+package e2e
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler/dto"
+)
+
+func TestUserDirectory(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TearDown(env)
+
+	runUserDirectory(t, env)
+}
+
+func TestUserDirectorySQLite(t *testing.T) {
+	env := SetupTestEnv(t, WithBackend(config.BackendSQLite))
+	defer TearDown(env)
+
+	runUserDirectory(t, env)
+}
+
+func runUserDirectory(t *testing.T, env *TestEnv) {
+	base := env.Server.URL
+
+	resp := POST(t, base+"/team/add", map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "d1", "username": "alice", "is_active": true, "role": "lead"},
+			{"user_id": "d2", "username": "albert", "is_active": true},
+			{"user_id": "d3", "username": "bob", "is_active": false},
+		},
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	resp = POST(t, base+"/team/add", map[string]any{
+		"team_name": "frontend",
+		"members": []map[string]any{
+			{"user_id": "d1", "username": "alice", "is_active": true},
+			{"user_id": "d4", "username": "carol", "is_active": true},
+		},
+		"on_conflict": "upsert",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+	resp = POST(t, base+"/pullRequest/create", map[string]any{
+		"pull_request_id": "pr-d1", "pull_request_name": "Directory", "author_id": "d2",
+	})
+	ExpectStatus(t, resp, http.StatusCreated)
+
+	// 1. a user shows their memberships and open reviews
+	resp = GET(t, base+"/users/get?user_id=d1")
+	ExpectStatus(t, resp, http.StatusOK)
+	var got struct {
+		User dto.UserDetailsResponse `json:"user"`
+	}
+	decodeJSON(t, resp, &got)
+	want := []dto.UserMembershipResponse{{TeamName: "backend", Role: "lead"}, {TeamName: "frontend", Role: "member"}}
+	if !slices.Equal(got.User.Memberships, want) {
+		t.Fatalf("expected memberships %+v, got %+v", want, got.User.Memberships)
+	}
+	if got.User.OpenReviews != 1 {
+		t.Fatalf("expected d1 to review pr-d1, got %+v", got.User)
+	}
+
+	resp = GET(t, base+"/users/get?user_id=missing")
+	ExpectStatus(t, resp, http.StatusNotFound)
+	ExpectErrorCode(t, resp, "NOT_FOUND")
+	resp = GET(t, base+"/users/get")
+	ExpectStatus(t, resp, http.StatusBadRequest)
+
+	// 2. filters combine and the total ignores paging
+	for query, ids := range map[string][]string{
+		"":                                      {"d1", "d2", "d3", "d4"},
+		"?team_name=backend":                    {"d1", "d2", "d3"},
+		"?team_name=backend&is_active=true":     {"d1", "d2"},
+		"?username_prefix=al":                   {"d1", "d2"},
+		"?team_name=frontend&username_prefix=c": {"d4"},
+		"?team_name=missing":                    {},
+	} {
+		list := listUsers(t, base, query)
+		if got := userIDs(list); !slices.Equal(got, ids) || list.Total != len(ids) {
+			t.Fatalf("%q: expected %v, got %v (total %d)", query, ids, got, list.Total)
+		}
+	}
+
+	list := listUsers(t, base, "?limit=2&offset=1")
+	if got := userIDs(list); list.Total != 4 || !slices.Equal(got, []string{"d2", "d3"}) {
+		t.Fatalf("unexpected page: %v (total %d)", got, list.Total)
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=1000", "?offset=-1", "?is_active=maybe"} {
+		resp = GET(t, base+"/users/list"+query)
+		ExpectStatus(t, resp, http.StatusBadRequest)
+		ExpectErrorCode(t, resp, "INVALID_INPUT")
+	}
+}
+
+func listUsers(t *testing.T, base, query string) dto.UserListResponse {
+	t.Helper()
+
+	resp := GET(t, base+"/users/list"+query)
+	ExpectStatus(t, resp, http.StatusOK)
+	var list dto.UserListResponse
+	decodeJSON(t, resp, &list)
+	return list
+}
+
+func userIDs(list dto.UserListResponse) []string {
+	ids := make([]string, 0, len(list.Users))
+	for _, u := range list.Users {
+		ids = append(ids, u.UserID)
+	}
+	return ids
+}
+
+func decodeJSON(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+package unit
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/auth"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/repository/mocks"
+)
+
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return &testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+func (k *testKeys) jwks(t *testing.T) []byte {
+	t.Helper()
+
+	data, err := auth.EncodeJWKS(map[string]crypto.PublicKey{
+		"rsa-1": &k.rsa.PublicKey,
+		"ec-1":  &k.ec.PublicKey,
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims(sub string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": sub,
+		"iss": "https://idp.test",
+		"aud": "pr-review-service",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func newTestVerifier(keys *auth.KeySet) *auth.Verifier {
+	return auth.NewVerifier(keys, auth.VerifierConfig{
+		Issuer:     "https://idp.test",
+		Audience:   "pr-review-service",
+		UserClaim:  "sub",
+		TeamsClaim: "groups",
+	})
+}
+
+func writeJWKSFile(t *testing.T, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestVerifier_RS256AndES256(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := newTestVerifier(auth.NewFileKeySet(writeJWKSFile(t, keys.jwks(t)), time.Minute))
+
+	claims, err := verifier.Verify(context.Background(),
+		signToken(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims("u1")))
+	assert.NoError(t, err)
+	assert.Equal(t, "u1", claims.Subject)
+
+	claims, err = verifier.Verify(context.Background(),
+		signToken(t, jwt.SigningMethodES256, "ec-1", keys.ec, validClaims("u2")))
+	assert.NoError(t, err)
+	assert.Equal(t, "u2", claims.Subject)
+}
+
+func TestVerifier_RejectsInvalidTokens(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := newTestVerifier(auth.NewFileKeySet(writeJWKSFile(t, keys.jwks(t)), time.Minute))
+
+	expired := validClaims("u1")
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	wrongAudience := validClaims("u1")
+	wrongAudience["aud"] = "someone-else"
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tokens := map[string]string{
+		"expired":        signToken(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, expired),
+		"wrong audience": signToken(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, wrongAudience),
+		"wrong key":      signToken(t, jwt.SigningMethodRS256, "rsa-1", otherKey, validClaims("u1")),
+		"unknown kid":    signToken(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa, validClaims("u1")),
+		"hmac":           signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), validClaims("u1")),
+		"garbage":        "not-a-token",
+	}
+
+	for name, token := range tokens {
+		_, err := verifier.Verify(context.Background(), token)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken, name)
+	}
+}
+
+func TestKeySet_PicksUpRotatedKeys(t *testing.T) {
+	oldKeys := newTestKeys(t)
+	newKeys := newTestKeys(t)
+
+	var current atomic.Value
+	current.Store(oldKeys.jwks(t))
+	var fetches atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(current.Load().([]byte)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	keySet := auth.NewURLKeySet(server.URL, time.Hour)
+	keySet.SetMinRefreshInterval(0)
+	verifier := newTestVerifier(keySet)
+
+	_, err := verifier.Verify(context.Background(),
+		signToken(t, jwt.SigningMethodRS256, "rsa-1", oldKeys.rsa, validClaims("u1")))
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(context.Background(),
+		signToken(t, jwt.SigningMethodRS256, "rsa-1", oldKeys.rsa, validClaims("u1")))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load(), "keys should be served from cache")
+
+	rotated, err := auth.EncodeJWKS(map[string]crypto.PublicKey{"rsa-2": &newKeys.rsa.PublicKey})
+	require.NoError(t, err)
+	current.Store(rotated)
+
+	_, err = verifier.Verify(context.Background(),
+		signToken(t, jwt.SigningMethodRS256, "rsa-2", newKeys.rsa, validClaims("u1")))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load(), "unknown kid should trigger a reload")
+
+	_, err = verifier.Verify(context.Background(),
+		signToken(t, jwt.SigningMethodRS256, "rsa-1", oldKeys.rsa, validClaims("u1")))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestAuthenticator_MapsClaimsToUser(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := newTestVerifier(auth.NewFileKeySet(writeJWKSFile(t, keys.jwks(t)), time.Minute))
+	userRepo := new(mocks.UserRepository)
+	authenticator := auth.NewAuthenticator(verifier, userRepo)
+
+	userRepo.On("GetByID", mock.Anything, "u1").Return(&domain.User{ID: "u1", Username: "Alice", IsActive: true, Teams: []string{"backend"}}, nil)
+	userRepo.On("GetByID", mock.Anything, "u2").Return(&domain.User{ID: "u2", Username: "Bob", IsActive: false, Teams: []string{"backend"}}, nil)
+	userRepo.On("GetByID", mock.Anything, "ghost").Return(nil, domain.ErrUserNotFound)
+	userRepo.On("GetByID", mock.Anything, "erased").Return(nil, fmt.Errorf("get user: %w", domain.ErrUserNotFound))
+
+	principal, err := authenticator.Authenticate(context.Background(),
+		signToken(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims("u1")))
+	require.NoError(t, err)
+	assert.Equal(t, "u1", principal.UserID)
+	assert.Equal(t, []string{"backend"}, principal.Teams)
+	assert.True(t, principal.HasRole("backend", domain.RoleMember, domain.RoleMaintainer))
+	assert.False(t, principal.HasRole("backend", domain.RoleLead))
+
+	narrowed := validClaims("u1")
+	narrowed["groups"] = []any{"frontend"}
+	principal, err = authenticator.Authenticate(context.Background(),
+		signToken(t, jwt.SigningMethodES256, "ec-1", keys.ec, narrowed))
+	require.NoError(t, err)
+	assert.Empty(t, principal.Teams)
+	assert.Empty(t, principal.Roles)
+
+	_, err = authenticator.Authenticate(context.Background(),
+		signToken(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims("u2")))
+	assert.ErrorIs(t, err, auth.ErrInactiveUser)
+
+	_, err = authenticator.Authenticate(context.Background(),
+		signToken(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims("ghost")))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	_, err = authenticator.Authenticate(context.Background(),
+		signToken(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims("erased")))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken, "wrapped repository errors are recognised")
+}
@@ -0,0 +1,154 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/repository/cache"
+	"github.com/111zxc/pr-review-service/internal/repository/mocks"
+	"github.com/111zxc/pr-review-service/tests/contract"
+)
+
+type recordingPublisher struct {
+	payloads []string
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, payload string) error {
+	p.payloads = append(p.payloads, payload)
+	return nil
+}
+
+var testCacheConfig = cache.Config{UserTTL: time.Minute, TeamTTL: time.Minute}
+
+func TestCachedRepositoryContract(t *testing.T) {
+	contract.Run(t, func(*testing.T) app.Repositories {
+		repos := app.NewMemoryRepositories()
+		c := cache.New(testCacheConfig, nil, "test")
+		repos.User = cache.NewUserRepository(repos.User, c)
+		repos.Team = cache.NewTeamRepository(repos.Team, c)
+		return repos
+	})
+}
+
+func TestUserCache_ServesRepeatedLookups(t *testing.T) {
+	next := new(mocks.UserRepository)
+	next.On("GetByID", mock.Anything, "u1").Return(CreateTestUser(), nil).Once()
+	next.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{CreateTestUser()}, nil).Once()
+
+	repo := cache.NewUserRepository(next, cache.New(testCacheConfig, nil, "test"))
+	ctx := context.Background()
+
+	for range 3 {
+		user, err := repo.GetByID(ctx, "u1")
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", user.Username)
+
+		users, err := repo.GetByTeam(ctx, "backend")
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+	}
+
+	next.AssertExpectations(t)
+}
+
+func TestUserCache_UpdateInvalidatesAndPublishes(t *testing.T) {
+	next := new(mocks.UserRepository)
+	updated := CreateTestUser()
+	updated.IsActive = false
+	next.On("GetByID", mock.Anything, "u1").Return(CreateTestUser(), nil).Once()
+	next.On("Update", mock.Anything, updated).Return(nil).Once()
+	next.On("GetByID", mock.Anything, "u1").Return(updated, nil).Once()
+
+	publisher := &recordingPublisher{}
+	repo := cache.NewUserRepository(next, cache.New(testCacheConfig, publisher, "test"))
+	ctx := context.Background()
+
+	_, err := repo.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	require.NoError(t, repo.Update(ctx, updated))
+
+	user, err := repo.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+	assert.Len(t, publisher.payloads, 1)
+	next.AssertExpectations(t)
+}
+
+func TestUserCache_EntriesExpire(t *testing.T) {
+	next := new(mocks.UserRepository)
+	next.On("GetByID", mock.Anything, "u1").Return(CreateTestUser(), nil).Twice()
+
+	c := cache.New(cache.Config{UserTTL: 20 * time.Millisecond, TeamTTL: time.Minute}, nil, "test")
+	repo := cache.NewUserRepository(next, c)
+
+	_, err := repo.GetByID(context.Background(), "u1")
+	require.NoError(t, err)
+	time.Sleep(40 * time.Millisecond)
+	_, err = repo.GetByID(context.Background(), "u1")
+	require.NoError(t, err)
+
+	next.AssertExpectations(t)
+}
+
+func TestCache_RemoteInvalidation(t *testing.T) {
+	publisher := &recordingPublisher{}
+	writerCache := cache.New(testCacheConfig, publisher, "writer")
+	readerCache := cache.New(testCacheConfig, nil, "reader")
+
+	writerNext := new(mocks.UserRepository)
+	writerNext.On("Update", mock.Anything, mock.Anything).Return(nil)
+	readerNext := new(mocks.UserRepository)
+	readerNext.On("GetByID", mock.Anything, "u1").Return(CreateTestUser(), nil).Twice()
+
+	writer := cache.NewUserRepository(writerNext, writerCache)
+	reader := cache.NewUserRepository(readerNext, readerCache)
+	ctx := context.Background()
+
+	_, err := reader.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	require.NoError(t, writer.Update(ctx, CreateTestUser()))
+	require.Len(t, publisher.payloads, 1)
+
+	// An instance ignores its own broadcasts.
+	writerCache.HandleRemote(publisher.payloads[0])
+
+	readerCache.HandleRemote(publisher.payloads[0])
+	_, err = reader.GetByID(ctx, "u1")
+	require.NoError(t, err)
+
+	readerNext.AssertExpectations(t)
+}
+
+func TestTeamCache_CreateInvalidatesMembers(t *testing.T) {
+	c := cache.New(testCacheConfig, nil, "test")
+	repos := app.NewMemoryRepositories()
+	users := cache.NewUserRepository(repos.User, c)
+	teams := cache.NewTeamRepository(repos.Team, c)
+	ctx := context.Background()
+
+	require.NoError(t, users.Create(ctx, &domain.User{ID: "u1", Username: "Alice", IsActive: true}))
+	user, err := users.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Empty(t, user.Teams)
+
+	exists, err := teams.Exists(ctx, "backend")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, teams.Create(ctx, &domain.Team{Name: "backend", Members: []domain.TeamMember{{UserID: "u1"}}}))
+
+	user, err = users.GetByID(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend"}, user.Teams)
+
+	exists, err = teams.Exists(ctx, "backend")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/internal/config"
+)
+
+func TestConnectDB_GivesUpAtConnectTimeout(t *testing.T) {
+	// A server that accepts connections but never answers the handshake.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	cfg := &config.Config{DB: config.DBConfig{
+		Host:           "127.0.0.1",
+		Port:           ln.Addr().(*net.TCPAddr).Port,
+		User:           "pr_user",
+		Name:           "pr_review",
+		SSLMode:        "disable",
+		MaxConns:       1,
+		ConnectTimeout: 300 * time.Millisecond,
+	}}
+
+	start := time.Now()
+	pool, err := app.ConnectDB(context.Background(), cfg)
+
+	assert.Error(t, err)
+	assert.Nil(t, pool)
+	assert.Less(t, time.Since(start), 3*time.Second, "the attempt must not outlive the connect timeout")
+}
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/metrics"
+	"github.com/111zxc/pr-review-service/internal/repository/decorator"
+	"github.com/111zxc/pr-review-service/internal/repository/mocks"
+	"github.com/111zxc/pr-review-service/tests/contract"
+)
+
+func TestDecoratedRepositoryContract(t *testing.T) {
+	contract.Run(t, func(*testing.T) app.Repositories {
+		return app.NewMemoryRepositories().Decorate(decorator.Instrument())
+	})
+}
+
+func TestDecorator_ChainOrderAndOperationNames(t *testing.T) {
+	var calls []string
+	record := func(name string) decorator.Middleware {
+		return func(ctx context.Context, op string, next func(context.Context) error) error {
+			calls = append(calls, name+":"+op)
+			return next(ctx)
+		}
+	}
+
+	next := new(mocks.EventsRepository)
+	next.On("CreateEvent", mock.Anything, mock.Anything).Return(nil).Once()
+	repo := decorator.NewEventsRepository(next, decorator.Chain(record("outer"), record("inner")))
+
+	require.NoError(t, repo.CreateEvent(context.Background(), &domain.Event{}))
+	assert.Equal(t, []string{"outer:Events.CreateEvent", "inner:Events.CreateEvent"}, calls)
+	next.AssertExpectations(t)
+}
+
+func TestInstrument_RecordsLatencyAndErrorKinds(t *testing.T) {
+	next := new(mocks.UserRepository)
+	next.On("GetByID", mock.Anything, "missing").Return(nil, domain.ErrUserNotFound)
+	next.On("GetByID", mock.Anything, "outsider").Return(nil, fmt.Errorf("get user: %w", domain.ErrNotTeamMember))
+	next.On("GetByID", mock.Anything, "broken").Return(nil, errors.New("connection reset"))
+	repo := decorator.NewUserRepository(next, decorator.Instrument())
+
+	calls := metrics.RepositoryOperationDuration.Count("User.GetByID")
+	domainErrs := metrics.RepositoryOperationErrors.Value("User.GetByID", "domain")
+	internalErrs := metrics.RepositoryOperationErrors.Value("User.GetByID", "internal")
+
+	_, err := repo.GetByID(context.Background(), "missing")
+	assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	_, err = repo.GetByID(context.Background(), "outsider")
+	assert.ErrorIs(t, err, domain.ErrNotTeamMember)
+	_, err = repo.GetByID(context.Background(), "broken")
+	assert.Error(t, err)
+
+	assert.Equal(t, calls+3, metrics.RepositoryOperationDuration.Count("User.GetByID"))
+	assert.Equal(t, domainErrs+2, metrics.RepositoryOperationErrors.Value("User.GetByID", "domain"))
+	assert.Equal(t, internalErrs+1, metrics.RepositoryOperationErrors.Value("User.GetByID", "internal"))
+}
+
+func TestFaults_FailsMatchingCallsAtConfiguredRate(t *testing.T) {
+	next := new(mocks.EventsRepository)
+	next.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+	next.On("GetEventCountsByType", mock.Anything).Return(map[domain.EventType]int{}, nil)
+
+	faults := decorator.NewFaults(42)
+	faults.Set(decorator.FaultRule{Operation: "Events.CreateEvent", ErrorRate: 0.1})
+	repo := decorator.NewEventsRepository(next, faults.Middleware())
+	ctx := context.Background()
+
+	failed := 0
+	for range 1000 {
+		if err := repo.CreateEvent(ctx, &domain.Event{}); err != nil {
+			assert.ErrorIs(t, err, decorator.ErrInjectedFault)
+			failed++
+		}
+		_, err := repo.GetEventCountsByType(ctx)
+		require.NoError(t, err)
+	}
+	assert.InDelta(t, 100, failed, 40)
+
+	faults.Set()
+	assert.NoError(t, repo.CreateEvent(ctx, &domain.Event{}))
+}
+
+func TestFaults_CustomErrorAndDelay(t *testing.T) {
+	next := new(mocks.PullRequestRepository)
+	errDown := errors.New("primary down")
+
+	faults := decorator.NewFaults(1)
+	faults.Set(decorator.FaultRule{Operation: "PR.*", ErrorRate: 1, Err: errDown})
+	repo := decorator.NewPullRequestRepository(next, faults.Middleware())
+
+	_, err := repo.Exists(context.Background(), "pr1")
+	assert.ErrorIs(t, err, errDown)
+	next.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+
+	faults.Set(decorator.FaultRule{Operation: "*", Delay: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = repo.Exists(ctx, "pr1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
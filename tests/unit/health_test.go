@@ -0,0 +1,75 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/handler"
+)
+
+func readiness(t *testing.T, h *handler.HealthHandler) (int, handler.ReadinessResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.Ready(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	var resp handler.ReadinessResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func TestHealth_ReadyWhenAllChecksPass(t *testing.T) {
+	h := handler.NewHealthHandler()
+	h.AddCheck("database", func(context.Context) (map[string]any, error) { return nil, nil })
+	h.AddCheck("pool", func(context.Context) (map[string]any, error) {
+		return map[string]any{"saturation": 0.5}, nil
+	})
+
+	code, resp := readiness(t, h)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", resp.Status)
+	assert.Equal(t, "ok", resp.Checks["database"].Status)
+	assert.Equal(t, 0.5, resp.Checks["pool"].Details["saturation"])
+}
+
+func TestHealth_NotReadyWhenACheckFails(t *testing.T) {
+	h := handler.NewHealthHandler()
+	h.AddCheck("database", func(context.Context) (map[string]any, error) {
+		return nil, errors.New("connection refused")
+	})
+	h.AddCheck("migrations", func(context.Context) (map[string]any, error) {
+		return map[string]any{"applied": 3, "expected": 4}, errors.New("schema version 3 doesn't match expected 4")
+	})
+	h.AddCheck("pool", func(context.Context) (map[string]any, error) { return nil, nil })
+
+	code, resp := readiness(t, h)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "fail", resp.Status)
+	assert.Equal(t, "fail", resp.Checks["database"].Status)
+	assert.Equal(t, "connection refused", resp.Checks["database"].Error)
+	assert.Equal(t, "fail", resp.Checks["migrations"].Status)
+	assert.Equal(t, float64(3), resp.Checks["migrations"].Details["applied"])
+	assert.Equal(t, "ok", resp.Checks["pool"].Status)
+}
+
+func TestHealth_LiveIgnoresDependencies(t *testing.T) {
+	h := handler.NewHealthHandler()
+	h.AddCheck("database", func(context.Context) (map[string]any, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	rec := httptest.NewRecorder()
+	h.Live(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"ok"`)
+}
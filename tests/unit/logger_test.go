@@ -0,0 +1,137 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/logger"
+)
+
+func initFileLogger(t *testing.T, cfg logger.Config) string {
+	t.Helper()
+
+	cfg.File = filepath.Join(t.TempDir(), "app.log")
+	cfg.Format = "json"
+	if cfg.FileMaxSizeMB == 0 {
+		cfg.FileMaxSizeMB = 10
+	}
+	require.NoError(t, logger.Init(cfg))
+	t.Cleanup(func() { _ = logger.InitWithEnv("test") })
+
+	return cfg.File
+}
+
+func readLog(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestLogger_ComponentLevels(t *testing.T) {
+	path := initFileLogger(t, logger.Config{
+		Level:      "info",
+		Components: map[string]string{"repository": "debug", "service/noisy": "error"},
+	})
+	ctx := context.Background()
+
+	logger.For(ctx, "repository/postgres").Debug("repository debug")
+	logger.For(ctx, "service").Debug("service debug")
+	logger.For(ctx, "service").Info("service info")
+	logger.For(ctx, "service/noisy").Warn("noisy warn")
+
+	out := readLog(t, path)
+	assert.Contains(t, out, "repository debug")
+	assert.NotContains(t, out, "service debug")
+	assert.Contains(t, out, "service info")
+	assert.NotContains(t, out, "noisy warn")
+}
+
+func TestLogger_RuntimeLevelChanges(t *testing.T) {
+	path := initFileLogger(t, logger.Config{Level: "warn"})
+	ctx := context.Background()
+
+	logger.For(ctx, "service").Info("before")
+
+	require.NoError(t, logger.SetLevel("info"))
+	logger.For(ctx, "service").Info("after set level")
+
+	assert.Equal(t, "debug", logger.ToggleDebug())
+	logger.For(ctx, "service").Debug("while toggled")
+
+	assert.Equal(t, "info", logger.ToggleDebug())
+	logger.For(ctx, "service").Debug("after toggle back")
+
+	require.NoError(t, logger.SetComponentLevel("service", "error"))
+	logger.For(ctx, "service").Info("component silenced")
+	require.NoError(t, logger.SetComponentLevel("service", ""))
+	logger.For(ctx, "service").Info("override removed")
+
+	assert.Error(t, logger.SetLevel("verbose"))
+
+	out := readLog(t, path)
+	assert.NotContains(t, out, "before")
+	assert.Contains(t, out, "after set level")
+	assert.Contains(t, out, "while toggled")
+	assert.NotContains(t, out, "after toggle back")
+	assert.NotContains(t, out, "component silenced")
+	assert.Contains(t, out, "override removed")
+}
+
+func TestLogger_FileRotation(t *testing.T) {
+	path := initFileLogger(t, logger.Config{Level: "info", FileMaxSizeMB: 1, FileMaxBackups: 2})
+
+	padding := strings.Repeat("x", 1024)
+	for range 3000 {
+		logger.Info("filler", "padding", padding)
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		require.NoError(t, err, name)
+		assert.LessOrEqual(t, info.Size(), int64(1<<20), name)
+	}
+	_, err := os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAdminHandler_LogLevel(t *testing.T) {
+	initFileLogger(t, logger.Config{Level: "info"})
+
+	call := func(h *handler.AdminHandler, method, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/log-level", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set(handler.AdminTokenHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		h.LogLevel(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotFound, call(handler.NewAdminHandler("", nil), http.MethodGet, "anything", "").Code)
+
+	h := handler.NewAdminHandler("s3cret", nil)
+	assert.Equal(t, http.StatusUnauthorized, call(h, http.MethodGet, "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call(h, http.MethodGet, "wrong", "").Code)
+
+	rec := call(h, http.MethodPut, "s3cret", `{"level":"debug"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"debug","components":{}}`, rec.Body.String())
+
+	rec = call(h, http.MethodPut, "s3cret", `{"component":"repository/postgres","level":"warn"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"debug","components":{"repository/postgres":"warn"}}`, rec.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, call(h, http.MethodPut, "s3cret", `{"level":"loud"}`).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, call(h, http.MethodDelete, "s3cret", "").Code)
+}
@@ -0,0 +1,14 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/tests/contract"
+)
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	contract.Run(t, func(*testing.T) app.Repositories {
+		return app.NewMemoryRepositories()
+	})
+}
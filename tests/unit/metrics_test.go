@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/metrics"
+	"github.com/111zxc/pr-review-service/internal/repository/mocks"
+	"github.com/111zxc/pr-review-service/internal/service"
+)
+
+func scrape(t *testing.T) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetricsMiddleware_RecordsRouteAndStatus(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/team/get", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	routeOf := func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		return pattern
+	}
+	h := handler.NewMetricsMiddleware(routeOf)(mux)
+
+	before := metrics.HTTPRequestDuration.Count("/team/get", http.MethodGet, "404")
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/team/get?team_name=x", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope/123", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("BREW", "/team/get", nil))
+
+	assert.Equal(t, before+1, metrics.HTTPRequestDuration.Count("/team/get", http.MethodGet, "404"))
+	assert.Equal(t, uint64(1), metrics.HTTPRequestDuration.Count("unmatched", http.MethodGet, "404"))
+	assert.Equal(t, uint64(1), metrics.HTTPRequestDuration.Count("/team/get", "other", "404"), "unknown methods share one label")
+	assert.Zero(t, metrics.HTTPRequestDuration.Count("/team/get", "BREW", "404"))
+
+	body := scrape(t)
+	assert.Contains(t, body, "# TYPE http_request_duration_seconds histogram")
+	assert.Contains(t, body, `http_request_duration_seconds_bucket{route="/team/get",method="GET",status="404",le="+Inf"}`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{route="unmatched",method="GET",status="404"} 1`)
+}
+
+func TestMetrics_FuncCollectorsAndEscaping(t *testing.T) {
+	metrics.RegisterGaugeFunc("test_open_reviews", "Open reviews.", []string{"team"},
+		func(emit func(float64, ...string)) {
+			emit(3, `back"end`)
+		})
+
+	body := scrape(t)
+	assert.Contains(t, body, "# TYPE test_open_reviews gauge")
+	assert.Contains(t, body, `test_open_reviews{team="back\"end"} 3`)
+
+	// re-registering replaces the collector instead of panicking
+	metrics.RegisterGaugeFunc("test_open_reviews", "Open reviews.", []string{"team"},
+		func(emit func(float64, ...string)) {
+			emit(5, "backend")
+		})
+
+	body = scrape(t)
+	assert.Contains(t, body, `test_open_reviews{team="backend"} 5`)
+	assert.NotContains(t, body, `back\"end`)
+}
+
+func TestMetrics_OpenReviewsAreNotQueriedPerScrape(t *testing.T) {
+	statsRepo := new(mocks.StatsRepository)
+	statsRepo.On("GetOpenReviewsByTeam", mock.Anything).Return(map[string]int{"backend": 4}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	app.RegisterMetrics(ctx, nil, service.NewStatsService(statsRepo))
+
+	assert.Eventually(t, func() bool {
+		return strings.Contains(scrape(t), `open_reviews{team="backend"} 4`)
+	}, time.Second, 10*time.Millisecond)
+	scrape(t)
+	scrape(t)
+
+	statsRepo.AssertNumberOfCalls(t, "GetOpenReviewsByTeam", 1)
+}
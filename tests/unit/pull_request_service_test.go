@@ -1,14 +1,17 @@
 package unit
 
 import (
+	"context"
 	"slices"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/metrics"
 	"github.com/111zxc/pr-review-service/internal/repository/mocks"
 	"github.com/111zxc/pr-review-service/internal/service"
 )
@@ -59,21 +62,24 @@ func TestPullRequestService_CreatePullRequest_Success(t *testing.T) {
 	author := CreateTestUser()
 
 	teamUsers := []*domain.User{
-		{ID: "u2", Username: "Bob", IsActive: true, TeamName: "backend"},
-		{ID: "u3", Username: "Charlie", IsActive: true, TeamName: "backend"},
-		{ID: "u4", Username: "David", IsActive: true, TeamName: "backend"},
+		{ID: "u2", Username: "Bob", IsActive: true, Teams: []string{"backend"}},
+		{ID: "u3", Username: "Charlie", IsActive: true, Teams: []string{"backend"}},
+		{ID: "u4", Username: "David", IsActive: true, Teams: []string{"backend"}},
 	}
 
-	suite.mockPRRepo.On("Exists", "pr-1").Return(false, nil)
-	suite.mockUserRepo.On("GetByID", "u1").Return(author, nil)
-	suite.mockUserRepo.On("GetByTeam", "backend").Return(teamUsers, nil)
-	suite.mockPRRepo.On("Create", pr).Return(nil)
-	suite.mockEventsRepo.On("CreateEvent", mock.AnythingOfType("*domain.Event")).Return(nil)
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(author, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return(teamUsers, nil)
+	suite.mockPRRepo.On("Create", mock.Anything, pr).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.AnythingOfType("*domain.Event")).Return(nil)
 
-	err := suite.prService.CreatePullRequest(pr)
+	createdBefore := metrics.PullRequestsCreated.Value()
+
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
 
 	assert.NoError(t, err)
 	assert.Len(t, pr.AssignedReviewers, 2)
+	assert.Equal(t, createdBefore+1, metrics.PullRequestsCreated.Value())
 
 	assert.NotContains(t, pr.AssignedReviewers, "u1")
 
@@ -86,9 +92,9 @@ func TestPullRequestService_CreatePullRequest_AlreadyExists(t *testing.T) {
 	suite := NewPRServiceTestSuite()
 	pr := CreateTestPullRequest()
 
-	suite.mockPRRepo.On("Exists", "pr-1").Return(true, nil)
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(true, nil)
 
-	err := suite.prService.CreatePullRequest(pr)
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
 
 	assert.Error(t, err)
 	assert.Equal(t, domain.ErrPullRequestExists, err)
@@ -99,10 +105,10 @@ func TestPullRequestService_CreatePullRequest_AuthorNotFound(t *testing.T) {
 	suite := NewPRServiceTestSuite()
 	pr := CreateTestPullRequest()
 
-	suite.mockPRRepo.On("Exists", "pr-1").Return(false, nil)
-	suite.mockUserRepo.On("GetByID", "u1").Return(nil, domain.ErrUserNotFound)
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(nil, domain.ErrUserNotFound)
 
-	err := suite.prService.CreatePullRequest(pr)
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
 
 	assert.Error(t, err)
 	assert.Equal(t, domain.ErrUserNotFound, err)
@@ -120,11 +126,10 @@ func TestPullRequestService_CreatePullRequest_TeamNotFound(t *testing.T) {
 		IsActive: true,
 	}
 
-	suite.mockPRRepo.On("Exists", "pr-1").Return(false, nil)
-	suite.mockUserRepo.On("GetByID", "u1").Return(author, nil)
-	suite.mockTeamRepo.On("GetByName", mock.Anything).Return(nil, domain.ErrTeamNotFound)
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(author, nil)
 
-	err := suite.prService.CreatePullRequest(pr)
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
 
 	assert.Error(t, err)
 	assert.Equal(t, domain.ErrTeamNotFound, err)
@@ -143,13 +148,13 @@ func TestPullRequestService_MergePullRequest_Success(t *testing.T) {
 		AssignedReviewers: []string{"u2", "u3"},
 	}
 
-	suite.mockPRRepo.On("GetByID", "pr-1").Return(pr, nil)
-	suite.mockPRRepo.On("Update", mock.MatchedBy(func(p *domain.PullRequest) bool {
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *domain.PullRequest) bool {
 		return p.Status == domain.PRStatusMerged && p.MergedAt != nil
 	})).Return(nil)
-	suite.mockEventsRepo.On("CreateEvent", mock.AnythingOfType("*domain.Event")).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.AnythingOfType("*domain.Event")).Return(nil)
 
-	result, err := suite.prService.MergePullRequest("pr-1")
+	result, err := suite.prService.MergePullRequest(context.Background(), "pr-1")
 
 	assert.NoError(t, err)
 	assert.Equal(t, domain.PRStatusMerged, result.Status)
@@ -170,9 +175,9 @@ func TestPullRequestService_MergePullRequest_AlreadyMerged(t *testing.T) {
 		MergedAt:          &mergedAt,
 	}
 
-	suite.mockPRRepo.On("GetByID", "pr-1").Return(pr, nil)
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
 
-	result, err := suite.prService.MergePullRequest("pr-1")
+	result, err := suite.prService.MergePullRequest(context.Background(), "pr-1")
 
 	assert.NoError(t, err)
 	assert.Equal(t, domain.PRStatusMerged, result.Status)
@@ -184,9 +189,9 @@ func TestPullRequestService_MergePullRequest_AlreadyMerged(t *testing.T) {
 func TestPullRequestService_MergePullRequest_NotFound(t *testing.T) {
 	suite := NewPRServiceTestSuite()
 
-	suite.mockPRRepo.On("GetByID", "pr-1").Return(nil, domain.ErrPullRequestNotFound)
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(nil, domain.ErrPullRequestNotFound)
 
-	result, err := suite.prService.MergePullRequest("pr-1")
+	result, err := suite.prService.MergePullRequest(context.Background(), "pr-1")
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -208,24 +213,24 @@ func TestPullRequestService_ReassignReviewer_Success(t *testing.T) {
 	oldReviewer := &domain.User{
 		ID:       "u2",
 		Username: "Bob",
-		TeamName: "backend",
+		Teams:    []string{"backend"},
 		IsActive: true,
 	}
 
 	teamUsers := []*domain.User{
-		{ID: "u3", Username: "Ivan", IsActive: true, TeamName: "backend"},
-		{ID: "u4", Username: "Stepan", IsActive: true, TeamName: "backend"},
+		{ID: "u3", Username: "Ivan", IsActive: true, Teams: []string{"backend"}},
+		{ID: "u4", Username: "Stepan", IsActive: true, Teams: []string{"backend"}},
 	}
 
-	suite.mockPRRepo.On("GetByID", "pr-1").Return(pr, nil)
-	suite.mockUserRepo.On("GetByID", "u2").Return(oldReviewer, nil)
-	suite.mockUserRepo.On("GetByTeam", "backend").Return(teamUsers, nil)
-	suite.mockPRRepo.On("Update", mock.MatchedBy(func(p *domain.PullRequest) bool {
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").Return(oldReviewer, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return(teamUsers, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *domain.PullRequest) bool {
 		return slices.Contains(p.AssignedReviewers, "u4") && !slices.Contains(p.AssignedReviewers, "u2")
 	})).Return(nil)
-	suite.mockEventsRepo.On("CreateEvent", mock.AnythingOfType("*domain.Event")).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.AnythingOfType("*domain.Event")).Return(nil)
 
-	result, newReviewer, err := suite.prService.ReassignReviewer("pr-1", "u2")
+	result, newReviewer, err := suite.prService.ReassignReviewer(context.Background(), "pr-1", "u2")
 
 	assert.NoError(t, err)
 	assert.Equal(t, "u4", newReviewer)
@@ -246,9 +251,9 @@ func TestPullRequestService_ReassignReviewer_MergedPR(t *testing.T) {
 		AssignedReviewers: []string{"u2", "u3"},
 	}
 
-	suite.mockPRRepo.On("GetByID", "pr-1").Return(pr, nil)
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
 
-	result, newReviewer, err := suite.prService.ReassignReviewer("pr-1", "u2")
+	result, newReviewer, err := suite.prService.ReassignReviewer(context.Background(), "pr-1", "u2")
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -268,9 +273,9 @@ func TestPullRequestService_ReassignReviewer_ReviewerNotAssigned(t *testing.T) {
 		AssignedReviewers: []string{"u3", "u4"},
 	}
 
-	suite.mockPRRepo.On("GetByID", "pr-1").Return(pr, nil)
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
 
-	result, newReviewer, err := suite.prService.ReassignReviewer("pr-1", "u2")
+	result, newReviewer, err := suite.prService.ReassignReviewer(context.Background(), "pr-1", "u2")
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -293,22 +298,25 @@ func TestPullRequestService_ReassignReviewer_NoReplacementCandidate(t *testing.T
 	oldReviewer := &domain.User{
 		ID:       "u2",
 		Username: "Bob",
-		TeamName: "backend",
+		Teams:    []string{"backend"},
 		IsActive: true,
 	}
 
 	teamUsers := []*domain.User{}
 
-	suite.mockPRRepo.On("GetByID", "pr-1").Return(pr, nil)
-	suite.mockUserRepo.On("GetByID", "u2").Return(oldReviewer, nil)
-	suite.mockUserRepo.On("GetByTeam", "backend").Return(teamUsers, nil)
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").Return(oldReviewer, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return(teamUsers, nil)
+
+	failuresBefore := metrics.NoCandidateFailures.Value()
 
-	result, newReviewer, err := suite.prService.ReassignReviewer("pr-1", "u2")
+	result, newReviewer, err := suite.prService.ReassignReviewer(context.Background(), "pr-1", "u2")
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, "", newReviewer)
 	assert.Equal(t, domain.ErrNoCandidate, err)
+	assert.Equal(t, failuresBefore+1, metrics.NoCandidateFailures.Value())
 	suite.mockPRRepo.AssertExpectations(t)
 	suite.mockUserRepo.AssertExpectations(t)
 }
@@ -332,7 +340,7 @@ func TestPullRequestService_GetUserReviews_Success(t *testing.T) {
 		},
 	}
 
-	suite.mockPRRepo.On("ListByReviewer", userID).Return([]*domain.PullRequest{
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, userID).Return([]*domain.PullRequest{
 		{
 			ID:       "pr-1",
 			Name:     "Add feature",
@@ -347,7 +355,7 @@ func TestPullRequestService_GetUserReviews_Success(t *testing.T) {
 		},
 	}, nil)
 
-	result, err := suite.prService.GetUserReviews(userID)
+	result, err := suite.prService.GetUserReviews(context.Background(), userID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedPRs, result)
@@ -359,9 +367,9 @@ func TestPullRequestService_GetUserReviews_EmptyList(t *testing.T) {
 	suite := NewPRServiceTestSuite()
 	userID := "u1"
 
-	suite.mockPRRepo.On("ListByReviewer", userID).Return([]*domain.PullRequest{}, nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, userID).Return([]*domain.PullRequest{}, nil)
 
-	result, err := suite.prService.GetUserReviews(userID)
+	result, err := suite.prService.GetUserReviews(context.Background(), userID)
 
 	assert.NoError(t, err)
 	assert.Empty(t, result)
@@ -374,9 +382,9 @@ func TestPullRequestService_GetUserReviews_RepositoryError(t *testing.T) {
 	userID := "u1"
 	expectedError := assert.AnError
 
-	suite.mockPRRepo.On("ListByReviewer", userID).Return(nil, expectedError)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, userID).Return(nil, expectedError)
 
-	result, err := suite.prService.GetUserReviews(userID)
+	result, err := suite.prService.GetUserReviews(context.Background(), userID)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -403,7 +411,7 @@ func TestPullRequestService_GetUserReviews_WithMultiplePRsSameAuthor(t *testing.
 		},
 	}
 
-	suite.mockPRRepo.On("ListByReviewer", userID).Return([]*domain.PullRequest{
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, userID).Return([]*domain.PullRequest{
 		{
 			ID:       "pr-1",
 			Name:     "Feature A",
@@ -418,7 +426,7 @@ func TestPullRequestService_GetUserReviews_WithMultiplePRsSameAuthor(t *testing.
 		},
 	}, nil)
 
-	result, err := suite.prService.GetUserReviews(userID)
+	result, err := suite.prService.GetUserReviews(context.Background(), userID)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -427,3 +435,343 @@ func TestPullRequestService_GetUserReviews_WithMultiplePRsSameAuthor(t *testing.
 	assert.Equal(t, "u2", result[1].AuthorID)
 	suite.mockPRRepo.AssertExpectations(t)
 }
+
+func TestPullRequestService_ReviewPolicyPerTeam(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	suite.prService.SetReviewPolicy(domain.ReviewPolicy{
+		ReviewersPerPR: 2,
+		TeamReviewers:  map[string]int{"backend": 3},
+	})
+
+	author := &domain.User{ID: "u1", Username: "alice", Teams: []string{"backend"}, IsActive: true}
+	teamUsers := []*domain.User{
+		author,
+		{ID: "u2", Teams: []string{"backend"}, IsActive: true},
+		{ID: "u3", Teams: []string{"backend"}, IsActive: true},
+		{ID: "u4", Teams: []string{"backend"}, IsActive: true},
+		{ID: "u5", Teams: []string{"backend"}, IsActive: true},
+	}
+
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(author, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return(teamUsers, nil)
+	suite.mockPRRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	pr := CreateTestPullRequest()
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
+
+	assert.NoError(t, err)
+	assert.Len(t, pr.AssignedReviewers, 3)
+	assert.NotContains(t, pr.AssignedReviewers, "u1")
+}
+
+func TestPullRequestService_CreatePullRequest_TeamAmbiguous(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	pr := CreateTestPullRequest()
+
+	author := &domain.User{ID: "u1", Username: "Alice", IsActive: true, Teams: []string{"backend", "platform"}}
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(author, nil)
+
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
+
+	assert.Equal(t, domain.ErrTeamAmbiguous, err)
+	suite.mockPRRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPullRequestService_CreatePullRequest_ExplicitTeam(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	pr := CreateTestPullRequest()
+	pr.TeamName = "platform"
+
+	author := &domain.User{ID: "u1", Username: "Alice", IsActive: true, Teams: []string{"backend", "platform"}}
+	platform := []*domain.User{
+		author,
+		{ID: "u5", IsActive: true, Teams: []string{"platform"}},
+	}
+
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(author, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "platform").Return(platform, nil)
+	suite.mockPRRepo.On("Create", mock.Anything, mock.MatchedBy(func(p *domain.PullRequest) bool {
+		return p.TeamName == "platform"
+	})).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u5"}, pr.AssignedReviewers)
+	suite.mockPRRepo.AssertExpectations(t)
+	suite.mockUserRepo.AssertNotCalled(t, "GetByTeam", mock.Anything, "backend")
+}
+
+func TestPullRequestService_CreatePullRequest_ExplicitTeamChecks(t *testing.T) {
+	author := &domain.User{ID: "u1", Username: "Alice", IsActive: true, Teams: []string{"backend"}}
+
+	tests := []struct {
+		team    string
+		exists  bool
+		wantErr error
+	}{
+		{team: "platform", exists: true, wantErr: domain.ErrNotTeamMember},
+		{team: "ghosts", exists: false, wantErr: domain.ErrTeamNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.team, func(t *testing.T) {
+			suite := NewPRServiceTestSuite()
+			pr := CreateTestPullRequest()
+			pr.TeamName = tt.team
+
+			suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+			suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(author, nil)
+			suite.mockTeamRepo.On("Exists", mock.Anything, tt.team).Return(tt.exists, nil)
+
+			err := suite.prService.CreatePullRequest(context.Background(), pr)
+
+			assert.Equal(t, tt.wantErr, err)
+			suite.mockPRRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPullRequestService_ReassignReviewer_UsesPullRequestTeam(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+
+	pr := &domain.PullRequest{
+		ID:                "pr-1",
+		Name:              "Test PR",
+		AuthorID:          "u1",
+		TeamName:          "platform",
+		Status:            domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+	platform := []*domain.User{
+		{ID: "u2", IsActive: true, Teams: []string{"backend", "platform"}},
+		{ID: "u5", IsActive: true, Teams: []string{"platform"}},
+	}
+
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "platform").Return(platform, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	_, newReviewer, err := suite.prService.ReassignReviewer(context.Background(), "pr-1", "u2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "u5", newReviewer)
+	suite.mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything, "u2")
+}
+
+func TestPullRequestService_MergePullRequest_ClosedPR(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+
+	pr := &domain.PullRequest{ID: "pr-1", AuthorID: "u1", Status: domain.PRStatusClosed}
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
+
+	result, err := suite.prService.MergePullRequest(context.Background(), "pr-1")
+
+	assert.Equal(t, domain.ErrPullRequestClosed, err)
+	assert.Nil(t, result)
+	suite.mockPRRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPullRequestService_CreatePullRequest_FallsBackToParent(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	suite.prService.SetReviewPolicy(domain.ReviewPolicy{
+		ReviewersPerPR: 2,
+		TeamFallback:   map[string]domain.FallbackPolicy{"squad-a": domain.FallbackParent},
+	})
+
+	author := &domain.User{ID: "u1", Teams: []string{"squad-a"}, IsActive: true}
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(author, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "squad-a").Return([]*domain.User{author, {ID: "u2", IsActive: true}}, nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "squad-a").Return(&domain.Team{Name: "squad-a", ParentName: "dept"}, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, "dept").Return([]string{"squad-a", "squad-b"}, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, "squad-a").Return(nil, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, "squad-b").Return(nil, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "dept").Return(nil, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "squad-b").Return([]*domain.User{
+		{ID: "u2", IsActive: true},
+		{ID: "u3", IsActive: true},
+	}, nil)
+	suite.mockPRRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	pr := CreateTestPullRequest()
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u3"}, pr.AssignedReviewers, "own team first, then the sibling squad")
+	suite.mockUserRepo.AssertNumberOfCalls(t, "GetByTeam", 3)
+}
+
+func TestPullRequestService_ReassignReviewer_WithoutFallbackStaysInTeam(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "squad-a", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "squad-a").Return([]*domain.User{
+		{ID: "u1", IsActive: true},
+		{ID: "u2", IsActive: true},
+	}, nil)
+
+	_, _, err := suite.prService.ReassignReviewer(context.Background(), "pr-1", "u2")
+
+	assert.Equal(t, domain.ErrNoCandidate, err)
+	suite.mockTeamRepo.AssertNotCalled(t, "GetByName", mock.Anything, mock.Anything)
+}
+
+func TestPullRequestService_ReassignReviewer_WalksUpToAncestors(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	suite.prService.SetReviewPolicy(domain.ReviewPolicy{ReviewersPerPR: 2, Fallback: domain.FallbackAncestors})
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "squad-a", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "squad-a").Return([]*domain.User{
+		{ID: "u1", IsActive: true},
+		{ID: "u2", IsActive: true},
+	}, nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "squad-a").Return(&domain.Team{Name: "squad-a", ParentName: "dept"}, nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "dept").Return(&domain.Team{Name: "dept", ParentName: "org"}, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, "dept").Return([]string{"squad-a"}, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, "squad-a").Return(nil, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, "org").Return([]string{"dept"}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "dept").Return([]*domain.User{{ID: "u3", IsActive: false}}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "org").Return([]*domain.User{{ID: "u4", IsActive: true}}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	result, newReviewer, err := suite.prService.ReassignReviewer(context.Background(), "pr-1", "u2")
+
+	require.NoError(t, err)
+	assert.Equal(t, "u4", newReviewer)
+	assert.Equal(t, []string{"u4"}, result.AssignedReviewers)
+}
+
+func backendUser(id string, role domain.TeamRole) *domain.User {
+	return &domain.User{
+		ID: id, IsActive: true, Teams: []string{"backend"},
+		Roles: map[string]domain.TeamRole{"backend": role},
+	}
+}
+
+func TestPullRequestService_CreatePullRequest_HoldsBackLeadsAndTrainees(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(CreateTestUser(), nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{
+		backendUser("u2", domain.RoleLead),
+		backendUser("u3", domain.RoleTrainee),
+		backendUser("u4", domain.RoleMember),
+	}, nil)
+	suite.mockPRRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	pr := CreateTestPullRequest()
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u4", "u3"}, pr.AssignedReviewers, "the trainee joins the member, the lead stays out")
+}
+
+func TestPullRequestService_CreatePullRequest_EscalatesToLead(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(CreateTestUser(), nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{
+		backendUser("u2", domain.RoleLead),
+		backendUser("u3", domain.RoleTrainee),
+	}, nil)
+	suite.mockPRRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	pr := CreateTestPullRequest()
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, pr.AssignedReviewers, "a trainee must not review alone")
+}
+
+func TestPullRequestService_CreatePullRequest_RequiresMaintainer(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	suite.prService.SetReviewPolicy(domain.ReviewPolicy{ReviewersPerPR: 1, RequireMaintainer: true})
+
+	suite.mockPRRepo.On("Exists", mock.Anything, "pr-1").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(CreateTestUser(), nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{
+		backendUser("u2", domain.RoleMember),
+		backendUser("u3", domain.RoleMember),
+		backendUser("u4", domain.RoleMaintainer),
+	}, nil)
+	suite.mockPRRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	pr := CreateTestPullRequest()
+	err := suite.prService.CreatePullRequest(context.Background(), pr)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u4"}, pr.AssignedReviewers)
+}
+
+func TestPullRequestService_ReassignReviewer_KeepsTraineeCompany(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{
+		backendUser("u1", domain.RoleMember),
+		backendUser("u2", domain.RoleMember),
+		backendUser("u3", domain.RoleTrainee),
+		backendUser("u4", domain.RoleTrainee),
+		backendUser("u5", domain.RoleLead),
+	}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u3").Return(backendUser("u3", domain.RoleTrainee), nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	_, newReviewer, err := suite.prService.ReassignReviewer(context.Background(), "pr-1", "u2")
+
+	require.NoError(t, err)
+	assert.Equal(t, "u5", newReviewer, "two trainees may not review alone, so the lead steps in")
+}
+
+func TestPullRequestService_ReassignReviewer_ReplacesLastMaintainer(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	suite.prService.SetReviewPolicy(domain.ReviewPolicy{ReviewersPerPR: 2, RequireMaintainer: true})
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(pr, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u3").Return(backendUser("u3", domain.RoleMember), nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{
+		backendUser("u1", domain.RoleMember),
+		backendUser("u2", domain.RoleMaintainer),
+		backendUser("u3", domain.RoleMember),
+		backendUser("u4", domain.RoleMember),
+		backendUser("u5", domain.RoleMaintainer),
+	}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	_, newReviewer, err := suite.prService.ReassignReviewer(context.Background(), "pr-1", "u2")
+
+	require.NoError(t, err)
+	assert.Equal(t, "u5", newReviewer)
+}
@@ -0,0 +1,174 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/internal/auth"
+	"github.com/111zxc/pr-review-service/internal/config"
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/metrics"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func serve(h http.Handler, path, remoteAddr, token string) *httptest.ResponseRecorder {
+	return serveAs(h, path, remoteAddr, token, "")
+}
+
+// serveAs serves the request as if the auth middleware had verified it for
+// principal; an empty principal leaves the token unverified.
+func serveAs(h http.Handler, path, remoteAddr, token, principal string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if principal != "" {
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{UserID: principal}))
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit_PerClientBuckets(t *testing.T) {
+	h := handler.NewRateLimitMiddleware(handler.RateLimitOptions{
+		RequestsPerSecond: 0.5,
+		Burst:             2,
+		ExemptPaths:       []string{"/health"},
+	})(okHandler())
+
+	before := metrics.ThrottledRequests.Value("rate_limit")
+
+	assert.Equal(t, http.StatusOK, serve(h, "/pullRequest/create", "10.0.0.1:1000", "").Code)
+	assert.Equal(t, http.StatusOK, serve(h, "/pullRequest/create", "10.0.0.1:1001", "").Code)
+
+	rec := serve(h, "/pullRequest/create", "10.0.0.1:1002", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	assert.Equal(t, before+1, metrics.ThrottledRequests.Value("rate_limit"))
+
+	// other IPs and verified principals have their own buckets
+	assert.Equal(t, http.StatusOK, serve(h, "/pullRequest/create", "10.0.0.2:1000", "").Code)
+	assert.Equal(t, http.StatusOK, serveAs(h, "/pullRequest/create", "10.0.0.1:1003", "ci-bot", "u1").Code)
+
+	// unverified tokens count against the IP, however often they change
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, "/pullRequest/create", "10.0.0.1:1005", "random-1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, "/pullRequest/create", "10.0.0.1:1006", "random-2").Code)
+
+	// probes are never throttled
+	assert.Equal(t, http.StatusOK, serve(h, "/health", "10.0.0.1:1004", "").Code)
+}
+
+func TestRateLimit_ForwardedForTakesTheEntryOfTheTrustedProxy(t *testing.T) {
+	h := handler.NewRateLimitMiddleware(handler.RateLimitOptions{
+		RequestsPerSecond: 0.5,
+		Burst:             1,
+		TrustForwardedFor: true,
+		TrustedProxies:    2,
+	})(okHandler())
+
+	serveVia := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", nil)
+		req.RemoteAddr = "10.0.0.9:1000"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serveVia("203.0.113.7, 10.0.0.5"))
+	// entries the client prepends do not give it a new bucket
+	assert.Equal(t, http.StatusTooManyRequests, serveVia("forged-1, 203.0.113.7, 10.0.0.5"))
+	assert.Equal(t, http.StatusTooManyRequests, serveVia("forged-2, 203.0.113.7, 10.0.0.5"))
+	assert.Equal(t, http.StatusOK, serveVia("198.51.100.1, 10.0.0.5"))
+	// a shorter header only holds trusted entries
+	assert.Equal(t, http.StatusOK, serveVia("10.0.0.6"))
+}
+
+func TestRateLimit_EvictsLeastRecentlySeenClient(t *testing.T) {
+	h := handler.NewRateLimitMiddleware(handler.RateLimitOptions{
+		RequestsPerSecond: 0.001,
+		Burst:             1,
+	})(okHandler())
+	limited := func(addr string) bool {
+		return serve(h, "/pullRequest/create", addr, "").Code == http.StatusTooManyRequests
+	}
+
+	assert.False(t, limited("10.1.0.1:1"))
+	assert.False(t, limited("10.1.0.2:1"))
+
+	// 9,998 more clients fill the table, 10.1.0.1 is seen again and the
+	// next new client pushes out 10.1.0.2, the least recently seen.
+	for i := range 9998 {
+		assert.False(t, limited(fmt.Sprintf("10.2.%d.%d:1", i/256, i%256)))
+	}
+	assert.True(t, limited("10.1.0.1:1"))
+	assert.False(t, limited("10.3.0.1:1"))
+
+	assert.False(t, limited("10.1.0.2:1"), "10.1.0.2 got a fresh bucket")
+	assert.True(t, limited("10.1.0.1:1"), "10.1.0.1 kept its bucket")
+}
+
+func TestConcurrencyLimit_RejectsWhenSaturated(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	blocking := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		started <- struct{}{}
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	h := handler.NewConcurrencyLimitMiddleware(1, 20*time.Millisecond)(blocking)
+
+	done := make(chan int)
+	go func() {
+		done <- serve(h, "/stats", "10.0.0.1:1000", "").Code
+	}()
+	<-started
+
+	before := metrics.ThrottledRequests.Value("concurrency")
+
+	rec := serve(h, "/stats", "10.0.0.2:1000", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	assert.Equal(t, before+1, metrics.ThrottledRequests.Value("concurrency"))
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
+
+func TestMiddlewares_LimitTokenEndpoints(t *testing.T) {
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{
+		Enabled:           true,
+		RequestsPerSecond: 0.5,
+		Burst:             1,
+		MaxInFlight:       10,
+		QueueTimeout:      time.Second,
+	}}
+
+	var h http.Handler = okHandler()
+	middlewares := app.NewMiddlewares(cfg, nil)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	// the admin and SCIM tokens cannot be guessed at full speed
+	assert.Equal(t, http.StatusOK, serve(h, "/admin/sync", "10.0.1.1:1000", "guess-1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, "/scim/v2/Users", "10.0.1.1:1001", "guess-2").Code)
+
+	assert.Equal(t, http.StatusOK, serve(h, "/health", "10.0.1.1:1002", "").Code)
+	assert.Equal(t, http.StatusOK, serve(h, "/metrics", "10.0.1.1:1003", "").Code)
+}
@@ -0,0 +1,174 @@
+package unit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/111zxc/pr-review-service/internal/app"
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/logger"
+	"github.com/111zxc/pr-review-service/internal/service"
+)
+
+func serveWithLogger(t *testing.T, h http.Handler, req *http.Request) (*httptest.ResponseRecorder, []map[string]any) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	req = req.WithContext(logger.WithContext(req.Context(), log))
+
+	routeOf := func(r *http.Request) string { return r.URL.Path }
+	rec := httptest.NewRecorder()
+	handler.NewRequestLogMiddleware(routeOf)(h).ServeHTTP(rec, req)
+
+	var records []map[string]any
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	return rec, records
+}
+
+func TestRequestLog_GeneratesRequestIDAndWritesAccessLog(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Info("inside handler")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("short and stout"))
+	})
+
+	rec, records := serveWithLogger(t, h, httptest.NewRequest(http.MethodGet, "/team/get", nil))
+
+	requestID := rec.Header().Get(handler.RequestIDHeader)
+	require.NotEmpty(t, requestID)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "inside handler", records[0]["msg"])
+	assert.Equal(t, requestID, records[0]["request_id"])
+	assert.Equal(t, "/team/get", records[0]["route"])
+
+	access := records[1]
+	assert.Equal(t, "http request", access["msg"])
+	assert.Equal(t, requestID, access["request_id"])
+	assert.Equal(t, float64(http.StatusTeapot), access["status"])
+	assert.Equal(t, float64(len("short and stout")), access["bytes"])
+	assert.Contains(t, access, "duration_ms")
+}
+
+func TestRequestLog_RequestIDHeader(t *testing.T) {
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+	tests := []struct {
+		name     string
+		incoming string
+		kept     bool
+	}{
+		{"valid id is propagated", "req-42.abc_DEF:1", true},
+		{"id with spaces is replaced", "req 42", false},
+		{"too long id is replaced", strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			req.Header.Set(handler.RequestIDHeader, tt.incoming)
+
+			rec, records := serveWithLogger(t, noop, req)
+			got := rec.Header().Get(handler.RequestIDHeader)
+
+			require.NotEmpty(t, got)
+			assert.Equal(t, tt.kept, got == tt.incoming)
+			require.Len(t, records, 1)
+			assert.Equal(t, got, records[0]["request_id"])
+		})
+	}
+}
+
+func TestRequestLog_HandlerErrorsCarryRequestAndPRIDs(t *testing.T) {
+	suite := NewPRServiceTestSuite()
+	suite.mockPRRepo.On("GetByID", mock.Anything, "pr-1").Return(nil, errors.New("connection reset"))
+	h := handler.NewPullRequestHandler(suite.prService)
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge", strings.NewReader(`{"pull_request_id":"pr-1"}`))
+	req.Header.Set(handler.RequestIDHeader, "merge-req-1")
+
+	rec, records := serveWithLogger(t, http.HandlerFunc(h.MergePullRequest), req)
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	var found bool
+	for _, record := range records {
+		if record["msg"] != "Failed to merge PR" {
+			continue
+		}
+		found = true
+		assert.Equal(t, "merge-req-1", record["request_id"])
+		assert.Equal(t, "pr-1", record["pr_id"])
+		assert.Equal(t, "/pullRequest/merge", record["route"])
+	}
+	assert.True(t, found, "expected the merge failure to be logged")
+
+	var errResp domain.ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errResp))
+	assert.Equal(t, "INTERNAL_ERROR", errResp.Error.Code)
+}
+
+func TestRequestLog_KeepsResourceIDsOutOfPaths(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	prevProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(prevProvider)
+
+	repos := app.NewMemoryRepositories()
+	require.NoError(t, repos.User.Create(context.Background(), &domain.User{ID: "dana@example.com", Username: "dana", IsActive: true}))
+	prService := service.NewPullRequestService(repos.PR, repos.User, repos.Team, repos.PRStatus, repos.Events)
+	userService := service.NewUserService(repos.User, repos.Tx, prService)
+	teamService := service.NewTeamService(repos.Team, repos.User, repos.Tx, prService)
+	h := handler.New(teamService, userService, prService, service.NewStatsService(repos.Stats))
+	h.SCIM = handler.NewSCIMHandler("idp-token", userService, teamService)
+
+	req := httptest.NewRequest(http.MethodDelete, "/scim/v2/Users/dana@example.com", nil)
+	req.Header.Set("Authorization", "Bearer idp-token")
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+	req = req.WithContext(logger.WithContext(req.Context(), log))
+	rec := httptest.NewRecorder()
+	app.NewRouter(h).ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	var access map[string]any
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		if record["msg"] == "http request" {
+			access = record
+		}
+	}
+	require.NotNil(t, access)
+	assert.Equal(t, "/scim/v2/Users/{id}", access["path"])
+	assert.NotContains(t, fmt.Sprint(access), "dana")
+
+	for _, span := range exporter.GetSpans() {
+		for _, attr := range span.Attributes {
+			assert.NotContains(t, attr.Value.Emit(), "dana", "span %s, attribute %s", span.Name, attr.Key)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/roster"
+)
+
+func TestRoster_DecodeYAMLAndJSON(t *testing.T) {
+	want := []*domain.Team{
+		{Name: "platform"},
+		{Name: "backend", ParentName: "platform", Members: []domain.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleLead},
+			{UserID: "u2", Username: "Bob", IsActive: false, Role: domain.RoleMember},
+		}},
+	}
+
+	yamlRoster := `
+teams:
+  - team_name: platform
+  - team_name: backend
+    parent_team: platform
+    members:
+      - {user_id: u1, username: Alice, role: lead}
+      - {user_id: u2, username: Bob, is_active: false}
+`
+	teams, err := roster.Decode(strings.NewReader(yamlRoster))
+	require.NoError(t, err)
+	assert.Equal(t, want, teams)
+
+	jsonRoster := `{"teams": [
+		{"team_name": "platform"},
+		{"team_name": "backend", "parent_team": "platform", "members": [
+			{"user_id": "u1", "username": "Alice", "role": "lead"},
+			{"user_id": "u2", "username": "Bob", "is_active": false}
+		]}
+	]}`
+	teams, err = roster.Decode(strings.NewReader(jsonRoster))
+	require.NoError(t, err)
+	assert.Equal(t, want, teams)
+}
+
+func TestRoster_DecodeRejectsUnknownFieldsAndRoles(t *testing.T) {
+	_, err := roster.Decode(strings.NewReader("teams:\n  - team_name: a\n    lead: u1\n"))
+	assert.ErrorContains(t, err, "field lead not found")
+
+	_, err = roster.Decode(strings.NewReader("teams:\n  - team_name: a\n    members: [{user_id: u1, role: boss}]\n"))
+	assert.ErrorContains(t, err, `unknown role "boss"`)
+}
+
+func TestRoster_EmptyRosterIsInvalid(t *testing.T) {
+	for name, body := range map[string]string{"empty body": "", "no teams": "teams: []\n"} {
+		t.Run(name, func(t *testing.T) {
+			teams, err := roster.Decode(strings.NewReader(body))
+			require.NoError(t, err)
+
+			var invalid *domain.RosterError
+			require.ErrorAs(t, domain.ValidateRoster(teams), &invalid)
+			assert.Equal(t, []string{"the roster lists no teams"}, invalid.Problems)
+		})
+	}
+}
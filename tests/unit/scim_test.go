@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/111zxc/pr-review-service/internal/scim"
+)
+
+func TestSCIM_ParseFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   *scim.Filter
+	}{
+		{"", nil},
+		{`userName eq "alice@example.com"`, &scim.Filter{Attribute: "username", Value: "alice@example.com"}},
+		{`externalId EQ "a\"b"`, &scim.Filter{Attribute: "externalid", Value: `a"b`}},
+		{"active eq false", &scim.Filter{Attribute: "active", Value: "false"}},
+	}
+	for _, tt := range tests {
+		got, err := scim.ParseFilter(tt.filter)
+		require.NoError(t, err, tt.filter)
+		assert.Equal(t, tt.want, got, tt.filter)
+	}
+
+	for _, filter := range []string{`userName sw "a"`, `userName eq "a" and active eq true`, "userName eq alice"} {
+		_, err := scim.ParseFilter(filter)
+		var scimErr *scim.Error
+		require.ErrorAs(t, err, &scimErr, filter)
+		assert.Equal(t, scim.ErrInvalidFilter, scimErr.ScimType)
+		assert.Equal(t, http.StatusBadRequest, scimErr.HTTPStatus())
+	}
+}
+
+func TestSCIM_ParsePage(t *testing.T) {
+	page, err := scim.ParsePage("", "")
+	require.NoError(t, err)
+	assert.Equal(t, scim.Page{StartIndex: 1, Count: scim.MaxResults}, page)
+
+	page, err = scim.ParsePage("0", "1000")
+	require.NoError(t, err)
+	assert.Equal(t, scim.Page{StartIndex: 1, Count: scim.MaxResults}, page)
+
+	page, err = scim.ParsePage("11", "-5")
+	require.NoError(t, err)
+	assert.Equal(t, scim.Page{StartIndex: 11, Count: 0}, page)
+	assert.Equal(t, 10, page.Offset())
+
+	_, err = scim.ParsePage("first", "")
+	assert.Error(t, err)
+}
+
+func patchOps(t *testing.T, body string) []scim.Operation {
+	t.Helper()
+
+	var req scim.PatchRequest
+	require.NoError(t, json.Unmarshal([]byte(body), &req))
+	return req.Operations
+}
+
+func TestSCIM_UserApply(t *testing.T) {
+	user := scim.User{UserName: "alice", Active: true}
+
+	err := user.Apply(patchOps(t, `{"Operations": [
+		{"op": "Replace", "path": "active", "value": "False"},
+		{"op": "replace", "path": "name.givenName", "value": "Alice"},
+		{"op": "remove", "path": "title"}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, scim.User{UserName: "alice", Active: false}, user)
+
+	err = user.Apply(patchOps(t, `{"Operations": [{"op": "replace", "value": {"userName": "alice2", "active": true}}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, scim.User{UserName: "alice2", Active: true}, user)
+
+	assert.Error(t, user.Apply(patchOps(t, `{"Operations": [{"op": "replace", "path": "active", "value": "maybe"}]}`)))
+	assert.Error(t, user.Apply(patchOps(t, `{"Operations": [{"op": "move", "path": "active", "value": true}]}`)))
+}
+
+func TestSCIM_GroupApply(t *testing.T) {
+	group := scim.Group{DisplayName: "backend", Members: []scim.Ref{{Value: "u1"}}}
+
+	err := group.Apply(patchOps(t, `{"Operations": [
+		{"op": "add", "path": "members", "value": [{"value": "u1"}, {"value": "u2"}, {"value": "u3"}]},
+		{"op": "remove", "path": "members[value eq \"u1\"]"},
+		{"op": "Remove", "path": "members", "value": [{"value": "u3"}]},
+		{"op": "replace", "value": {"id": "T1", "displayName": "platform"}}
+	]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "platform", group.DisplayName)
+	assert.Equal(t, []scim.Ref{{Value: "u2"}}, group.Members)
+
+	err = group.Apply(patchOps(t, `{"Operations": [{"op": "replace", "path": "members", "value": [{"value": "u4"}]}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, []scim.Ref{{Value: "u4"}}, group.Members)
+
+	err = group.Apply(patchOps(t, `{"Operations": [{"op": "remove", "path": "members"}]}`))
+	require.NoError(t, err)
+	assert.Empty(t, group.Members)
+
+	err = group.Apply(patchOps(t, `{"Operations": [{"op": "remove", "path": "displayName"}]}`))
+	var scimErr *scim.Error
+	require.ErrorAs(t, err, &scimErr)
+	assert.Equal(t, scim.ErrInvalidPath, scimErr.ScimType)
+}
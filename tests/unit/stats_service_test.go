@@ -1,9 +1,11 @@
 package unit
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/111zxc/pr-review-service/internal/domain"
 	"github.com/111zxc/pr-review-service/internal/repository/mocks"
@@ -39,9 +41,9 @@ func TestStatsService_GetStats_Success(t *testing.T) {
 		TotalEvents: 55,
 	}
 
-	suite.mockStatsRepo.On("GetEventStats").Return(expectedStats, nil)
+	suite.mockStatsRepo.On("GetEventStats", mock.Anything).Return(expectedStats, nil)
 
-	result, err := suite.statsService.GetStats()
+	result, err := suite.statsService.GetStats(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedStats, result)
@@ -68,9 +70,9 @@ func TestStatsService_GetStats_EmptyStats(t *testing.T) {
 		TotalEvents: 0,
 	}
 
-	suite.mockStatsRepo.On("GetEventStats").Return(expectedStats, nil)
+	suite.mockStatsRepo.On("GetEventStats", mock.Anything).Return(expectedStats, nil)
 
-	result, err := suite.statsService.GetStats()
+	result, err := suite.statsService.GetStats(context.Background())
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedStats, result)
@@ -85,12 +87,25 @@ func TestStatsService_GetStats_RepositoryError(t *testing.T) {
 	suite := NewStatsServiceTestSuite()
 	expectedError := assert.AnError
 
-	suite.mockStatsRepo.On("GetEventStats").Return(nil, expectedError)
+	suite.mockStatsRepo.On("GetEventStats", mock.Anything).Return(nil, expectedError)
 
-	result, err := suite.statsService.GetStats()
+	result, err := suite.statsService.GetStats(context.Background())
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, expectedError, err)
 	suite.mockStatsRepo.AssertExpectations(t)
 }
+
+func TestStatsService_GetOpenReviewsByTeam(t *testing.T) {
+	suite := NewStatsServiceTestSuite()
+
+	expected := map[string]int{"backend": 4, "frontend": 1}
+	suite.mockStatsRepo.On("GetOpenReviewsByTeam", mock.Anything).Return(expected, nil)
+
+	result, err := suite.statsService.GetOpenReviewsByTeam(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	suite.mockStatsRepo.AssertExpectations(t)
+}
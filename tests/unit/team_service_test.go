@@ -1,31 +1,53 @@
 package unit
 
 import (
+	"context"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"github.com/111zxc/pr-review-service/internal/auth"
 	"github.com/111zxc/pr-review-service/internal/domain"
 	"github.com/111zxc/pr-review-service/internal/repository/mocks"
 	"github.com/111zxc/pr-review-service/internal/service"
 )
 
 type TeamServiceTestSuite struct {
-	mockTeamRepo *mocks.TeamRepository
-	mockUserRepo *mocks.UserRepository
-	teamService  *service.TeamService
+	mockTeamRepo   *mocks.TeamRepository
+	mockUserRepo   *mocks.UserRepository
+	mockPRRepo     *mocks.PullRequestRepository
+	mockEventsRepo *mocks.EventsRepository
+	teamService    *service.TeamService
+}
+
+// directTx runs fn without a real transaction; rollback is covered by the
+// repository contract tests.
+type directTx struct{}
+
+func (directTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
 }
 
 func NewTeamServiceTestSuite() *TeamServiceTestSuite {
 	mockTeamRepo := new(mocks.TeamRepository)
 	mockUserRepo := new(mocks.UserRepository)
-	teamService := service.NewTeamService(mockTeamRepo, mockUserRepo)
+	mockPRRepo := new(mocks.PullRequestRepository)
+	mockEventsRepo := new(mocks.EventsRepository)
+	prService := service.NewPullRequestService(
+		mockPRRepo, mockUserRepo, mockTeamRepo, new(mocks.PRStatusRepository), mockEventsRepo,
+	)
+	teamService := service.NewTeamService(mockTeamRepo, mockUserRepo, directTx{}, prService)
 
 	return &TeamServiceTestSuite{
-		mockTeamRepo: mockTeamRepo,
-		mockUserRepo: mockUserRepo,
-		teamService:  teamService,
+		mockTeamRepo:   mockTeamRepo,
+		mockUserRepo:   mockUserRepo,
+		mockPRRepo:     mockPRRepo,
+		mockEventsRepo: mockEventsRepo,
+		teamService:    teamService,
 	}
 }
 
@@ -43,18 +65,21 @@ func TestTeamService_CreateTeam_Success(t *testing.T) {
 	suite := NewTeamServiceTestSuite()
 	team := CreateTestTeam()
 
-	suite.mockTeamRepo.On("Exists", "backend").Return(false, nil)
-	suite.mockUserRepo.On("Create", mock.MatchedBy(func(user *domain.User) bool {
+	suite.mockTeamRepo.On("Exists", mock.Anything, "backend").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, domain.ErrUserNotFound)
+	suite.mockUserRepo.On("Create", mock.Anything, mock.MatchedBy(func(user *domain.User) bool {
 		return user.ID == "u1" && user.Username == "Alice"
 	})).Return(nil)
-	suite.mockUserRepo.On("Create", mock.MatchedBy(func(user *domain.User) bool {
+	suite.mockUserRepo.On("Create", mock.Anything, mock.MatchedBy(func(user *domain.User) bool {
 		return user.ID == "u2" && user.Username == "Bob"
 	})).Return(nil)
-	suite.mockTeamRepo.On("Create", team).Return(nil)
+	suite.mockTeamRepo.On("Create", mock.Anything, team).Return(nil)
 
-	err := suite.teamService.CreateTeam(team)
+	diffs, err := suite.teamService.CreateTeam(context.Background(), team, service.CreateTeamOptions{})
 
 	assert.NoError(t, err)
+	assert.Len(t, diffs, 2)
+	assert.Equal(t, domain.MemberCreate, diffs[0].Action)
 	suite.mockTeamRepo.AssertExpectations(t)
 	suite.mockUserRepo.AssertExpectations(t)
 }
@@ -63,22 +88,105 @@ func TestTeamService_CreateTeam_AlreadyExists(t *testing.T) {
 	suite := NewTeamServiceTestSuite()
 	team := CreateTestTeam()
 
-	suite.mockTeamRepo.On("Exists", "backend").Return(true, nil)
+	suite.mockTeamRepo.On("Exists", mock.Anything, "backend").Return(true, nil)
 
-	err := suite.teamService.CreateTeam(team)
+	_, err := suite.teamService.CreateTeam(context.Background(), team, service.CreateTeamOptions{})
 
 	assert.Error(t, err)
 	assert.Equal(t, domain.ErrTeamExists, err)
 	suite.mockTeamRepo.AssertExpectations(t)
 }
 
+func TestTeamService_CreateTeam_RejectsConflictingMembers(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+
+	suite.mockTeamRepo.On("Exists", mock.Anything, "backend").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").
+		Return(&domain.User{ID: "u1", Username: "Alice", IsActive: true, Teams: []string{"frontend"}}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").
+		Return(&domain.User{ID: "u2", Username: "Robert", IsActive: true}, nil)
+
+	_, err := suite.teamService.CreateTeam(context.Background(), team, service.CreateTeamOptions{})
+
+	var conflict *domain.MemberConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Len(t, conflict.Conflicts, 2)
+	assert.Equal(t, []string{"frontend"}, conflict.Conflicts[0].OtherTeams)
+	assert.Equal(t, domain.MemberUpdate, conflict.Conflicts[1].Action)
+	assert.Equal(t, "Robert", conflict.Conflicts[1].OldUsername)
+	suite.mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	suite.mockTeamRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_CreateTeam_JoinsUnchangedTeamlessUser(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+
+	suite.mockTeamRepo.On("Exists", mock.Anything, "backend").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").
+		Return(&domain.User{ID: "u1", Username: "Alice", IsActive: true}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").Return(nil, domain.ErrUserNotFound)
+	suite.mockUserRepo.On("Create", mock.Anything, mock.MatchedBy(func(user *domain.User) bool {
+		return user.ID == "u2"
+	})).Return(nil).Once()
+	suite.mockTeamRepo.On("Create", mock.Anything, team).Return(nil)
+
+	diffs, err := suite.teamService.CreateTeam(context.Background(), team, service.CreateTeamOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.MemberUnchanged, diffs[0].Action)
+	suite.mockUserRepo.AssertExpectations(t)
+}
+
+func TestTeamService_CreateTeam_UpsertOverwrites(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+
+	suite.mockTeamRepo.On("Exists", mock.Anything, "backend").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").
+		Return(&domain.User{ID: "u1", Username: "Alice", IsActive: true, Teams: []string{"frontend"}}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").
+		Return(&domain.User{ID: "u2", Username: "Robert", IsActive: false}, nil)
+	suite.mockUserRepo.On("Create", mock.Anything, &domain.User{ID: "u2", Username: "Bob", IsActive: true}).Return(nil).Once()
+	suite.mockTeamRepo.On("Create", mock.Anything, team).Return(nil)
+
+	_, err := suite.teamService.CreateTeam(context.Background(), team, service.CreateTeamOptions{
+		OnConflict: domain.ConflictUpsert,
+	})
+
+	require.NoError(t, err)
+	suite.mockUserRepo.AssertExpectations(t)
+	suite.mockTeamRepo.AssertExpectations(t)
+}
+
+func TestTeamService_CreateTeam_DryRunWritesNothing(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+
+	suite.mockTeamRepo.On("Exists", mock.Anything, "backend").Return(false, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").
+		Return(&domain.User{ID: "u1", Username: "Alice", IsActive: true, Teams: []string{"frontend"}}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").Return(nil, domain.ErrUserNotFound)
+
+	diffs, err := suite.teamService.CreateTeam(context.Background(), team, service.CreateTeamOptions{DryRun: true})
+
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	assert.True(t, diffs[0].Conflicts())
+	assert.Equal(t, domain.MemberCreate, diffs[1].Action)
+	suite.mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	suite.mockTeamRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestTeamService_GetTeam_Success(t *testing.T) {
 	suite := NewTeamServiceTestSuite()
 	expectedTeam := CreateTestTeam()
 
-	suite.mockTeamRepo.On("GetByName", "backend").Return(expectedTeam, nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(expectedTeam, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, "backend").Return(nil, nil)
 
-	team, err := suite.teamService.GetTeam("backend")
+	team, err := suite.teamService.GetTeam(context.Background(), domain.TeamRef{Name: "backend"})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedTeam, team)
@@ -88,9 +196,9 @@ func TestTeamService_GetTeam_Success(t *testing.T) {
 func TestTeamService_GetTeam_NotFound(t *testing.T) {
 	suite := NewTeamServiceTestSuite()
 
-	suite.mockTeamRepo.On("GetByName", "nonexistent").Return(nil, domain.ErrTeamNotFound)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "nonexistent").Return(nil, domain.ErrTeamNotFound)
 
-	team, err := suite.teamService.GetTeam("nonexistent")
+	team, err := suite.teamService.GetTeam(context.Background(), domain.TeamRef{Name: "nonexistent"})
 
 	assert.Error(t, err)
 	assert.Nil(t, team)
@@ -102,12 +210,680 @@ func TestTeamService_GetTeam_RepositoryError(t *testing.T) {
 	suite := NewTeamServiceTestSuite()
 	expectedError := assert.AnError
 
-	suite.mockTeamRepo.On("GetByName", "backend").Return(nil, expectedError)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(nil, expectedError)
 
-	team, err := suite.teamService.GetTeam("backend")
+	team, err := suite.teamService.GetTeam(context.Background(), domain.TeamRef{Name: "backend"})
 
 	assert.Error(t, err)
 	assert.Nil(t, team)
 	assert.Equal(t, expectedError, err)
 	suite.mockTeamRepo.AssertExpectations(t)
 }
+
+func TestTeamService_AddMembers_Success(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	members := []domain.TeamMember{{UserID: "u3", Username: "Carol", IsActive: true}}
+	updated := CreateTestTeam()
+	updated.Members = append(updated.Members, members[0])
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u3").Return(nil, domain.ErrUserNotFound)
+	suite.mockUserRepo.On("Create", mock.Anything, &domain.User{ID: "u3", Username: "Carol", IsActive: true}).Return(nil)
+	suite.mockTeamRepo.On("AddMembers", mock.Anything, "backend", []string{"u3"}).Return(nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(updated, nil)
+
+	team, err := suite.teamService.AddMembers(context.Background(), "backend", members, service.AddMembersOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, updated, team)
+	suite.mockTeamRepo.AssertExpectations(t)
+	suite.mockUserRepo.AssertExpectations(t)
+}
+
+func TestTeamService_AddMembers_TeamNotFound(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u3").Return(nil, domain.ErrUserNotFound)
+	suite.mockUserRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	suite.mockTeamRepo.On("AddMembers", mock.Anything, "nonexistent", []string{"u3"}).Return(domain.ErrTeamNotFound)
+
+	team, err := suite.teamService.AddMembers(context.Background(), "nonexistent",
+		[]domain.TeamMember{{UserID: "u3", Username: "Carol", IsActive: true}}, service.AddMembersOptions{})
+
+	assert.Equal(t, domain.ErrTeamNotFound, err)
+	assert.Nil(t, team)
+	suite.mockTeamRepo.AssertNotCalled(t, "GetByName", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_AddMembers_RejectsChangedUsers(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	members := []domain.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+		{UserID: "u2", Username: "Robert", IsActive: true},
+	}
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").
+		Return(&domain.User{ID: "u1", Username: "Alice", IsActive: true, Teams: []string{"frontend"}}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").
+		Return(&domain.User{ID: "u2", Username: "Bob", IsActive: true}, nil)
+
+	team, err := suite.teamService.AddMembers(context.Background(), "backend", members, service.AddMembersOptions{})
+
+	// u1 only belongs to another team, which is what adding members is for
+	var conflict *domain.MemberConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.Len(t, conflict.Conflicts, 1)
+	assert.Equal(t, "u2", conflict.Conflicts[0].UserID)
+	assert.Equal(t, "Bob", conflict.Conflicts[0].OldUsername)
+	assert.Nil(t, team)
+	suite.mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	suite.mockTeamRepo.AssertNotCalled(t, "AddMembers", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamService_AddMembers_UpsertOverwrites(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	members := []domain.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+		{UserID: "u2", Username: "Robert", IsActive: true},
+	}
+	updated := CreateTestTeam()
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").
+		Return(&domain.User{ID: "u1", Username: "Alice", IsActive: true}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").
+		Return(&domain.User{ID: "u2", Username: "Bob", IsActive: true}, nil)
+	suite.mockUserRepo.On("Create", mock.Anything, &domain.User{ID: "u2", Username: "Robert", IsActive: true}).Return(nil)
+	suite.mockTeamRepo.On("AddMembers", mock.Anything, "backend", []string{"u1", "u2"}).Return(nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(updated, nil)
+
+	team, err := suite.teamService.AddMembers(context.Background(), "backend", members,
+		service.AddMembersOptions{OnConflict: domain.ConflictUpsert})
+
+	require.NoError(t, err)
+	assert.Equal(t, updated, team)
+	suite.mockUserRepo.AssertNumberOfCalls(t, "Create", 1)
+	suite.mockTeamRepo.AssertExpectations(t)
+}
+
+func TestTeamService_RemoveMember_ReassignsOpenReviews(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	open := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+	merged := &domain.PullRequest{
+		ID: "pr-2", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusMerged,
+		AssignedReviewers: []string{"u2"},
+	}
+	otherTeam := &domain.PullRequest{
+		ID: "pr-3", AuthorID: "u5", TeamName: "frontend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	suite.mockTeamRepo.On("RemoveMember", mock.Anything, "backend", "u2").Return(nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u2").Return([]*domain.PullRequest{open, merged, otherTeam}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{
+		{ID: "u1", IsActive: true},
+		{ID: "u3", IsActive: true},
+		{ID: "u4", IsActive: true},
+	}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.MatchedBy(func(pr *domain.PullRequest) bool {
+		return pr.ID == "pr-1" && slices.Equal(pr.AssignedReviewers, []string{"u4", "u3"})
+	})).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *domain.Event) bool {
+		return e.EventType == domain.EventTypeReviewerReassigned && e.PRID == "pr-1" && e.UserID == "u4"
+	})).Return(nil)
+
+	changes, err := suite.teamService.RemoveMember(context.Background(), "backend", "u2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ReviewerChange{{PRID: "pr-1", OldUserID: "u2", NewUserID: "u4"}}, changes)
+	suite.mockPRRepo.AssertNumberOfCalls(t, "Update", 1)
+	suite.mockEventsRepo.AssertExpectations(t)
+}
+
+func TestTeamService_RemoveMember_UnassignsWithoutCandidate(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	suite.mockTeamRepo.On("RemoveMember", mock.Anything, "backend", "u2").Return(nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u2").Return([]*domain.PullRequest{pr}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{{ID: "u1", IsActive: true}}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.MatchedBy(func(pr *domain.PullRequest) bool {
+		return len(pr.AssignedReviewers) == 0
+	})).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *domain.Event) bool {
+		return e.EventType == domain.EventTypeReviewerUnassigned && e.UserID == "u2"
+	})).Return(nil)
+
+	changes, err := suite.teamService.RemoveMember(context.Background(), "backend", "u2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ReviewerChange{{PRID: "pr-1", OldUserID: "u2"}}, changes)
+	suite.mockEventsRepo.AssertExpectations(t)
+}
+
+func TestTeamService_RemoveMember_EventFailureFailsChange(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	suite.mockTeamRepo.On("RemoveMember", mock.Anything, "backend", "u2").Return(nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u2").Return([]*domain.PullRequest{pr}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return(nil, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(assert.AnError)
+
+	changes, err := suite.teamService.RemoveMember(context.Background(), "backend", "u2")
+
+	assert.Equal(t, assert.AnError, err)
+	assert.Nil(t, changes)
+}
+
+func TestTeamService_RemoveMember_NotMember(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	suite.mockTeamRepo.On("RemoveMember", mock.Anything, "backend", "u9").Return(domain.ErrNotTeamMember)
+
+	_, err := suite.teamService.RemoveMember(context.Background(), "backend", "u9")
+
+	assert.Equal(t, domain.ErrNotTeamMember, err)
+	suite.mockPRRepo.AssertNotCalled(t, "ListByReviewer", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_TransferMember_Success(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	suite.mockTeamRepo.On("RemoveMember", mock.Anything, "backend", "u2").Return(nil)
+	suite.mockTeamRepo.On("AddMembers", mock.Anything, "frontend", []string{"u2"}).Return(nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u2").Return(nil, nil)
+
+	changes, err := suite.teamService.TransferMember(context.Background(), "u2", "backend", "frontend")
+
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	suite.mockTeamRepo.AssertExpectations(t)
+}
+
+func TestTeamService_TransferMember_SameTeam(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	_, err := suite.teamService.TransferMember(context.Background(), "u2", "backend", "backend")
+
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	suite.mockTeamRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamService_DeleteTeam_RejectsOpenPullRequests(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	suite.mockTeamRepo.On("Exists", mock.Anything, "backend").Return(true, nil)
+	suite.mockPRRepo.On("ListOpenByTeam", mock.Anything, "backend").Return([]*domain.PullRequest{{ID: "pr-1"}}, nil)
+
+	_, err := suite.teamService.DeleteTeam(context.Background(), "backend", service.DeleteTeamOptions{})
+
+	assert.Equal(t, domain.ErrTeamHasOpenPRs, err)
+	suite.mockTeamRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_DeleteTeam_ClosesOpenPullRequests(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	pr := &domain.PullRequest{ID: "pr-1", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen}
+
+	suite.mockTeamRepo.On("Exists", mock.Anything, "backend").Return(true, nil)
+	suite.mockPRRepo.On("ListOpenByTeam", mock.Anything, "backend").Return([]*domain.PullRequest{pr}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.MatchedBy(func(pr *domain.PullRequest) bool {
+		return pr.Status == domain.PRStatusClosed
+	})).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *domain.Event) bool {
+		return e.EventType == domain.EventTypePRClosed && e.PRID == "pr-1"
+	})).Return(nil)
+	suite.mockTeamRepo.On("Delete", mock.Anything, "backend").Return(nil)
+
+	deletion, err := suite.teamService.DeleteTeam(context.Background(), "backend",
+		service.DeleteTeamOptions{OpenPRs: domain.OpenPRsClose})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pr-1"}, deletion.ClosedPRs)
+	suite.mockEventsRepo.AssertExpectations(t)
+	suite.mockTeamRepo.AssertExpectations(t)
+}
+
+func TestTeamService_DeleteTeam_ReassignsOpenPullRequests(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2", "u3"},
+	}
+
+	suite.mockTeamRepo.On("Exists", mock.Anything, "backend").Return(true, nil)
+	suite.mockTeamRepo.On("Exists", mock.Anything, "frontend").Return(true, nil)
+	suite.mockPRRepo.On("ListOpenByTeam", mock.Anything, "backend").Return([]*domain.PullRequest{pr}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "frontend").Return([]*domain.User{
+		{ID: "u3", IsActive: true},
+		{ID: "u5", IsActive: true},
+	}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.MatchedBy(func(pr *domain.PullRequest) bool {
+		return pr.TeamName == "frontend" && slices.Equal(pr.AssignedReviewers, []string{"u5", "u3"})
+	})).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.MatchedBy(func(e *domain.Event) bool {
+		return e.EventType == domain.EventTypeReviewerReassigned && e.UserID == "u5"
+	})).Return(nil)
+	suite.mockTeamRepo.On("Delete", mock.Anything, "backend").Return(nil)
+
+	deletion, err := suite.teamService.DeleteTeam(context.Background(), "backend",
+		service.DeleteTeamOptions{OpenPRs: domain.OpenPRsReassign, ReassignTo: "frontend"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pr-1"}, deletion.MovedPRs)
+	assert.Equal(t, []domain.ReviewerChange{{PRID: "pr-1", OldUserID: "u2", NewUserID: "u5"}}, deletion.Reviews)
+	suite.mockPRRepo.AssertNumberOfCalls(t, "Update", 1)
+}
+
+func TestTeamService_DeleteTeam_ReassignToSameTeam(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	_, err := suite.teamService.DeleteTeam(context.Background(), "backend",
+		service.DeleteTeamOptions{OpenPRs: domain.OpenPRsReassign, ReassignTo: "backend"})
+
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	suite.mockTeamRepo.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_RestoreTeam_Success(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+
+	suite.mockTeamRepo.On("Restore", mock.Anything, "backend").Return(nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(team, nil)
+
+	restored, err := suite.teamService.RestoreTeam(context.Background(), "backend")
+
+	require.NoError(t, err)
+	assert.Equal(t, team, restored)
+}
+
+func TestTeamService_RenameTeam_ByID(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+	team.ID = "T1"
+	renamed := *team
+	renamed.Name = "platform"
+
+	suite.mockTeamRepo.On("GetByID", mock.Anything, "T1").Return(team, nil).Once()
+	suite.mockTeamRepo.On("Rename", mock.Anything, "backend", "platform").Return(nil)
+	suite.mockTeamRepo.On("GetByID", mock.Anything, "T1").Return(&renamed, nil).Once()
+
+	result, err := suite.teamService.RenameTeam(context.Background(), domain.TeamRef{ID: "T1"}, "platform")
+
+	require.NoError(t, err)
+	assert.Equal(t, "platform", result.Name)
+	assert.Equal(t, "T1", result.ID)
+	suite.mockTeamRepo.AssertExpectations(t)
+}
+
+func TestTeamService_RenameTeam_SameName(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(team, nil)
+
+	result, err := suite.teamService.RenameTeam(context.Background(), domain.TeamRef{Name: "backend"}, "backend")
+
+	require.NoError(t, err)
+	assert.Equal(t, team, result)
+	suite.mockTeamRepo.AssertNotCalled(t, "Rename", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamService_RenameTeam_NameTaken(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(CreateTestTeam(), nil)
+	suite.mockTeamRepo.On("Rename", mock.Anything, "backend", "frontend").Return(domain.ErrTeamExists)
+
+	_, err := suite.teamService.RenameTeam(context.Background(), domain.TeamRef{Name: "backend"}, "frontend")
+
+	assert.Equal(t, domain.ErrTeamExists, err)
+}
+
+func TestTeamService_GetTeam_ShowsTree(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "squad").Return(&domain.Team{Name: "squad", ParentName: "dept"}, nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "dept").Return(&domain.Team{Name: "dept", ParentName: "org"}, nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "org").Return(&domain.Team{Name: "org"}, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, "squad").Return([]string{"squad-a", "squad-b"}, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, "squad-a").Return([]string{"pod"}, nil)
+	suite.mockTeamRepo.On("ListSubTeams", mock.Anything, mock.Anything).Return(nil, nil)
+
+	team, err := suite.teamService.GetTeam(context.Background(), domain.TeamRef{Name: "squad"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dept", "org"}, team.Ancestors)
+	assert.Equal(t, []domain.TeamNode{
+		{Name: "squad-a", SubTeams: []domain.TeamNode{{Name: "pod"}}},
+		{Name: "squad-b"},
+	}, team.SubTeams)
+}
+
+func TestTeamService_SetParent_RejectsCycle(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "dept").Return(&domain.Team{ID: "D", Name: "dept"}, nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "squad").Return(&domain.Team{ID: "S", Name: "squad", ParentName: "dept"}, nil)
+
+	_, err := suite.teamService.SetParent(context.Background(), domain.TeamRef{Name: "dept"}, "squad")
+
+	assert.Equal(t, domain.ErrTeamCycle, err)
+	suite.mockTeamRepo.AssertNotCalled(t, "SetParent", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamService_SetMemberRole_Success(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+	team.ID = "T1"
+
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(team, nil)
+	suite.mockTeamRepo.On("SetRole", mock.Anything, "backend", "u2", domain.RoleMaintainer).Return(nil)
+	suite.mockTeamRepo.On("GetByID", mock.Anything, "T1").Return(team, nil)
+
+	_, err := suite.teamService.SetMemberRole(context.Background(), domain.TeamRef{Name: "backend"}, "u2", domain.RoleMaintainer,
+		service.SetMemberRoleOptions{Force: true})
+
+	require.NoError(t, err)
+	suite.mockTeamRepo.AssertExpectations(t)
+}
+
+func TestTeamService_SetMemberRole_DeniedWithoutPrincipal(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(CreateTestTeam(), nil)
+
+	_, err := suite.teamService.SetMemberRole(context.Background(), domain.TeamRef{Name: "backend"}, "u2", domain.RoleLead, service.SetMemberRoleOptions{})
+
+	assert.Equal(t, domain.ErrNotTeamLead, err)
+	suite.mockTeamRepo.AssertNotCalled(t, "SetRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamService_SetMemberRole_RequiresLead(t *testing.T) {
+	team := CreateTestTeam()
+	team.Members[0].Role = domain.RoleLead
+
+	cases := []struct {
+		name      string
+		principal *auth.Principal
+		allowed   bool
+	}{
+		{"lead", &auth.Principal{UserID: "u1", Teams: []string{"backend"}, Roles: map[string]domain.TeamRole{"backend": domain.RoleLead}}, true},
+		{"member", &auth.Principal{UserID: "u2", Teams: []string{"backend"}, Roles: map[string]domain.TeamRole{"backend": domain.RoleMember}}, false},
+		{"outsider", &auth.Principal{UserID: "u9"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			suite := NewTeamServiceTestSuite()
+			suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(team, nil)
+			suite.mockTeamRepo.On("SetRole", mock.Anything, "backend", "u2", domain.RoleTrainee).Return(nil)
+			suite.mockTeamRepo.On("GetByID", mock.Anything, mock.Anything).Return(team, nil)
+
+			ctx := auth.WithPrincipal(context.Background(), tc.principal)
+			_, err := suite.teamService.SetMemberRole(ctx, domain.TeamRef{Name: "backend"}, "u2", domain.RoleTrainee, service.SetMemberRoleOptions{})
+
+			if tc.allowed {
+				require.NoError(t, err)
+			} else {
+				assert.Equal(t, domain.ErrNotTeamLead, err)
+				suite.mockTeamRepo.AssertNotCalled(t, "SetRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTeamService_SetMemberRole_MemberCannotAppointSelf(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(team, nil)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{
+		UserID: "u2", Teams: []string{"backend"}, Roles: map[string]domain.TeamRole{"backend": domain.RoleMember},
+	})
+	_, err := suite.teamService.SetMemberRole(ctx, domain.TeamRef{Name: "backend"}, "u2", domain.RoleLead, service.SetMemberRoleOptions{})
+
+	assert.Equal(t, domain.ErrNotTeamLead, err)
+	suite.mockTeamRepo.AssertNotCalled(t, "SetRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamService_SetMemberRole_ParentLeadAppointsFirstLead(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+	team.ID = "T1"
+	team.ParentName = "platform"
+
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "backend").Return(team, nil)
+	suite.mockTeamRepo.On("GetByName", mock.Anything, "platform").Return(&domain.Team{Name: "platform", ParentName: "engineering"}, nil)
+	suite.mockTeamRepo.On("SetRole", mock.Anything, "backend", "u2", domain.RoleLead).Return(nil)
+	suite.mockTeamRepo.On("GetByID", mock.Anything, "T1").Return(team, nil)
+
+	// the lead two levels up may appoint a lead
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{
+		UserID: "u9", Teams: []string{"engineering"}, Roles: map[string]domain.TeamRole{"engineering": domain.RoleLead},
+	})
+	_, err := suite.teamService.SetMemberRole(ctx, domain.TeamRef{Name: "backend"}, "u2", domain.RoleLead, service.SetMemberRoleOptions{})
+
+	require.NoError(t, err)
+	suite.mockTeamRepo.AssertExpectations(t)
+}
+
+func syncFixture(suite *TeamServiceTestSuite) []*domain.Team {
+	suite.mockTeamRepo.On("List", mock.Anything).Return([]*domain.Team{
+		{Name: "backend", Members: []domain.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleMember},
+			{UserID: "u2", Username: "Bob", IsActive: true, Role: domain.RoleMember},
+		}},
+		{Name: "legacy", Members: []domain.TeamMember{
+			{UserID: "u3", Username: "Carol", IsActive: true, Role: domain.RoleMember},
+		}},
+	}, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u4").Return(nil, domain.ErrUserNotFound)
+
+	return []*domain.Team{
+		{Name: "backend", Members: []domain.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleLead},
+			{UserID: "u4", Username: "Dan", IsActive: true, Role: domain.RoleMember},
+		}},
+		{Name: "frontend", ParentName: "backend", Members: []domain.TeamMember{
+			{UserID: "u2", Username: "Bob", IsActive: true, Role: domain.RoleMember},
+		}},
+	}
+}
+
+func TestTeamService_SyncRoster_AppliesPlan(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	roster := syncFixture(suite)
+
+	suite.mockPRRepo.On("ListOpenByTeam", mock.Anything, "legacy").Return(nil, nil)
+	suite.mockTeamRepo.On("Delete", mock.Anything, "legacy").Return(nil)
+	suite.mockTeamRepo.On("Create", mock.Anything, &domain.Team{Name: "frontend", ParentName: "backend"}).Return(nil)
+	suite.mockUserRepo.On("Create", mock.Anything, &domain.User{ID: "u3", Username: "Carol"}).Return(nil)
+	suite.mockUserRepo.On("Update", mock.Anything, &domain.User{ID: "u3", Username: "Carol"}).Return(nil)
+	suite.mockUserRepo.On("Create", mock.Anything, &domain.User{ID: "u4", Username: "Dan", IsActive: true}).Return(nil)
+	suite.mockTeamRepo.On("AddMembers", mock.Anything, "backend", []string{"u4"}).Return(nil)
+	suite.mockTeamRepo.On("SetRole", mock.Anything, "backend", "u1", domain.RoleLead).Return(nil)
+	suite.mockTeamRepo.On("AddMembers", mock.Anything, "frontend", []string{"u2"}).Return(nil)
+	suite.mockTeamRepo.On("RemoveMember", mock.Anything, "backend", "u2").Return(nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u2").Return(nil, nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u3").Return([]*domain.PullRequest{{
+		ID: "pr-1", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen, AssignedReviewers: []string{"u3"},
+	}}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{{ID: "u1", IsActive: true}}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	plan, err := suite.teamService.SyncRoster(context.Background(), roster, service.SyncOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SyncChange{
+		{Action: domain.SyncDeleteTeam, Team: "legacy"},
+		{Action: domain.SyncCreateTeam, Team: "frontend", Parent: "backend"},
+		{Action: domain.SyncDeactivateUser, UserID: "u3", Username: "Carol"},
+		{Action: domain.SyncCreateUser, UserID: "u4", Username: "Dan", IsActive: true},
+		{Action: domain.SyncAddMember, Team: "backend", UserID: "u4", Role: domain.RoleMember},
+		{Action: domain.SyncSetRole, Team: "backend", UserID: "u1", Role: domain.RoleLead},
+		{Action: domain.SyncTransferMember, Team: "frontend", FromTeam: "backend", UserID: "u2", Role: domain.RoleMember},
+	}, plan.Changes)
+	assert.Equal(t, []domain.ReviewerChange{{PRID: "pr-1", OldUserID: "u3"}}, plan.Reviews,
+		"the deactivated user's review is released even though they left no team")
+	suite.mockTeamRepo.AssertExpectations(t)
+	suite.mockUserRepo.AssertExpectations(t)
+	suite.mockTeamRepo.AssertNotCalled(t, "RemoveMember", mock.Anything, "legacy", mock.Anything)
+}
+
+func TestTeamService_SyncRoster_DryRunWritesNothing(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	roster := syncFixture(suite)
+	suite.mockPRRepo.On("ListOpenByTeam", mock.Anything, "legacy").Return(nil, nil)
+
+	plan, err := suite.teamService.SyncRoster(context.Background(), roster, service.SyncOptions{DryRun: true})
+
+	require.NoError(t, err)
+	assert.Len(t, plan.Changes, 7)
+	suite.mockTeamRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	suite.mockTeamRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	suite.mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTeamService_SyncRoster_RejectsDroppingTeamWithOpenPullRequests(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	roster := syncFixture(suite)
+	suite.mockPRRepo.On("ListOpenByTeam", mock.Anything, "legacy").Return([]*domain.PullRequest{{ID: "pr-1"}}, nil)
+
+	_, err := suite.teamService.SyncRoster(context.Background(), roster, service.SyncOptions{DryRun: true})
+
+	assert.Equal(t, domain.ErrTeamHasOpenPRs, err)
+}
+
+func TestTeamService_SyncRoster_InvalidRoster(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	roster := []*domain.Team{
+		{Name: "a", ParentName: "b"},
+		{Name: "b", ParentName: "a", Members: []domain.TeamMember{{UserID: "u1", Username: "Alice", IsActive: true}}},
+		{Name: "c", ParentName: "missing", Members: []domain.TeamMember{{UserID: "u1", Username: "Alice"}}},
+		{Name: "c"},
+	}
+
+	_, err := suite.teamService.SyncRoster(context.Background(), roster, service.SyncOptions{})
+
+	var invalid *domain.RosterError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []string{
+		`team "c" is listed twice`,
+		`team "c": parent_team "missing" is not in the roster`,
+		`user "u1" has different username or is_active in different teams`,
+		`team "a" is nested under itself`,
+		`team "b" is nested under itself`,
+	}, invalid.Problems)
+	suite.mockTeamRepo.AssertNotCalled(t, "List", mock.Anything)
+}
+
+func TestTeamService_SetMembers_AddsAndRemoves(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+	team.ID = "T1"
+	updated := &domain.Team{ID: "T1", Name: "backend", Members: []domain.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+		{UserID: "u3", Username: "Carol", IsActive: true},
+	}}
+
+	suite.mockTeamRepo.On("GetByID", mock.Anything, "T1").Return(team, nil).Once()
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u3").Return(&domain.User{ID: "u3"}, nil)
+	suite.mockTeamRepo.On("AddMembers", mock.Anything, "backend", []string{"u3"}).Return(nil)
+	suite.mockTeamRepo.On("RemoveMember", mock.Anything, "backend", "u2").Return(nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u2").Return(nil, nil)
+	suite.mockTeamRepo.On("GetByID", mock.Anything, "T1").Return(updated, nil).Once()
+
+	result, changes, err := suite.teamService.SetMembers(context.Background(), domain.TeamRef{ID: "T1"}, []string{"u1", "u3"})
+
+	require.NoError(t, err)
+	assert.Equal(t, updated, result)
+	assert.Empty(t, changes)
+	suite.mockTeamRepo.AssertExpectations(t)
+}
+
+func TestTeamService_SetMembers_UnknownUser(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	team := CreateTestTeam()
+	team.ID = "T1"
+
+	suite.mockTeamRepo.On("GetByID", mock.Anything, "T1").Return(team, nil)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u9").Return(nil, domain.ErrUserNotFound)
+
+	_, _, err := suite.teamService.SetMembers(context.Background(), domain.TeamRef{ID: "T1"}, []string{"u1", "u2", "u9"})
+
+	assert.Equal(t, domain.ErrUserNotFound, err)
+	suite.mockTeamRepo.AssertNotCalled(t, "AddMembers", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamService_EraseUser_ReleasesReviewsBeforeErasing(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	user := &domain.User{ID: "u2", Username: "Bob", IsActive: true, Teams: []string{"backend"}}
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "frontend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").Return(user, nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u2").Return([]*domain.PullRequest{pr}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "frontend").Return([]*domain.User{{ID: "u1", IsActive: true}}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+	suite.mockUserRepo.On("Erase", mock.Anything, "u2", mock.MatchedBy(func(pseudonym string) bool {
+		return strings.HasPrefix(pseudonym, domain.PseudonymPrefix)
+	})).Return(domain.ErasedRecords{PullRequests: 2, Events: 3}, nil)
+
+	receipt, err := suite.teamService.EraseUser(context.Background(), "u2")
+
+	require.NoError(t, err)
+	assert.Equal(t, "u2", receipt.UserID)
+	assert.Equal(t, []string{"backend"}, receipt.Teams)
+	assert.Equal(t, []domain.ReviewerChange{{PRID: "pr-1", OldUserID: "u2"}}, receipt.Reviews, "reviews outside the user's teams are released too")
+	assert.Equal(t, domain.ErasedRecords{PullRequests: 2, Events: 3}, receipt.ErasedRecords)
+	suite.mockUserRepo.AssertExpectations(t)
+}
+
+func TestTeamService_EraseUser_UserNotFound(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u9").Return(nil, domain.ErrUserNotFound)
+
+	receipt, err := suite.teamService.EraseUser(context.Background(), "u9")
+
+	assert.Equal(t, domain.ErrUserNotFound, err)
+	assert.Nil(t, receipt)
+	suite.mockUserRepo.AssertNotCalled(t, "Erase", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTeamService_DeleteUser_ReleasesReviewsWithoutErasing(t *testing.T) {
+	suite := NewTeamServiceTestSuite()
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u1", TeamName: "frontend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u2").Return(&domain.User{ID: "u2", IsActive: true}, nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u2").Return([]*domain.PullRequest{pr}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "frontend").Return([]*domain.User{{ID: "u1", IsActive: true}}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEventsRepo.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+	suite.mockUserRepo.On("Delete", mock.Anything, "u2").Return(nil)
+
+	released, err := suite.teamService.DeleteUser(context.Background(), "u2")
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ReviewerChange{{PRID: "pr-1", OldUserID: "u2"}}, released)
+	suite.mockUserRepo.AssertExpectations(t)
+	suite.mockUserRepo.AssertNotCalled(t, "Erase", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/111zxc/pr-review-service/internal/domain"
+	"github.com/111zxc/pr-review-service/internal/handler"
+	"github.com/111zxc/pr-review-service/internal/repository/mocks"
+	"github.com/111zxc/pr-review-service/internal/service"
+)
+
+func TestTracing_PropagatesTraceContextIntoServiceSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	teamRepo := new(mocks.TeamRepository)
+	teamRepo.On("GetByName", mock.Anything, "backend").Return(&domain.Team{Name: "backend"}, nil)
+	teamRepo.On("ListSubTeams", mock.Anything, "backend").Return(nil, nil)
+	h := handler.NewTeamHandler(service.NewTeamService(teamRepo, new(mocks.UserRepository), nil, nil))
+
+	routeOf := func(*http.Request) string { return "/team/get" }
+	traced := handler.NewTracingMiddleware(routeOf)(http.HandlerFunc(h.GetTeam))
+
+	req := httptest.NewRequest(http.MethodGet, "/team/get?team_name=backend", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	traced.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	byName := make(map[string]tracetest.SpanStub)
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+
+	server, ok := byName["GET /team/get"]
+	require.True(t, ok)
+	svc, ok := byName["TeamService.GetTeam"]
+	require.True(t, ok)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, server.SpanContext.SpanID(), svc.Parent.SpanID())
+	assert.Equal(t, server.SpanContext.TraceID(), svc.SpanContext.TraceID())
+}
@@ -1,6 +1,7 @@
 package unit
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,15 +14,24 @@ import (
 
 type UserServiceTestSuite struct {
 	mockUserRepo *mocks.UserRepository
+	mockPRRepo   *mocks.PullRequestRepository
+	mockEvents   *mocks.EventsRepository
 	userService  *service.UserService
 }
 
 func NewUserServiceTestSuite() *UserServiceTestSuite {
 	mockUserRepo := new(mocks.UserRepository)
-	userService := service.NewUserService(mockUserRepo)
+	mockPRRepo := new(mocks.PullRequestRepository)
+	mockEvents := new(mocks.EventsRepository)
+	prService := service.NewPullRequestService(
+		mockPRRepo, mockUserRepo, new(mocks.TeamRepository), new(mocks.PRStatusRepository), mockEvents,
+	)
+	userService := service.NewUserService(mockUserRepo, directTx{}, prService)
 
 	return &UserServiceTestSuite{
 		mockUserRepo: mockUserRepo,
+		mockPRRepo:   mockPRRepo,
+		mockEvents:   mockEvents,
 		userService:  userService,
 	}
 }
@@ -31,7 +41,7 @@ func CreateTestUser() *domain.User {
 		ID:       "u1",
 		Username: "Alice",
 		IsActive: true,
-		TeamName: "backend",
+		Teams:    []string{"backend"},
 	}
 }
 
@@ -39,27 +49,129 @@ func TestUserService_SetUserActive_Success(t *testing.T) {
 	suite := NewUserServiceTestSuite()
 	user := CreateTestUser()
 
-	suite.mockUserRepo.On("GetByID", "u1").Return(user, nil)
-	suite.mockUserRepo.On("Update", mock.MatchedBy(func(u *domain.User) bool {
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(user, nil)
+	suite.mockUserRepo.On("Update", mock.Anything, mock.MatchedBy(func(u *domain.User) bool {
 		return u.ID == "u1" && u.IsActive == false
 	})).Return(nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u1").Return(nil, nil)
 
-	result, err := suite.userService.SetUserActive("u1", false)
+	result, err := suite.userService.SetUserActive(context.Background(), "u1", false)
 
 	assert.NoError(t, err)
 	assert.False(t, result.IsActive)
 	suite.mockUserRepo.AssertExpectations(t)
 }
 
+func TestUserService_SetUserActive_DeactivationReleasesReviews(t *testing.T) {
+	suite := NewUserServiceTestSuite()
+	pr := &domain.PullRequest{
+		ID: "pr-1", AuthorID: "u2", TeamName: "backend", Status: domain.PRStatusOpen,
+		AssignedReviewers: []string{"u1"},
+	}
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(CreateTestUser(), nil)
+	suite.mockUserRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockPRRepo.On("ListByReviewer", mock.Anything, "u1").Return([]*domain.PullRequest{pr}, nil)
+	suite.mockUserRepo.On("GetByTeam", mock.Anything, "backend").Return([]*domain.User{
+		{ID: "u2", IsActive: true}, {ID: "u3", IsActive: true},
+	}, nil)
+	suite.mockPRRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	suite.mockEvents.On("CreateEvent", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := suite.userService.SetUserActive(context.Background(), "u1", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"u3"}, pr.AssignedReviewers)
+	suite.mockPRRepo.AssertExpectations(t)
+}
+
+func TestUserService_SetUserActive_ActivationKeepsReviews(t *testing.T) {
+	suite := NewUserServiceTestSuite()
+	user := CreateTestUser()
+	user.IsActive = false
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(user, nil)
+	suite.mockUserRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := suite.userService.SetUserActive(context.Background(), "u1", true)
+
+	assert.NoError(t, err)
+	assert.True(t, result.IsActive)
+	suite.mockPRRepo.AssertNotCalled(t, "ListByReviewer", mock.Anything, mock.Anything)
+}
+
 func TestUserService_SetUserActive_UserNotFound(t *testing.T) {
 	suite := NewUserServiceTestSuite()
 
-	suite.mockUserRepo.On("GetByID", "u1").Return(nil, domain.ErrUserNotFound)
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(nil, domain.ErrUserNotFound)
 
-	result, err := suite.userService.SetUserActive("u1", false)
+	result, err := suite.userService.SetUserActive(context.Background(), "u1", false)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, domain.ErrUserNotFound, err)
 	suite.mockUserRepo.AssertExpectations(t)
 }
+
+func TestUserService_CreateUser_Success(t *testing.T) {
+	suite := NewUserServiceTestSuite()
+	user := &domain.User{ID: "u1", Username: "Alice", IsActive: true}
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(nil, domain.ErrUserNotFound)
+	suite.mockUserRepo.On("Create", mock.Anything, user).Return(nil)
+
+	err := suite.userService.CreateUser(context.Background(), user)
+
+	assert.NoError(t, err)
+	suite.mockUserRepo.AssertExpectations(t)
+}
+
+func TestUserService_CreateUser_Exists(t *testing.T) {
+	suite := NewUserServiceTestSuite()
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(CreateTestUser(), nil)
+
+	err := suite.userService.CreateUser(context.Background(), &domain.User{ID: "u1", Username: "Alice"})
+
+	assert.Equal(t, domain.ErrUserExists, err)
+	suite.mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUserService_RenameUser_EmptyName(t *testing.T) {
+	suite := NewUserServiceTestSuite()
+
+	result, err := suite.userService.RenameUser(context.Background(), "u1", "")
+
+	assert.Equal(t, domain.ErrInvalidInput, err)
+	assert.Nil(t, result)
+	suite.mockUserRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestUserService_ListUserDetails_CountsOpenReviews(t *testing.T) {
+	suite := NewUserServiceTestSuite()
+	filter := domain.UserFilter{Team: "backend", Limit: 2}
+	alice := CreateTestUser()
+	bob := &domain.User{ID: "u2", Username: "Bob", IsActive: true, Teams: []string{"backend"}}
+
+	suite.mockUserRepo.On("List", mock.Anything, filter).Return([]*domain.User{alice, bob}, 5, nil)
+	suite.mockUserRepo.On("CountOpenReviews", mock.Anything, []string{"u1", "u2"}).Return(map[string]int{"u2": 3}, nil)
+
+	users, total, err := suite.userService.ListUserDetails(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Equal(t, []domain.UserDetails{{User: alice}, {User: bob, OpenReviews: 3}}, users)
+	suite.mockUserRepo.AssertExpectations(t)
+}
+
+func TestUserService_GetUserDetails_UserNotFound(t *testing.T) {
+	suite := NewUserServiceTestSuite()
+
+	suite.mockUserRepo.On("GetByID", mock.Anything, "u1").Return(nil, domain.ErrUserNotFound)
+
+	user, err := suite.userService.GetUserDetails(context.Background(), "u1")
+
+	assert.Equal(t, domain.ErrUserNotFound, err)
+	assert.Nil(t, user)
+	suite.mockUserRepo.AssertNotCalled(t, "CountOpenReviews", mock.Anything, mock.Anything)
+}